@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// EarlyAccess is the policy for configs read with Get before the ConfigSet
+// has loaded anything, which usually means a component read its settings
+// before Parse was called and is using the defaults by mistake.
+type EarlyAccess int
+
+const (
+	// EarlyAccessAllowed ignores early reads. It's the default.
+	EarlyAccessAllowed EarlyAccess = iota
+	// EarlyAccessLog reports early reads with the ConfigSet's logger.
+	EarlyAccessLog
+	// EarlyAccessPanic panics on early reads.
+	EarlyAccessPanic
+)
+
+// SetEarlyAccess sets the policy for configs read with Get before anything
+// has been loaded. It's meant for debugging and tests.
+func (c *ConfigSet) SetEarlyAccess(policy EarlyAccess) {
+	c.earlyAccess = policy
+}
+
+// Get returns the current value of the named config variable, or nil if it
+// hasn't been defined. Values of the types defined by this package are
+// returned as their Go types, such as int for configs defined by Int.
+func (c *ConfigSet) Get(name string) interface{} {
+	name = c.prefix + name
+	if c.source == nil && c.earlyAccess != EarlyAccessAllowed {
+		msg := fmt.Sprintf("%s read before the config was loaded, at %s", name, caller())
+		if c.earlyAccess == EarlyAccessPanic {
+			panic("config: " + msg)
+		}
+		c.warnf("%s", msg)
+	}
+
+	f := c.Lookup(name)
+	if f == nil {
+		return nil
+	}
+	if getter, ok := f.Value.(interface{ Get() interface{} }); ok {
+		return getter.Get()
+	}
+	return f.Value.String()
+}
+
+// packageDir is the directory holding this package's source files.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// caller describes the nearest call site on the stack outside this package,
+// such as "server.go:42 (main.startServer)", so that errors and warnings
+// point at the code responsible rather than at this package.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d (%s)", filepath.Base(frame.File), frame.Line, frame.Function)
+		}
+		if !more {
+			return "unknown caller"
+		}
+	}
+}
+
+// SetEarlyAccess sets the policy for configs read with Get before anything
+// has been loaded.
+func SetEarlyAccess(policy EarlyAccess) {
+	global().SetEarlyAccess(policy)
+}
+
+// Get returns the current value of the named config variable, or nil if it
+// hasn't been defined.
+func Get(name string) interface{} {
+	return global().Get(name)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	c := NewConfigSet("access", ContinueOnError)
+	c.Int("server.port", 8080)
+	c.Duration("server.timeout", time.Second)
+
+	if err := c.ParseLayers(Layers{Baseline: []byte("[server]\nport = 9000\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("server.port"); port != 9000 {
+		t.Error("server.port should be 9000, is", port)
+	}
+	if timeout := c.WithPrefix("server.").Get("timeout"); timeout != time.Second {
+		t.Error("server.timeout should be 1s, is", timeout)
+	}
+	if missing := c.Get("server.missing"); missing != nil {
+		t.Error("Undefined configs should be nil, are", missing)
+	}
+}
+
+func TestEarlyAccess(t *testing.T) {
+	c := NewConfigSet("access", ContinueOnError)
+	c.Int("server.port", 8080)
+
+	var out bytes.Buffer
+	c.SetLogger(log.New(&out, "", 0))
+	c.SetEarlyAccess(EarlyAccessLog)
+	if port := c.Get("server.port"); port != 8080 {
+		t.Error("server.port should still be readable, is", port)
+	}
+	if msg := out.String(); !strings.HasPrefix(msg, "config: server.port read before the config was loaded, at access_test.go:") {
+		t.Error("Unexpected warning:", msg)
+	}
+
+	c.SetEarlyAccess(EarlyAccessPanic)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected an early read to panic")
+			}
+		}()
+		c.Get("server.port")
+	}()
+
+	if err := c.ParseLayers(Layers{}); err != nil {
+		t.Fatal(err)
+	}
+	c.Get("server.port")
+}
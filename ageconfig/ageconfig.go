@@ -0,0 +1,89 @@
+// Package ageconfig decrypts config values encrypted with age
+// (https://age-encryption.org), so that individual secrets can be committed
+// to version control while the rest of a config file stays plain:
+//
+//	d, err := ageconfig.NewDecrypter("/etc/myapp/age.key")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	config.SetDecrypter(d)
+//
+// Values are encrypted with, for example:
+//
+//	echo -n hunter2 | age -r age1... --armor
+package ageconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stvp/go-toml-config"
+)
+
+// Prefix marks a value as an age ciphertext, either armored or base64
+// encoded, such as "!age:YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+...".
+const Prefix = "!age:"
+
+// Decrypter decrypts age ciphertexts with a set of identities. It implements
+// config.Decrypter.
+type Decrypter struct {
+	identities []age.Identity
+}
+
+var _ config.Decrypter = (*Decrypter)(nil)
+
+// NewDecrypter returns a Decrypter using the identities in the file at path,
+// in the format written by age-keygen.
+func NewDecrypter(path string) (*Decrypter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return &Decrypter{identities}, nil
+}
+
+// Decrypt decrypts value if it's an armored age ciphertext, or if it starts
+// with Prefix.
+func (d *Decrypter) Decrypt(value string) (string, bool, error) {
+	var r io.Reader
+	switch {
+	case strings.HasPrefix(value, Prefix):
+		ciphertext := strings.TrimSpace(strings.TrimPrefix(value, Prefix))
+		if strings.HasPrefix(ciphertext, armor.Header) {
+			r = armor.NewReader(strings.NewReader(ciphertext))
+		} else {
+			raw, err := base64.StdEncoding.DecodeString(ciphertext)
+			if err != nil {
+				return "", true, err
+			}
+			r = bytes.NewReader(raw)
+		}
+	case strings.HasPrefix(strings.TrimSpace(value), armor.Header):
+		r = armor.NewReader(strings.NewReader(strings.TrimSpace(value)))
+	default:
+		return "", false, nil
+	}
+
+	plaintext, err := age.Decrypt(r, d.identities...)
+	if err != nil {
+		return "", true, err
+	}
+	b, err := ioutil.ReadAll(plaintext)
+	if err != nil {
+		return "", true, err
+	}
+	return string(b), true, nil
+}
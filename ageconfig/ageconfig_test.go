@@ -0,0 +1,83 @@
+package ageconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stvp/go-toml-config"
+)
+
+func encrypt(t *testing.T, recipient age.Recipient, plaintext string, armored bool) string {
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopCloser{&buf}
+	if armored {
+		out = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(out, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, plaintext)
+	w.Close()
+	out.Close()
+	if armored {
+		return buf.String()
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestDecrypter(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "ageconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keyPath := filepath.Join(dir, "age.key")
+	ioutil.WriteFile(keyPath, []byte("# test key\n"+identity.String()+"\n"), 0600)
+
+	d, err := NewDecrypter(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "app.conf")
+	armored := encrypt(t, identity.Recipient(), "hunter2", true)
+	prefixed := encrypt(t, identity.Recipient(), "s3cret", false)
+	ioutil.WriteFile(path, []byte("password = '''\n"+armored+"'''\ntoken = \""+prefixed+"\"\nuser = \"app\"\n"), 0644)
+
+	c := config.NewConfigSet("ageconfig", config.ContinueOnError)
+	password := c.String("password", "")
+	token := c.String("token", "")
+	user := c.String("user", "")
+	c.SetDecrypter(d)
+
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "hunter2" || *token != "s3cret" || *user != "app" {
+		t.Error("Unexpected settings:", *password, *token, *user)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	ioutil.WriteFile(path, []byte("token = \""+encrypt(t, other.Recipient(), "x", false)+"\"\n"), 0644)
+	err = c.Parse(path)
+	if err == nil || !strings.HasPrefix(err.Error(), "The value for token is invalid: can't decrypt: ") {
+		t.Error("Expected a decryption error, got", err)
+	}
+}
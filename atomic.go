@@ -0,0 +1,250 @@
+package config
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Int64Val holds an int64 config value that can be read safely while the
+// ConfigSet is being reloaded. Load doesn't lock or allocate, so it's suitable
+// for settings read on every request.
+type Int64Val struct {
+	v int64
+}
+
+// Load returns the current value.
+func (i *Int64Val) Load() int64 { return atomic.LoadInt64(&i.v) }
+
+func (i *Int64Val) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return numError(err)
+	}
+	atomic.StoreInt64(&i.v, v)
+	return nil
+}
+
+func (i *Int64Val) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		atomic.StoreInt64(&i.v, v)
+	case string:
+		return i.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (i *Int64Val) Get() interface{} { return i.Load() }
+
+func (i *Int64Val) snapshot() func() error {
+	v := i.Load()
+	return func() error {
+		atomic.StoreInt64(&i.v, v)
+		return nil
+	}
+}
+
+func (i *Int64Val) String() string { return strconv.FormatInt(i.Load(), 10) }
+
+// BoolVal holds a bool config value that can be read safely while the
+// ConfigSet is being reloaded.
+type BoolVal struct {
+	v uint32
+}
+
+// Load returns the current value.
+func (b *BoolVal) Load() bool { return atomic.LoadUint32(&b.v) != 0 }
+
+func (b *BoolVal) store(v bool) {
+	var u uint32
+	if v {
+		u = 1
+	}
+	atomic.StoreUint32(&b.v, u)
+}
+
+func (b *BoolVal) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errParse
+	}
+	b.store(v)
+	return nil
+}
+
+func (b *BoolVal) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		b.store(v)
+	case string:
+		return b.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (b *BoolVal) Get() interface{} { return b.Load() }
+
+func (b *BoolVal) snapshot() func() error {
+	v := b.Load()
+	return func() error {
+		b.store(v)
+		return nil
+	}
+}
+
+func (b *BoolVal) String() string { return strconv.FormatBool(b.Load()) }
+
+// IsBoolFlag lets a command-line flag for the config be given without a
+// value, as -name.
+func (b *BoolVal) IsBoolFlag() bool { return true }
+
+// Float64Val holds a float64 config value that can be read safely while the
+// ConfigSet is being reloaded.
+type Float64Val struct {
+	bits uint64
+}
+
+// Load returns the current value.
+func (f *Float64Val) Load() float64 { return math.Float64frombits(atomic.LoadUint64(&f.bits)) }
+
+func (f *Float64Val) store(v float64) { atomic.StoreUint64(&f.bits, math.Float64bits(v)) }
+
+func (f *Float64Val) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return numError(err)
+	}
+	f.store(v)
+	return nil
+}
+
+func (f *Float64Val) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case float64:
+		f.store(v)
+	case int64:
+		f.store(float64(v))
+	case string:
+		return f.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (f *Float64Val) Get() interface{} { return f.Load() }
+
+func (f *Float64Val) snapshot() func() error {
+	v := f.Load()
+	return func() error {
+		f.store(v)
+		return nil
+	}
+}
+
+func (f *Float64Val) String() string {
+	return strconv.FormatFloat(f.Load(), 'g', -1, 64)
+}
+
+// DurationVal holds a time.Duration config value that can be read safely
+// while the ConfigSet is being reloaded.
+type DurationVal struct {
+	v int64
+}
+
+// Load returns the current value.
+func (d *DurationVal) Load() time.Duration { return time.Duration(atomic.LoadInt64(&d.v)) }
+
+func (d *DurationVal) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return errParse
+	}
+	atomic.StoreInt64(&d.v, int64(v))
+	return nil
+}
+
+func (d *DurationVal) setTOML(value interface{}) error {
+	if v, ok := value.(string); ok {
+		return d.Set(v)
+	}
+	return errParse
+}
+
+func (d *DurationVal) Get() interface{} { return d.Load() }
+
+func (d *DurationVal) snapshot() func() error {
+	v := d.Load()
+	return func() error {
+		atomic.StoreInt64(&d.v, int64(v))
+		return nil
+	}
+}
+
+func (d *DurationVal) String() string { return d.Load().String() }
+
+// AtomicInt64 defines an int64 config variable with a given name and default
+// value for a ConfigSet, returning a holder that is safe for concurrent reads.
+func (c *ConfigSet) AtomicInt64(name string, value int64) *Int64Val {
+	p := &Int64Val{v: value}
+	c.Var(p, name, "")
+	return p
+}
+
+// AtomicBool defines a bool config variable with a given name and default
+// value for a ConfigSet, returning a holder that is safe for concurrent reads.
+func (c *ConfigSet) AtomicBool(name string, value bool) *BoolVal {
+	p := &BoolVal{}
+	p.store(value)
+	c.Var(p, name, "")
+	return p
+}
+
+// AtomicFloat64 defines a float64 config variable with a given name and
+// default value for a ConfigSet, returning a holder that is safe for
+// concurrent reads.
+func (c *ConfigSet) AtomicFloat64(name string, value float64) *Float64Val {
+	p := &Float64Val{}
+	p.store(value)
+	c.Var(p, name, "")
+	return p
+}
+
+// AtomicDuration defines a time.Duration config variable with a given name
+// and default value for a ConfigSet, returning a holder that is safe for
+// concurrent reads.
+func (c *ConfigSet) AtomicDuration(name string, value time.Duration) *DurationVal {
+	p := &DurationVal{v: int64(value)}
+	c.Var(p, name, "")
+	return p
+}
+
+// AtomicInt64 defines an int64 config variable with a given name and default
+// value, returning a holder that is safe for concurrent reads.
+func AtomicInt64(name string, value int64) *Int64Val {
+	return global().AtomicInt64(name, value)
+}
+
+// AtomicBool defines a bool config variable with a given name and default
+// value, returning a holder that is safe for concurrent reads.
+func AtomicBool(name string, value bool) *BoolVal {
+	return global().AtomicBool(name, value)
+}
+
+// AtomicFloat64 defines a float64 config variable with a given name and
+// default value, returning a holder that is safe for concurrent reads.
+func AtomicFloat64(name string, value float64) *Float64Val {
+	return global().AtomicFloat64(name, value)
+}
+
+// AtomicDuration defines a time.Duration config variable with a given name
+// and default value, returning a holder that is safe for concurrent reads.
+func AtomicDuration(name string, value time.Duration) *DurationVal {
+	return global().AtomicDuration(name, value)
+}
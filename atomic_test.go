@@ -0,0 +1,42 @@
+package config
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAtomicValues(t *testing.T) {
+	c := NewConfigSet("atomic", ContinueOnError)
+	limit := c.AtomicInt64("limit", 10)
+	enabled := c.AtomicBool("enabled", false)
+	ratio := c.AtomicFloat64("ratio", 0.5)
+	timeout := c.AtomicDuration("timeout", time.Second)
+
+	if limit.Load() != 10 || enabled.Load() || ratio.Load() != 0.5 || timeout.Load() != time.Second {
+		t.Fatal("Atomic values should start at their defaults")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			limit.Load()
+			enabled.Load()
+		}
+	}()
+	err := c.parseBytes("atomic", []byte("limit = 20\nenabled = true\nratio = 1\ntimeout = \"5s\"\n"))
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if limit.Load() != 20 || !enabled.Load() || ratio.Load() != 1 || timeout.Load() != 5*time.Second {
+		t.Errorf("Unexpected values: %v %v %v %v", limit, enabled, ratio, timeout)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { limit.Load() }); allocs != 0 {
+		t.Error("Load should not allocate, got", allocs)
+	}
+}
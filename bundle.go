@@ -0,0 +1,146 @@
+package config
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// ParseBundle loads a multi-file configuration distributed as a single tar
+// archive, which may be compressed with gzip, as a ".tgz" file is. Loading
+// starts with the document named main, such as "app.toml". A document in the
+// bundle can include others with a top-level "include" array of paths
+// relative to its own directory within the archive:
+//
+//	include = ["common.toml", "conf.d/db.toml"]
+//
+// Included documents are loaded first, in order, so that the settings in the
+// including document take precedence. Errors name documents by the bundle's
+// path followed by their path within it, such as "app.tgz/conf.d/db.toml:3".
+func (c *ConfigSet) ParseBundle(bundlePath, main string) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseBundle(bundlePath, main) }
+	key := loadKey("bundle", bundlePath, main)
+	if c.unchanged(key) {
+		return nil
+	}
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	c.cache.key = key
+
+	files, err := c.readBundle(bundlePath)
+	if err != nil {
+		return err
+	}
+	c.recordFile(bundlePath)
+	return c.loadBundleDocument(bundlePath, files, path.Clean(main), nil)
+}
+
+// readBundle reads the regular files in a tar archive, keyed by their clean
+// paths.
+func (c *ConfigSet) readBundle(bundlePath string) (map[string][]byte, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, c.fileError(bundlePath, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	c.recordState(bundlePath, fi, nil)
+
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(len(gzipMagic)); string(magic) == string(gzipMagic) {
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, fmt.Errorf("%s can't be decompressed: %s", bundlePath, err)
+		}
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid tar archive: %s", bundlePath, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		var src io.Reader = tr
+		if max := c.limits.MaxFileSize; max > 0 {
+			src = io.LimitReader(tr, max+1)
+		}
+		data, err := ioutil.ReadAll(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid tar archive: %s", bundlePath, err)
+		}
+		if err := c.checkSize(bundlePath+"/"+name, len(data)); err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+}
+
+// loadBundleDocument loads the named document from a bundle after the
+// documents it includes. The stack holds the documents including it.
+func (c *ConfigSet) loadBundleDocument(bundlePath string, files map[string][]byte, name string, stack []string) error {
+	displayName := bundlePath + "/" + name
+	for _, including := range stack {
+		if including == name {
+			return fmt.Errorf("%s includes itself", displayName)
+		}
+	}
+	if max := c.limits.MaxIncludeDepth; max > 0 && len(stack) > max {
+		return fmt.Errorf("%s exceeds the include depth limit of %d", displayName, max)
+	}
+	data, ok := files[name]
+	if !ok {
+		if len(stack) == 0 {
+			return fmt.Errorf("%s doesn't contain %s", bundlePath, name)
+		}
+		return fmt.Errorf("%s/%s includes %s, which the bundle doesn't contain", bundlePath, stack[len(stack)-1], name)
+	}
+
+	data = c.normalizeText(displayName, data)
+	tree, err := c.decode(displayName, data)
+	if err != nil {
+		c.recordDocument(data)
+		return err
+	}
+	var includes []string
+	if value := tree.Get("include"); value != nil {
+		if includes, err = tomlStrings(value); err != nil {
+			return fmt.Errorf("include in %s must be an array of paths", displayName)
+		}
+		tree.Delete("include")
+	}
+	for _, include := range includes {
+		includePath := path.Join(path.Dir(name), include)
+		if err := c.loadBundleDocument(bundlePath, files, includePath, append(stack, name)); err != nil {
+			return err
+		}
+	}
+	return c.loadDocument(displayName, data, tree)
+}
+
+// ParseBundle loads a multi-file configuration from a tar archive into the
+// global config, starting with the document named main.
+func ParseBundle(bundlePath, main string) error {
+	return handleGlobalError(global().ParseBundle(bundlePath, main))
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBundle writes a gzipped tar archive holding files to path.
+func writeBundle(t *testing.T, path string, files map[string]string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, contents := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(contents))
+	}
+	tw.Close()
+	gz.Close()
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "app.tgz")
+	writeBundle(t, bundle, map[string]string{
+		"app.toml":       "include = [\"conf.d/db.toml\"]\nname = \"app\"\n[db]\nhost = \"db1\"\n",
+		"conf.d/db.toml": "include = [\"../common.toml\"]\n[db]\nhost = \"db0\"\nport = 5433\n",
+		"common.toml":    "name = \"common\"\nworkers = 4\n",
+		"loop/a.toml":    "include = [\"b.toml\"]\n",
+		"loop/b.toml":    "include = [\"a.toml\"]\n",
+		"missing.toml":   "include = [\"nope.toml\"]\n",
+	})
+
+	c := NewConfigSet("bundle", ContinueOnError)
+	name := c.String("name", "")
+	workers := c.Int("workers", 1)
+	host := c.String("db.host", "")
+	port := c.Int("db.port", 5432)
+
+	if err := c.ParseBundle(bundle, "app.toml"); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "app" || *workers != 4 || *host != "db1" || *port != 5433 {
+		t.Error("Unexpected settings:", *name, *workers, *host, *port)
+	}
+	if origin := c.Origin("db.port"); origin != bundle+"/conf.d/db.toml:4" {
+		t.Error("Unexpected origin:", origin)
+	}
+
+	for main, msg := range map[string]string{
+		"loop/a.toml":  bundle + "/loop/a.toml includes itself",
+		"missing.toml": bundle + "/missing.toml includes nope.toml, which the bundle doesn't contain",
+		"nope.toml":    bundle + " doesn't contain nope.toml",
+	} {
+		if err := c.ParseBundle(bundle, main); err == nil || err.Error() != msg {
+			t.Errorf("Expected %q, got %v", msg, err)
+		}
+	}
+
+	c.SetLimits(Limits{MaxIncludeDepth: 1})
+	err = c.ParseBundle(bundle, "app.toml")
+	if err == nil || err.Error() != bundle+"/common.toml exceeds the include depth limit of 1" {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loadCache remembers the files read by the last successful load, so that
+// loading the same files again can be skipped if none of them has changed.
+type loadCache struct {
+	enabled bool
+
+	// key identifies the last successful load, such as the path given to
+	// Parse. It's empty if the load can't be skipped.
+	key string
+	// flags is the number of configs defined at the end of the load, since
+	// a config defined afterwards needs a new load to be set.
+	flags int
+
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+// fileState is the state of a file when it was read.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	// hash is the hex-encoded SHA-256 of the file's contents, or empty if
+	// they weren't read in full.
+	hash string
+	// racy is set if the file was modified so recently that another change
+	// might not alter its modification time, which some file systems only
+	// record to the second. Its contents must be compared instead.
+	racy bool
+}
+
+// racyWindow is how recently a file must have been modified for its
+// modification time to be unreliable.
+const racyWindow = 2 * time.Second
+
+// SetLoadCache sets whether Parse, ParseFiles, ParseDir, ParseIncremental,
+// ParseBundle, and Reload skip loading files again when none of them has
+// changed since the last successful load, which keeps frequent refreshes
+// cheap. A file whose modification time has changed is read and compared with
+// the contents last loaded. Loads aren't skipped unless the cache is enabled,
+// so by default loading a file again re-applies its values even if it hasn't
+// changed. A skipped load leaves the settings, LoadInfo, and Stats as they
+// were, and doesn't call OnRotate callbacks. Loads with ParseOptions, and
+// loads after a config has been changed by Set, a transaction, or Rollback,
+// are never skipped.
+func (c *ConfigSet) SetLoadCache(enabled bool) {
+	c.cache.enabled = enabled
+}
+
+// loadKey identifies a load of the given kind from the given files.
+func loadKey(kind string, paths ...string) string {
+	return kind + "\x00" + strings.Join(paths, "\x00")
+}
+
+// unchanged reports whether a load identified by key can be skipped, because
+// the last load was the same, succeeded, and none of its files has changed
+// since. It's never true for an empty key or during a Reload, which checks
+// for itself.
+func (c *ConfigSet) unchanged(key string) bool {
+	cache := c.cache
+	if !cache.enabled || key == "" || key != cache.key || c.reloading || cache.flags != c.numDefined() {
+		return false
+	}
+	for path, state := range cache.files {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		if fi.ModTime().Equal(state.modTime) && fi.Size() == state.size && !state.racy {
+			continue
+		}
+		if state.hash == "" || fi.IsDir() {
+			return false
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil || hashBytes(data) != state.hash {
+			return false
+		}
+		// The file was only touched, so the next check can use the new
+		// modification time.
+		state.modTime, state.size = fi.ModTime(), fi.Size()
+		state.racy = isRacy(fi.ModTime())
+		cache.files[path] = state
+	}
+	return true
+}
+
+// recordState records the state of a file read by a load. The data are the
+// file's raw contents, or nil if they weren't read in full. The FileInfo must
+// come from before the file was read, so that a change made while it was
+// being read isn't mistaken for the contents loaded.
+func (c *ConfigSet) recordState(path string, fi os.FileInfo, data []byte) {
+	state := fileState{modTime: fi.ModTime(), size: fi.Size(), racy: isRacy(fi.ModTime())}
+	if data != nil {
+		state.hash = hashBytes(data)
+	}
+	c.cache.mu.Lock()
+	c.cache.files[path] = state
+	c.cache.mu.Unlock()
+}
+
+// invalidateCache makes the next load run even if no file has changed,
+// because the settings no longer match the files.
+func (c *ConfigSet) invalidateCache() {
+	c.cache.key = ""
+}
+
+// numDefined returns the number of configs defined in the ConfigSet.
+func (c *ConfigSet) numDefined() int {
+	n := 0
+	c.VisitAll(func(*flag.Flag) { n++ })
+	return n
+}
+
+// isRacy reports whether a modification time is too recent to be relied on.
+func isRacy(modTime time.Time) bool {
+	return time.Since(modTime) < racyWindow
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetLoadCache sets whether loads of the global config are skipped when none
+// of the files has changed.
+func SetLoadCache(enabled bool) {
+	global().SetLoadCache(enabled)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+	write := func(contents string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+	start := time.Now().Add(-time.Hour)
+	write("name = \"one\"\n", start)
+
+	c := NewConfigSet("cache", ContinueOnError)
+	name := c.String("name", "")
+	var rotations []string
+	c.OnRotate("name", func(value string) { rotations = append(rotations, value) })
+	load := func() uint64 {
+		if err := c.Parse(path); err != nil {
+			t.Fatal(err)
+		}
+		return c.LoadInfo().Generation
+	}
+
+	// Without the cache, loading the file again re-applies its values.
+	load()
+	*name = "changed"
+	if load() != 2 || *name != "one" {
+		t.Error("Loads shouldn't be skipped by default:", *name)
+	}
+
+	c.SetLoadCache(true)
+	if load() != 2 || load() != 2 {
+		t.Error("An unchanged file shouldn't be loaded again")
+	}
+	write("name = \"one\"\n", start.Add(time.Minute))
+	if load() != 2 || c.Reload() != nil || c.LoadInfo().Generation != 2 {
+		t.Error("A touched file shouldn't be loaded again")
+	}
+	write("name = \"two\"\n", start.Add(2*time.Minute))
+	if err := c.Reload(); err != nil || *name != "two" || c.LoadInfo().Generation != 3 {
+		t.Error("A changed file should be loaded again:", err, *name)
+	}
+	if len(rotations) != 1 || c.Reload() != nil || len(rotations) != 1 {
+		t.Errorf("Unexpected rotations: %v", rotations)
+	}
+
+	// A change that keeps the size and modification time of a file modified
+	// moments ago is still noticed.
+	write("name = \"two\"\n", time.Now())
+	load()
+	now := c.cache.files[path].modTime
+	write("name = \"2wo\"\n", now)
+	if err := c.Reload(); err != nil || *name != "2wo" {
+		t.Error("A change to a recently modified file should be loaded:", err, *name)
+	}
+	write("name = \"two\"\n", start.Add(3*time.Minute))
+	load()
+
+	loads := func() bool {
+		before := c.LoadInfo().Generation
+		return load() != before
+	}
+	c.Set("name", "set")
+	if !loads() || *name != "two" {
+		t.Error("A load after Set shouldn't be skipped")
+	}
+	c.String("late", "")
+	if !loads() {
+		t.Error("A load after a config is defined shouldn't be skipped")
+	}
+	load()
+	before := c.LoadInfo().Generation
+	if err := c.Parse(path, WithProfile("")); err != nil || c.LoadInfo().Generation == before {
+		t.Error("A load with options shouldn't be skipped:", err)
+	}
+
+	c.SetLoadCache(false)
+	if !loads() {
+		t.Error("Loads shouldn't be skipped when the cache is disabled")
+	}
+}
+
+func TestLoadCacheDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "a.conf"), []byte("a = 1\n"), 0600)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(dir, past, past)
+
+	c := NewConfigSet("cache", ContinueOnError)
+	c.SetLoadCache(true)
+	b := c.Int("b", 0)
+	c.Int("a", 0)
+	if err := c.ParseDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ParseDir(dir); err != nil || c.LoadInfo().Generation != 1 {
+		t.Error("An unchanged directory shouldn't be loaded again:", err)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "b.conf"), []byte("b = 2\n"), 0600)
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(dir, future, future)
+	if err := c.ParseDir(dir); err != nil || *b != 2 {
+		t.Error("A new file should be loaded:", err, *b)
+	}
+}
@@ -0,0 +1,53 @@
+// Package cobraconfig binds the settings in a config.ConfigSet to the flags
+// of a cobra command, so that a command line application can take each
+// setting from a config file, an environment variable, or a flag.
+package cobraconfig
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stvp/go-toml-config"
+)
+
+// BindCobra defines a flag on cmd for every config variable defined in cs so
+// far, named by config.FlagName, along with a --config flag giving the path to
+// the config file. It sets cmd's PreRunE to load the settings before the
+// command runs, with flags overriding environment variables and environment
+// variables overriding the config file. Environment variables are named after
+// the root command, so "server.port" is overridden by MYAPP_SERVER_PORT for a
+// root command named "myapp". Any PreRunE already set on cmd is run after the
+// settings are loaded.
+func BindCobra(cmd *cobra.Command, cs *config.ConfigSet) {
+	goflags := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	cs.RegisterFlags(goflags)
+	goflags.VisitAll(cmd.Flags().AddGoFlag)
+
+	path := cmd.Flags().String("config", "", "path to the config `file`")
+	prefix := EnvPrefix(cmd)
+
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if *path != "" {
+			if _, err := os.Stat(*path); err != nil {
+				return err
+			}
+		}
+		if err := cs.ParseLayers(config.Layers{User: *path, EnvPrefix: prefix}); err != nil {
+			return err
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		return nil
+	}
+}
+
+// EnvPrefix returns the prefix of the environment variables that override
+// settings bound to cmd: the name of its root command in upper case, with
+// dashes replaced by underscores.
+func EnvPrefix(cmd *cobra.Command) string {
+	return strings.ToUpper(strings.Replace(cmd.Root().Name(), "-", "_", -1))
+}
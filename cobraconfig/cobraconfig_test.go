@@ -0,0 +1,54 @@
+package cobraconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stvp/go-toml-config"
+)
+
+func TestBindCobra(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	err := os.WriteFile(path, []byte("[server]\nhost = \"file.example.com\"\nport = 80\nworkers = 2\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := config.NewConfigSet("cobra-app", config.ContinueOnError)
+	host := cs.String("server.host", "localhost")
+	port := cs.Int("server.port", 8080)
+	workers := cs.Int("server.workers", 1)
+
+	ran := false
+	cmd := &cobra.Command{
+		Use: "cobra-app",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ran = true
+			return nil
+		},
+	}
+	BindCobra(cmd, cs)
+
+	t.Setenv("COBRA_APP_SERVER_PORT", "8000")
+	t.Setenv("COBRA_APP_SERVER_WORKERS", "4")
+
+	cmd.SetArgs([]string{"--config", path, "--server-workers", "8"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ran {
+		t.Error("The command should have run")
+	}
+	if *host != "file.example.com" {
+		t.Error("server.host should come from the config file, is", *host)
+	}
+	if *port != 8000 {
+		t.Error("server.port should come from the environment, is", *port)
+	}
+	if *workers != 8 {
+		t.Error("server.workers should come from the command line, is", *workers)
+	}
+}
@@ -0,0 +1,411 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// tomlStrings converts a decoded TOML array of strings into a []string. A
+// single string is split on commas, which is also how Set handles values
+// from the environment.
+func tomlStrings(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return splitList(v), nil
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, elementError{i, "is not a string"}
+			}
+			strs[i] = s
+		}
+		return strs, nil
+	case []string:
+		return v, nil
+	}
+	return nil, errParse
+}
+
+// splitList splits a comma-separated list, trimming spaces around elements.
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+	strs := strings.Split(s, ",")
+	for i := range strs {
+		strs[i] = strings.TrimSpace(strs[i])
+	}
+	return strs
+}
+
+// -- StringSet Value
+
+type stringSetValue struct {
+	p    *[]string
+	name string
+	c    *ConfigSet
+}
+
+func (s *stringSetValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+func (s *stringSetValue) setTOML(value interface{}) error {
+	strs, err := tomlStrings(value)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(strs))
+	set := make([]string, 0, len(strs))
+	for _, str := range strs {
+		if seen[str] {
+			s.c.warnf("%s contains duplicate value %q", s.name, str)
+			continue
+		}
+		seen[str] = true
+		set = append(set, str)
+	}
+	*s.p = set
+	return nil
+}
+
+func (s *stringSetValue) Get() interface{} { return *s.p }
+
+func (s *stringSetValue) snapshot() func() error {
+	v := slices.Clone(*s.p)
+	return func() error {
+		*s.p = v
+		return nil
+	}
+}
+
+func (s *stringSetValue) String() string {
+	if s.p == nil {
+		return ""
+	}
+	return strings.Join(*s.p, ",")
+}
+
+// StringSetVar defines a string set config with a given name and default
+// value for a ConfigSet. The argument p points to a []string variable in
+// which to store the value of the config. Duplicate entries are dropped, and
+// reported through the ConfigSet's logger.
+func (c *ConfigSet) StringSetVar(p *[]string, name string, value []string) {
+	*p = value
+	c.Var(&stringSetValue{p: p, name: c.prefix + name, c: c}, name, "")
+}
+
+// StringSet defines a string set config variable with a given name and
+// default value for a ConfigSet. The TOML value must be an array of strings;
+// the set keeps the order of first appearance.
+func (c *ConfigSet) StringSet(name string, value []string) *[]string {
+	p := new([]string)
+	c.StringSetVar(p, name, value)
+	return p
+}
+
+// StringSetVar defines a string set config with a given name and default
+// value. The argument p points to a []string variable in which to store the
+// value of the config.
+func StringSetVar(p *[]string, name string, value []string) {
+	global().StringSetVar(p, name, value)
+}
+
+// StringSet defines a string set config variable with a given name and
+// default value.
+func StringSet(name string, value []string) *[]string {
+	return global().StringSet(name, value)
+}
+
+// -- KeyValueList Value
+
+// KeyValue is a single entry in a KeyValueList config.
+type KeyValue struct {
+	Key, Value string
+}
+
+func (kv KeyValue) String() string { return kv.Key + "=" + kv.Value }
+
+type keyValueListValue []KeyValue
+
+func (l *keyValueListValue) Set(s string) error {
+	return l.setTOML(s)
+}
+
+// setTOML accepts an array of "key=value" strings, which keeps its order, or
+// a table, whose entries are sorted by key.
+func (l *keyValueListValue) setTOML(value interface{}) error {
+	if tree, ok := value.(*toml.Tree); ok {
+		keys := tree.Keys()
+		sort.Strings(keys)
+		pairs := make([]KeyValue, len(keys))
+		for i, key := range keys {
+			// Keys like "a.b" are quoted, so they can't be passed to Get.
+			v := tree.GetPath([]string{key})
+			switch v.(type) {
+			case nil:
+				return fmt.Errorf("the value for %s can't be read", key)
+			case *toml.Tree:
+				return fmt.Errorf("%s is a table, not a value", key)
+			}
+			pairs[i] = KeyValue{key, fmt.Sprintf("%v", v)}
+		}
+		*l = pairs
+		return nil
+	}
+
+	strs, err := tomlStrings(value)
+	if err != nil {
+		return err
+	}
+	pairs := make([]KeyValue, len(strs))
+	for i, str := range strs {
+		eq := strings.Index(str, "=")
+		if eq < 1 {
+			return elementError{i, fmt.Sprintf("(%q) is not of the form key=value", str)}
+		}
+		pairs[i] = KeyValue{str[:eq], str[eq+1:]}
+	}
+	*l = pairs
+	return nil
+}
+
+func (l *keyValueListValue) isTable() {}
+
+func (l *keyValueListValue) Get() interface{} { return []KeyValue(*l) }
+
+func (l *keyValueListValue) snapshot() func() error {
+	v := slices.Clone(*l)
+	return func() error {
+		*l = v
+		return nil
+	}
+}
+
+func (l *keyValueListValue) String() string {
+	strs := make([]string, len(*l))
+	for i, kv := range *l {
+		strs[i] = kv.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// KeyValueListVar defines a key/value list config with a given name for a
+// ConfigSet. The argument p points to a []KeyValue variable in which to store
+// the value of the config.
+func (c *ConfigSet) KeyValueListVar(p *[]KeyValue, name string) {
+	c.Var((*keyValueListValue)(p), name, "")
+}
+
+// KeyValueList defines a key/value list config variable with a given name for
+// a ConfigSet. The TOML value may be an array of "key=value" strings, which
+// keeps its order, or a table, whose entries are sorted by key.
+func (c *ConfigSet) KeyValueList(name string) *[]KeyValue {
+	p := new([]KeyValue)
+	c.KeyValueListVar(p, name)
+	return p
+}
+
+// KeyValueListVar defines a key/value list config with a given name. The
+// argument p points to a []KeyValue variable in which to store the value of
+// the config.
+func KeyValueListVar(p *[]KeyValue, name string) {
+	global().KeyValueListVar(p, name)
+}
+
+// KeyValueList defines a key/value list config variable with a given name.
+func KeyValueList(name string) *[]KeyValue {
+	return global().KeyValueList(name)
+}
+
+// -- map[string]int64 Value
+
+type int64MapValue map[string]int64
+
+// Set parses a comma-separated list of key=value pairs.
+func (m *int64MapValue) Set(s string) error {
+	values := make(map[string]int64)
+	for _, pair := range splitList(s) {
+		eq := strings.Index(pair, "=")
+		if eq < 1 {
+			return fmt.Errorf("%q is not of the form key=value", pair)
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(pair[eq+1:]), 0, 64)
+		if err != nil {
+			return fmt.Errorf("the value for %s is not an integer", pair[:eq])
+		}
+		values[strings.TrimSpace(pair[:eq])] = v
+	}
+	*m = values
+	return nil
+}
+
+func (m *int64MapValue) setTOML(value interface{}) error {
+	tree, ok := value.(*toml.Tree)
+	if !ok {
+		return errParse
+	}
+	values := make(map[string]int64)
+	for _, key := range tree.Keys() {
+		// Keys like "pro.plus" are quoted, so they can't be passed to Get.
+		v, ok := tree.GetPath([]string{key}).(int64)
+		if !ok {
+			return fmt.Errorf("the value for %s is not an integer", key)
+		}
+		values[key] = v
+	}
+	*m = values
+	return nil
+}
+
+func (m *int64MapValue) isTable() {}
+
+func (m *int64MapValue) Get() interface{} { return map[string]int64(*m) }
+
+func (m *int64MapValue) snapshot() func() error {
+	v := maps.Clone(*m)
+	return func() error {
+		*m = v
+		return nil
+	}
+}
+
+func (m *int64MapValue) String() string {
+	pairs := make([]string, 0, len(*m))
+	for key, v := range *m {
+		pairs = append(pairs, key+"="+strconv.FormatInt(v, 10))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Int64MapVar defines a map[string]int64 config with a given name and default
+// value for a ConfigSet. The argument p points to a map[string]int64 variable
+// in which to store the value of the config.
+func (c *ConfigSet) Int64MapVar(p *map[string]int64, name string, value map[string]int64) {
+	*p = value
+	c.Var((*int64MapValue)(p), name, "")
+}
+
+// Int64Map defines a map[string]int64 config variable with a given name for a
+// ConfigSet. Its TOML value is a table of integers with any keys, such as
+// per-plan quotas. A table in a config file replaces the default map rather
+// than being merged into it.
+func (c *ConfigSet) Int64Map(name string) *map[string]int64 {
+	p := new(map[string]int64)
+	c.Int64MapVar(p, name, map[string]int64{})
+	return p
+}
+
+// Int64MapVar defines a map[string]int64 config with a given name and default
+// value. The argument p points to a map[string]int64 variable in which to
+// store the value of the config.
+func Int64MapVar(p *map[string]int64, name string, value map[string]int64) {
+	global().Int64MapVar(p, name, value)
+}
+
+// Int64Map defines a map[string]int64 config variable with a given name.
+func Int64Map(name string) *map[string]int64 {
+	return global().Int64Map(name)
+}
+
+// -- map[string]string Value
+
+type stringMapValue map[string]string
+
+// Set parses a comma-separated list of key=value pairs.
+func (m *stringMapValue) Set(s string) error {
+	values := make(map[string]string)
+	for _, pair := range splitList(s) {
+		eq := strings.Index(pair, "=")
+		if eq < 1 {
+			return fmt.Errorf("%q is not of the form key=value", pair)
+		}
+		values[strings.TrimSpace(pair[:eq])] = strings.TrimSpace(pair[eq+1:])
+	}
+	*m = values
+	return nil
+}
+
+// setTOML accepts a table of values of any type other than tables. Values
+// that aren't strings are formatted as they are by fmt.
+func (m *stringMapValue) setTOML(value interface{}) error {
+	tree, ok := value.(*toml.Tree)
+	if !ok {
+		return errParse
+	}
+	values := make(map[string]string)
+	for _, key := range tree.Keys() {
+		// Keys like "app.kubernetes.io/name" are quoted, so they can't be
+		// passed to Get.
+		v := tree.GetPath([]string{key})
+		if _, isTree := v.(*toml.Tree); isTree {
+			return fmt.Errorf("%s is a table, not a value", key)
+		}
+		values[key] = fmt.Sprintf("%v", v)
+	}
+	*m = values
+	return nil
+}
+
+func (m *stringMapValue) isTable() {}
+
+func (m *stringMapValue) Get() interface{} { return map[string]string(*m) }
+
+func (m *stringMapValue) snapshot() func() error {
+	v := maps.Clone(*m)
+	return func() error {
+		*m = v
+		return nil
+	}
+}
+
+func (m *stringMapValue) String() string {
+	pairs := make([]string, 0, len(*m))
+	for key, v := range *m {
+		pairs = append(pairs, key+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// StringMapVar defines a map[string]string config with a given name and
+// default value for a ConfigSet. The argument p points to a map[string]string
+// variable in which to store the value of the config.
+func (c *ConfigSet) StringMapVar(p *map[string]string, name string, value map[string]string) {
+	*p = value
+	c.Var((*stringMapValue)(p), name, "")
+}
+
+// StringMap defines a map[string]string config variable with a given name and
+// default value for a ConfigSet. Its TOML value is a whole table with any
+// keys, such as [labels], so the keys don't have to be defined as configs of
+// their own. A table in a config file replaces the default map rather than
+// being merged into it.
+func (c *ConfigSet) StringMap(name string, value map[string]string) *map[string]string {
+	p := new(map[string]string)
+	c.StringMapVar(p, name, value)
+	return p
+}
+
+// StringMapVar defines a map[string]string config with a given name and
+// default value. The argument p points to a map[string]string variable in
+// which to store the value of the config.
+func StringMapVar(p *map[string]string, name string, value map[string]string) {
+	global().StringMapVar(p, name, value)
+}
+
+// StringMap defines a map[string]string config variable with a given name and
+// default value.
+func StringMap(name string, value map[string]string) *map[string]string {
+	return global().StringMap(name, value)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStringSet(t *testing.T) {
+	var logs bytes.Buffer
+	c := NewConfigSet("collections", ContinueOnError)
+	c.SetLogger(log.New(&logs, "", 0))
+	allowed := c.StringSet("allowed", []string{"localhost"})
+
+	err := c.parseBytes("collections", []byte(`allowed = ["a.com", "b.com", "a.com"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"a.com", "b.com"}; !reflect.DeepEqual(*allowed, expected) {
+		t.Errorf("allowed should be %v, is %v", expected, *allowed)
+	}
+	if !strings.Contains(logs.String(), `allowed contains duplicate value "a.com"`) {
+		t.Error("Expected a duplicate warning, got", logs.String())
+	}
+
+	err = c.parseBytes("collections", []byte(`allowed = ["a.com", 2]`))
+	if err == nil || err.Error() != "allowed[1] is not a string at collections:1" {
+		t.Error("Expected an invalid element error, got", err)
+	}
+
+	c.WithPrefix("cors.").StringSet("origins", nil)
+	logs.Reset()
+	err = c.parseBytes("collections", []byte("[cors]\norigins = [\"a.com\", \"a.com\"]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), `cors.origins contains duplicate value "a.com"`) {
+		t.Error("Expected a duplicate warning with the full name, got", logs.String())
+	}
+}
+
+func TestKeyValueList(t *testing.T) {
+	c := NewConfigSet("collections", ContinueOnError)
+	headers := c.KeyValueList("headers")
+	opts := c.KeyValueList("jvm.opts")
+
+	err := c.parseBytes("collections", []byte(`
+jvm.opts = ["-Dz=1", "-Da=b=c"]
+
+[headers]
+X-Trace = "on"
+Accept = "*/*"
+"a.b" = "c"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []KeyValue{{"Accept", "*/*"}, {"X-Trace", "on"}, {"a.b", "c"}}; !reflect.DeepEqual(*headers, expected) {
+		t.Errorf("headers should be %v, is %v", expected, *headers)
+	}
+	if expected := []KeyValue{{"-Dz", "1"}, {"-Da", "b=c"}}; !reflect.DeepEqual(*opts, expected) {
+		t.Errorf("jvm.opts should be %v, is %v", expected, *opts)
+	}
+}
+
+func TestElementErrors(t *testing.T) {
+	c := NewConfigSet("collections", ContinueOnError)
+	c.StringSet("hosts", nil)
+	c.KeyValueList("env")
+
+	err := c.ParseLayers(Layers{Baseline: []byte(`
+hosts = ["a", "b"]
+
+env = [
+  "A=1",
+  "B",
+]
+`)})
+	if err == nil || err.Error() != `baseline config: env[1] ("B") is not of the form key=value at baseline:4` {
+		t.Error("Expected an element error, got", err)
+	}
+}
+
+func TestInt64Map(t *testing.T) {
+	c := NewConfigSet("collections", ContinueOnError)
+	quotas := c.Int64Map("quotas")
+
+	if *quotas == nil || len(*quotas) != 0 {
+		t.Fatal("quotas should default to an empty map, is", *quotas)
+	}
+
+	err := c.parseBytes("collections", []byte("[quotas]\nfree = 100\npro = 10000\n\"pro.plus\" = 10\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]int64{"free": 100, "pro": 10000, "pro.plus": 10}; !reflect.DeepEqual(*quotas, expected) {
+		t.Errorf("quotas should be %v, is %v", expected, *quotas)
+	}
+
+	err = c.parseBytes("collections", []byte("[quotas]\nfree = \"lots\"\n"))
+	if err == nil || err.Error() != "The value for quotas is invalid: the value for free is not an integer" {
+		t.Error("Expected an invalid quota error, got", err)
+	}
+}
+
+func TestStringMap(t *testing.T) {
+	c := NewConfigSet("collections", ContinueOnError)
+	labels := c.StringMap("labels", map[string]string{"team": "core"})
+	port := c.Int("port", 0)
+
+	err := c.parseBytes("collections", []byte("port = 80\n[labels]\nenv = \"prod\"\n\"app.kubernetes.io/name\" = \"api\"\nreplicas = 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"env": "prod", "app.kubernetes.io/name": "api", "replicas": "3"}; !reflect.DeepEqual(*labels, expected) {
+		t.Errorf("labels should be %v, is %v", expected, *labels)
+	}
+	if *port != 80 {
+		t.Error("port should be 80, is", *port)
+	}
+
+	if err := c.Set("labels", "env=dev, tier = web"); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Lookup("labels").Value.String(); s != "env=dev,tier=web" {
+		t.Error("Unexpected string:", s)
+	}
+
+	err = c.parseBytes("collections", []byte("[labels.nested]\na = \"b\"\n"))
+	if err == nil || err.Error() != "The value for labels is invalid: nested is a table, not a value" {
+		t.Error("Expected a nested table error, got", err)
+	}
+}
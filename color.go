@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// -- color.NRGBA Value
+
+type colorValue color.NRGBA
+
+// parseHexColor parses "#RGB", "#RGBA", "#RRGGBB", or "#RRGGBBAA". Colors
+// without an alpha component are opaque. As in CSS, the color components are
+// not premultiplied by the alpha, so the result is a color.NRGBA.
+func parseHexColor(s string) (color.NRGBA, error) {
+	invalid := fmt.Errorf("%q is not a valid hex color", s)
+	if len(s) == 0 || s[0] != '#' {
+		return color.NRGBA{}, invalid
+	}
+	hex := s[1:]
+
+	var width int
+	switch len(hex) {
+	case 3, 4:
+		width = 1
+	case 6, 8:
+		width = 2
+	default:
+		return color.NRGBA{}, invalid
+	}
+
+	components := []uint8{0, 0, 0, 0xff}
+	for i := 0; i*width < len(hex); i++ {
+		v, err := strconv.ParseUint(hex[i*width:(i+1)*width], 16, 8)
+		if err != nil {
+			return color.NRGBA{}, invalid
+		}
+		if width == 1 {
+			v *= 0x11
+		}
+		components[i] = uint8(v)
+	}
+	return color.NRGBA{components[0], components[1], components[2], components[3]}, nil
+}
+
+func (c *colorValue) Set(s string) error {
+	v, err := parseHexColor(s)
+	if err != nil {
+		return err
+	}
+	*c = colorValue(v)
+	return nil
+}
+
+func (c *colorValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return c.Set(s)
+	}
+	return errParse
+}
+
+func (c *colorValue) Get() interface{} { return color.NRGBA(*c) }
+
+func (c *colorValue) snapshot() func() error {
+	v := *c
+	return func() error {
+		*c = v
+		return nil
+	}
+}
+
+func (c *colorValue) String() string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// ColorVar defines a hex color config with a given name and default value for
+// a ConfigSet. The argument p points to a color.NRGBA variable in which to
+// store the value of the config. It panics if the default isn't a valid hex
+// color.
+func (c *ConfigSet) ColorVar(p *color.NRGBA, name string, value string) {
+	v := (*colorValue)(p)
+	if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// Color defines a hex color config variable with a given name and default
+// value for a ConfigSet. Values are written as "#RGB", "#RGBA", "#RRGGBB", or
+// "#RRGGBBAA".
+func (c *ConfigSet) Color(name string, value string) *color.NRGBA {
+	p := new(color.NRGBA)
+	c.ColorVar(p, name, value)
+	return p
+}
+
+// ColorVar defines a hex color config with a given name and default value. The
+// argument p points to a color.NRGBA variable in which to store the value of
+// the config.
+func ColorVar(p *color.NRGBA, name string, value string) {
+	global().ColorVar(p, name, value)
+}
+
+// Color defines a hex color config variable with a given name and default
+// value.
+func Color(name string, value string) *color.NRGBA {
+	return global().Color(name, value)
+}
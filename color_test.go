@@ -0,0 +1,29 @@
+package config
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	c := NewConfigSet("color", ContinueOnError)
+	fill := c.Color("badge.fill", "#000")
+	text := c.Color("badge.text", "#fff")
+
+	err := c.parseBytes("color", []byte("[badge]\nfill = \"#4c1\"\ntext = \"#11223380\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *fill != (color.NRGBA{0x44, 0xcc, 0x11, 0xff}) {
+		t.Error("badge.fill should be #44cc11, is", *fill)
+	}
+	if *text != (color.NRGBA{0x11, 0x22, 0x33, 0x80}) {
+		t.Error("badge.text should be #11223380, is", *text)
+	}
+
+	for _, bad := range []string{"4c1", "#4c", "#ggg", "#12345"} {
+		if _, err := parseHexColor(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
@@ -46,10 +46,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"hash"
+	"log"
 	"regexp"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/pelletier/go-toml"
@@ -59,154 +61,380 @@ import (
 
 type ConfigSet struct {
 	*flag.FlagSet
+
+	prefix  string
+	logger  *log.Logger
+	stats   Stats
+	applied map[string]string
+	trees   []*toml.Tree
+	loading string
+	sources []string
+	cmdline map[string]string
+	source  func() error
+	secrets map[string]bool
+
+	envPrefix   string
+	envBindings map[string][]string
+	earlyAccess EarlyAccess
+	frozen      bool
+
+	info       LoadInfo
+	generation uint64
+	hash       hash.Hash
+
+	options       parseOptions
+	rotations     map[string]*rotation
+	reloading     bool
+	decrypter     Decrypter
+	fileDecrypter FileDecrypter
+	persister     Persister
+
+	errorTemplates map[ErrorKind]*template.Template
+	required       map[string]bool
+	previous       *Settings
+	rollback       *snapshot
+	derived        map[string]*derivedValue
+	expressions    bool
+	relative       map[string]bool
+	limits         Limits
+	keysLoaded     int
+	lenientText    bool
+	duplicateKeys  DuplicateKeys
+	invalidValues  InvalidValues
+	decoders       map[string]Decoder
+	lineOffset     int
+	cache          *loadCache
+
+	// defaults restore each config to the value it was defined with, so
+	// that a key removed from a file goes back to its default on reload.
+	defaults map[string]func() error
+
+	// mu serializes Reload, Rollback, Tx.Commit, and Dump, which may be
+	// called from different goroutines. It's shared with views from
+	// WithPrefix.
+	mu *sync.Mutex
+}
+
+// Var defines a config variable with the given flag.Value and name. It's used
+// by all of the typed definition methods, and may be used directly to define
+// configs of custom types.
+func (c *ConfigSet) Var(value flag.Value, name string, usage string) {
+	c.FlagSet.Var(value, c.prefix+name, usage)
+	c.defaults[c.prefix+name] = saveValue(value)
 }
 
 // BoolVar defines a bool config with a given name and default value for a ConfigSet.
 // The argument p points to a bool variable in which to store the value of the config.
 func (c *ConfigSet) BoolVar(p *bool, name string, value bool) {
-	c.FlagSet.BoolVar(p, name, value, "")
+	c.Var(newBoolValue(value, p), name, "")
 }
 
 // Bool defines a bool config variable with a given name and default value for
 // a ConfigSet.
 func (c *ConfigSet) Bool(name string, value bool) *bool {
-	return c.FlagSet.Bool(name, value, "")
+	p := new(bool)
+	c.BoolVar(p, name, value)
+	return p
 }
 
 // IntVar defines a int config with a given name and default value for a ConfigSet.
 // The argument p points to a int variable in which to store the value of the config.
 func (c *ConfigSet) IntVar(p *int, name string, value int) {
-	c.FlagSet.IntVar(p, name, value, "")
+	c.Var(newIntValue(value, p), name, "")
 }
 
 // Int defines a int config variable with a given name and default value for a
 // ConfigSet.
 func (c *ConfigSet) Int(name string, value int) *int {
-	return c.FlagSet.Int(name, value, "")
+	p := new(int)
+	c.IntVar(p, name, value)
+	return p
 }
 
 // Int64Var defines a int64 config with a given name and default value for a ConfigSet.
 // The argument p points to a int64 variable in which to store the value of the config.
 func (c *ConfigSet) Int64Var(p *int64, name string, value int64) {
-	c.FlagSet.Int64Var(p, name, value, "")
+	c.Var(newInt64Value(value, p), name, "")
 }
 
 // Int64 defines a int64 config variable with a given name and default value
 // for a ConfigSet.
 func (c *ConfigSet) Int64(name string, value int64) *int64 {
-	return c.FlagSet.Int64(name, value, "")
+	p := new(int64)
+	c.Int64Var(p, name, value)
+	return p
 }
 
 // UintVar defines a uint config with a given name and default value for a ConfigSet.
 // The argument p points to a uint variable in which to store the value of the config.
 func (c *ConfigSet) UintVar(p *uint, name string, value uint) {
-	c.FlagSet.UintVar(p, name, value, "")
+	c.Var(newUintValue(value, p), name, "")
 }
 
 // Uint defines a uint config variable with a given name and default value for
 // a ConfigSet.
 func (c *ConfigSet) Uint(name string, value uint) *uint {
-	return c.FlagSet.Uint(name, value, "")
+	p := new(uint)
+	c.UintVar(p, name, value)
+	return p
 }
 
 // Uint64Var defines a uint64 config with a given name and default value for a ConfigSet.
 // The argument p points to a uint64 variable in which to store the value of the config.
 func (c *ConfigSet) Uint64Var(p *uint64, name string, value uint64) {
-	c.FlagSet.Uint64Var(p, name, value, "")
+	c.Var(newUint64Value(value, p), name, "")
 }
 
 // Uint64 defines a uint64 config variable with a given name and default value
 // for a ConfigSet.
 func (c *ConfigSet) Uint64(name string, value uint64) *uint64 {
-	return c.FlagSet.Uint64(name, value, "")
+	p := new(uint64)
+	c.Uint64Var(p, name, value)
+	return p
 }
 
 // StringVar defines a string config with a given name and default value for a ConfigSet.
 // The argument p points to a string variable in which to store the value of the config.
 func (c *ConfigSet) StringVar(p *string, name string, value string) {
-	c.FlagSet.StringVar(p, name, value, "")
+	c.Var(newStringValue(value, p), name, "")
 }
 
 // String defines a string config variable with a given name and default value
 // for a ConfigSet.
 func (c *ConfigSet) String(name string, value string) *string {
-	return c.FlagSet.String(name, value, "")
+	p := new(string)
+	c.StringVar(p, name, value)
+	return p
 }
 
 // Float64Var defines a float64 config with a given name and default value for a ConfigSet.
 // The argument p points to a float64 variable in which to store the value of the config.
 func (c *ConfigSet) Float64Var(p *float64, name string, value float64) {
-	c.FlagSet.Float64Var(p, name, value, "")
+	c.Var(newFloat64Value(value, p), name, "")
 }
 
 // Float64 defines a float64 config variable with a given name and default
 // value for a ConfigSet.
 func (c *ConfigSet) Float64(name string, value float64) *float64 {
-	return c.FlagSet.Float64(name, value, "")
+	p := new(float64)
+	c.Float64Var(p, name, value)
+	return p
 }
 
 // DurationVar defines a time.Duration config with a given name and default value for a ConfigSet.
 // The argument p points to a time.Duration variable in which to store the value of the config.
 func (c *ConfigSet) DurationVar(p *time.Duration, name string, value time.Duration) {
-	c.FlagSet.DurationVar(p, name, value, "")
+	c.Var(newDurationValue(value, p), name, "")
 }
 
 // Duration defines a time.Duration config variable with a given name and
 // default value.
 func (c *ConfigSet) Duration(name string, value time.Duration) *time.Duration {
-	return c.FlagSet.Duration(name, value, "")
+	p := new(time.Duration)
+	c.DurationVar(p, name, value)
+	return p
 }
 
 // Parse takes a path to a TOML file and loads it. This must be called after
 // all the config flags in the ConfigSet have been defined but before the flags
-// are accessed by the program.
-func (c *ConfigSet) Parse(path string) error {
-	configBytes, err := ioutil.ReadFile(path)
+// are accessed by the program. Options such as WithProfile change how the
+// file is interpreted.
+func (c *ConfigSet) Parse(path string, opts ...ParseOption) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.Parse(path, opts...) }
+	var key string
+	if len(opts) == 0 {
+		key = loadKey("parse", path)
+	}
+	if c.unchanged(key) {
+		return nil
+	}
+	err = c.beginLoad()
+	defer c.endLoad(&err)
 	if err != nil {
 		return err
 	}
+	c.cache.key = key
+	for _, opt := range opts {
+		opt(&c.options)
+	}
 
-	tomlTree, err := toml.Load(string(configBytes))
+	configBytes, err := c.readFile(path)
 	if err != nil {
-		errorString := fmt.Sprintf("%s is not a valid TOML file. See https://github.com/mojombo/toml", path)
-		return errors.New(errorString)
+		return c.fileError(path, err)
 	}
+	c.recordFile(path)
+
+	return c.parseBytes(path, configBytes)
+}
 
-	err = c.loadTomlTree(tomlTree, []string{})
+// parseBytes loads the TOML document in configBytes. The name is used in
+// error messages and is usually the path the document was read from.
+func (c *ConfigSet) parseBytes(name string, configBytes []byte) error {
+	if err := c.checkSize(name, len(configBytes)); err != nil {
+		return err
+	}
+	configBytes = c.normalizeText(name, configBytes)
+	start := time.Now()
+	tomlTree, err := c.decode(name, configBytes)
+	c.stats.DecodeTime += time.Since(start)
 	if err != nil {
+		c.recordDocument(configBytes)
 		return err
 	}
 
-	return nil
+	return c.loadDocument(name, configBytes, tomlTree)
+}
+
+// loadDocument loads a decoded TOML document into the ConfigSet's config
+// variables and keeps its tree for later inspection. The name is used in
+// error messages.
+func (c *ConfigSet) loadDocument(name string, configBytes []byte, tree *toml.Tree) error {
+	c.recordDocument(configBytes)
+	if err := c.options.apply(name, tree); err != nil {
+		return err
+	}
+	c.trees = append(c.trees, tree)
+	c.startSource(name)
+	return c.loadTomlTree(tree, []string{})
+}
+
+// decodeTOML decodes a TOML document without loading it into a ConfigSet.
+// Keys and tables defined more than once are handled according to the
+// ConfigSet's DuplicateKeys policy.
+func (c *ConfigSet) decodeTOML(name string, configBytes []byte) (*toml.Tree, error) {
+	tomlTree, err := loadTOML(configBytes)
+	if err != nil {
+		if duplicates := findDuplicates(configBytes); len(duplicates) > 0 {
+			if c.duplicateKeys != DuplicateKeysWarn {
+				return nil, errors.New(duplicates[0].describe(name))
+			}
+			tomlTree, err = c.decodeDuplicates(name, configBytes, duplicates)
+		}
+	}
+	if err != nil {
+		errorString := fmt.Sprintf("%s is not a valid TOML file. See https://github.com/mojombo/toml", name)
+		return nil, errors.New(errorString)
+	}
+	return tomlTree, nil
 }
 
 // loadTomlTree recursively loads a toml.Tree into this ConfigSet's config
 // variables.
 func (c *ConfigSet) loadTomlTree(tree *toml.Tree, path []string) error {
 	for _, key := range tree.Keys() {
-		fullPath := append(path, key)
+		fullPath := append(path[:len(path):len(path)], key)
 		value := tree.Get(key)
-		if subtree, isTree := value.(*toml.Tree); isTree {
+		if err := c.checkKey(fullPath); err != nil {
+			return err
+		}
+		if subtree, isTree := value.(*toml.Tree); isTree && !c.isTable(fullPath) {
 			err := c.loadTomlTree(subtree, fullPath)
 			if err != nil {
 				return err
 			}
 		} else {
-			fullPath := strings.Join(append(path, key), ".")
-			err := c.Set(fullPath, fmt.Sprintf("%v", value))
+			pos := c.loading
+			if line := tree.GetPosition(key).Line; line > 0 {
+				pos = fmt.Sprintf("%s:%d", c.loading, c.lineOffset+line)
+			}
+			err := c.applyValue(strings.Join(fullPath, "."), value, pos)
 			if err != nil {
-				return buildLoadError(fullPath, err)
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// isTable reports whether the config variable at path is loaded from a whole
+// TOML table, rather than the table's keys being config variables themselves.
+func (c *ConfigSet) isTable(path []string) bool {
+	f := c.Lookup(strings.Join(path, "."))
+	if f == nil {
+		return false
+	}
+	_, ok := f.Value.(tableValue)
+	return ok
+}
+
+// applyValue sets the named config variable to a decoded TOML value. Values
+// defined by this package are assigned directly; other flag.Values are
+// formatted as a string and passed to Set. The pos describes where the value
+// came from, such as "app.conf:9", and is included in errors about individual
+// array elements if it's not empty.
+func (c *ConfigSet) applyValue(name string, value interface{}, pos string) error {
+	f := c.Lookup(name)
+	if f == nil {
+		err := errors.New(name + " is not a valid config setting")
+		return c.formatError(UnknownKey, ErrorData{Name: name, Pos: pos}, err)
+	}
+	if c.frozen {
+		return &FrozenError{name, caller()}
+	}
+	var restore func() error
+	if c.invalidValues == InvalidValuesWarn {
+		restore = saveValue(f.Value)
+	}
+	raw := value
+
+	value, err := c.decrypt(name, value)
+	if err == nil && c.expressions {
+		value, err = evalNumeric(f.Value, value)
+	}
+	if err == nil && c.relative[name] {
+		value = c.resolvePaths(value)
+	}
+	if err == nil {
+		if v, ok := f.Value.(tomlValue); ok {
+			err = v.setTOML(value)
+		} else if err := f.Value.Set(fmt.Sprintf("%v", value)); err != nil {
+			return c.rejectValue(name, raw, pos, buildLoadError(name, err), restore)
+		}
+	}
+	if err != nil {
+		return c.rejectValue(name, raw, pos, c.valueError(name, err, pos), restore)
+	}
+
+	if pos == "" {
+		pos = c.loading
+	}
+	c.recordApplied(name, pos)
+	return nil
+}
+
+// elementLoadError builds the error returned when an element of an array
+// value is invalid, such as "ports[3] is not a valid integer at app.conf:9".
+func elementLoadError(name string, err elementError, pos string) error {
+	msg := fmt.Sprintf("%s[%d] %s", name, err.index, err.reason)
+	if pos != "" {
+		msg += " at " + pos
+	}
+	return errors.New(msg)
+}
+
+// invalidValueError builds the error returned when a config variable can't
+// hold the value it was given.
+func invalidValueError(name string, err error) error {
+	if le, ok := err.(loadError); ok {
+		return le.error
+	}
+	if err == errParse {
+		return errors.New("The value for " + name + " is invalid")
+	}
+	return fmt.Errorf("The value for %s is invalid: %s", name, err)
+}
+
+var (
+	missingFlag   = regexp.MustCompile(`^no such flag -([^\s]+)`)
+	invalidSyntax = regexp.MustCompile(`^.+ parsing "(.+)": invalid syntax$`)
+)
+
 // buildLoadError takes an error from flag.FlagSet#Set and makes it a bit more
 // readable, if it recognizes the format.
 func buildLoadError(path string, err error) error {
-	missingFlag := regexp.MustCompile(`^no such flag -([^\s]+)`)
-	invalidSyntax := regexp.MustCompile(`^.+ parsing "(.+)": invalid syntax$`)
 	errorString := err.Error()
 
 	if missingFlag.MatchString(errorString) {
@@ -229,107 +457,160 @@ const (
 // flag.ExitOnError, and flag.PanicOnError.
 func NewConfigSet(name string, errorHandling flag.ErrorHandling) *ConfigSet {
 	return &ConfigSet{
-		flag.NewFlagSet(name, errorHandling),
+		FlagSet:        flag.NewFlagSet(name, errorHandling),
+		applied:        make(map[string]string),
+		cmdline:        make(map[string]string),
+		secrets:        make(map[string]bool),
+		envBindings:    make(map[string][]string),
+		rotations:      make(map[string]*rotation),
+		errorTemplates: make(map[ErrorKind]*template.Template),
+		required:       make(map[string]bool),
+		derived:        make(map[string]*derivedValue),
+		relative:       make(map[string]bool),
+		decoders:       make(map[string]Decoder),
+		cache:          &loadCache{files: make(map[string]fileState)},
+		mu:             new(sync.Mutex),
+		defaults:       make(map[string]func() error),
+	}
+}
+
+// SetLogger sets the logger used to report warnings, such as duplicate
+// entries in a StringSet. Warnings are discarded if the logger is nil, which
+// is the default.
+func (c *ConfigSet) SetLogger(logger *log.Logger) {
+	c.logger = logger
+}
+
+// warnf logs a warning if the ConfigSet has a logger.
+func (c *ConfigSet) warnf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf("config: "+format, v...)
 	}
 }
 
 // -- globalConfig
 
-var globalConfig = NewConfigSet(os.Args[0], flag.ExitOnError)
+// Var defines a config variable with the given flag.Value and name.
+func Var(value flag.Value, name string, usage string) {
+	global().Var(value, name, usage)
+}
 
 // BoolVar defines a bool config with a given name and default value.
 // The argument p points to a bool variable in which to store the value of the config.
 func BoolVar(p *bool, name string, value bool) {
-	globalConfig.BoolVar(p, name, value)
+	global().BoolVar(p, name, value)
 }
 
 // Bool defines a bool config variable with a given name and default value.
 func Bool(name string, value bool) *bool {
-	return globalConfig.Bool(name, value)
+	return global().Bool(name, value)
 }
 
 // IntVar defines a int config with a given name and default value.
 // The argument p points to a int variable in which to store the value of the config.
 func IntVar(p *int, name string, value int) {
-	globalConfig.IntVar(p, name, value)
+	global().IntVar(p, name, value)
 }
 
 // Int defines a int config variable with a given name and default value.
 func Int(name string, value int) *int {
-	return globalConfig.Int(name, value)
+	return global().Int(name, value)
 }
 
 // Int64Var defines a int64 config with a given name and default value.
 // The argument p points to a int64 variable in which to store the value of the config.
 func Int64Var(p *int64, name string, value int64) {
-	globalConfig.Int64Var(p, name, value)
+	global().Int64Var(p, name, value)
 }
 
 // Int64 defines a int64 config variable with a given name and default value.
 func Int64(name string, value int64) *int64 {
-	return globalConfig.Int64(name, value)
+	return global().Int64(name, value)
 }
 
 // UintVar defines a uint config with a given name and default value.
 // The argument p points to a uint variable in which to store the value of the config.
 func UintVar(p *uint, name string, value uint) {
-	globalConfig.UintVar(p, name, value)
+	global().UintVar(p, name, value)
 }
 
 // Uint defines a uint config variable with a given name and default value.
 func Uint(name string, value uint) *uint {
-	return globalConfig.Uint(name, value)
+	return global().Uint(name, value)
 }
 
 // Uint64Var defines a uint64 config with a given name and default value.
 // The argument p points to a uint64 variable in which to store the value of the config.
 func Uint64Var(p *uint64, name string, value uint64) {
-	globalConfig.Uint64Var(p, name, value)
+	global().Uint64Var(p, name, value)
 }
 
 // Uint64 defines a uint64 config variable with a given name and default value.
 func Uint64(name string, value uint64) *uint64 {
-	return globalConfig.Uint64(name, value)
+	return global().Uint64(name, value)
 }
 
 // StringVar defines a string config with a given name and default value.
 // The argument p points to a string variable in which to store the value of the config.
 func StringVar(p *string, name string, value string) {
-	globalConfig.StringVar(p, name, value)
+	global().StringVar(p, name, value)
 }
 
 // String defines a string config variable with a given name and default value.
 func String(name string, value string) *string {
-	return globalConfig.String(name, value)
+	return global().String(name, value)
 }
 
 // Float64Var defines a float64 config with a given name and default value.
 // The argument p points to a float64 variable in which to store the value of the config.
 func Float64Var(p *float64, name string, value float64) {
-	globalConfig.Float64Var(p, name, value)
+	global().Float64Var(p, name, value)
 }
 
 // Float64 defines a float64 config variable with a given name and default
 // value.
 func Float64(name string, value float64) *float64 {
-	return globalConfig.Float64(name, value)
+	return global().Float64(name, value)
 }
 
 // DurationVar defines a time.Duration config with a given name and default value.
 // The argument p points to a time.Duration variable in which to store the value of the config.
 func DurationVar(p *time.Duration, name string, value time.Duration) {
-	globalConfig.DurationVar(p, name, value)
+	global().DurationVar(p, name, value)
 }
 
 // Duration defines a time.Duration config variable with a given name and
 // default value.
 func Duration(name string, value time.Duration) *time.Duration {
-	return globalConfig.Duration(name, value)
+	return global().Duration(name, value)
+}
+
+// ParseLayers loads layered configuration into the global ConfigSet. See
+// ConfigSet.ParseLayers.
+func ParseLayers(layers Layers) error {
+	return handleGlobalError(global().ParseLayers(layers))
+}
+
+// ParseFiles loads several TOML files into the global ConfigSet. See
+// ConfigSet.ParseFiles.
+func ParseFiles(paths ...string) error {
+	return handleGlobalError(global().ParseFiles(paths...))
+}
+
+// ParseDir loads a conf.d-style directory of TOML files into the global
+// ConfigSet. See ConfigSet.ParseDir.
+func ParseDir(dir string) error {
+	return handleGlobalError(global().ParseDir(dir))
+}
+
+// SetLogger sets the logger used by the global ConfigSet to report warnings.
+func SetLogger(logger *log.Logger) {
+	global().SetLogger(logger)
 }
 
 // Parse takes a path to a TOML file and loads it into the global ConfigSet.
 // This must be called after all config flags have been defined but before the
 // flags are accessed by the program.
-func Parse(path string) error {
-	return globalConfig.Parse(path)
+func Parse(path string, opts ...ParseOption) error {
+	return handleGlobalError(global().Parse(path, opts...))
 }
@@ -105,8 +105,8 @@ func testGoodParse(t *testing.T, c *ConfigSet) {
 }
 
 func TestParse(t *testing.T) {
-	testBadParse(t, globalConfig)
+	testBadParse(t, global())
 	testBadParse(t, NewConfigSet("App Config", flag.ExitOnError))
-	testGoodParse(t, globalConfig)
+	testGoodParse(t, global())
 	testGoodParse(t, NewConfigSet("App Config", flag.ExitOnError))
 }
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Locker is a lock shared by every instance of a service, such as a lock held
+// by an etcd or Consul session.
+type Locker interface {
+	// Lock blocks until the lock is acquired or ctx is done, and returns a
+	// function that releases it.
+	Lock(ctx context.Context) (unlock func(), err error)
+}
+
+// Coordination describes how instances of a service stagger their reloads so
+// that a bad config isn't applied by the whole fleet at the same instant.
+type Coordination struct {
+	// Locker is acquired around each reload, so that instances reload one at
+	// a time.
+	Locker Locker
+
+	// Spread is the longest random delay before an instance tries to acquire
+	// the lock, spreading reloads out over the interval even without one.
+	Spread time.Duration
+
+	// Hold is how long the lock is held after reloading, giving health checks
+	// and alerts time to notice a bad config before the next instance loads
+	// it.
+	Hold time.Duration
+}
+
+// ReloadCoordinated calls Reload after waiting a random delay of up to
+// co.Spread and acquiring co.Locker, if set, which is released co.Hold after
+// the reload. It returns early with ctx's error if ctx is done while it
+// waits, in which case the config isn't reloaded.
+func (c *ConfigSet) ReloadCoordinated(ctx context.Context, co Coordination) error {
+	if co.Spread > 0 {
+		if err := sleep(ctx, time.Duration(rand.Int63n(int64(co.Spread)))); err != nil {
+			return err
+		}
+	}
+
+	if co.Locker != nil {
+		unlock, err := co.Locker.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if err := c.Reload(); err != nil {
+		return err
+	}
+	if co.Locker != nil && co.Hold > 0 {
+		// Reloading succeeded, so a canceled ctx only shortens the hold.
+		sleep(ctx, co.Hold)
+	}
+	return nil
+}
+
+// sleep waits for d or until ctx is done, returning ctx's error in that case.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReloadCoordinated reloads the global config, staggered with other instances
+// as described by co. See ConfigSet.ReloadCoordinated.
+func ReloadCoordinated(ctx context.Context, co Coordination) error {
+	return handleGlobalError(global().ReloadCoordinated(ctx, co))
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLocker is a Locker for instances in the same process.
+type testLocker struct {
+	locked chan struct{}
+}
+
+func (l *testLocker) Lock(ctx context.Context) (func(), error) {
+	select {
+	case l.locked <- struct{}{}:
+		return func() { <-l.locked }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestReloadCoordinated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coordinate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.toml")
+	ioutil.WriteFile(path, []byte("port = 1\n"), 0644)
+
+	var instances []*int
+	var sets []*ConfigSet
+	for i := 0; i < 3; i++ {
+		c := NewConfigSet("coordinate", ContinueOnError)
+		instances = append(instances, c.Int("port", 0))
+		if err := c.Parse(path); err != nil {
+			t.Fatal(err)
+		}
+		sets = append(sets, c)
+	}
+	ioutil.WriteFile(path, []byte("port = 2\n"), 0644)
+
+	locker := &testLocker{locked: make(chan struct{}, 1)}
+	co := Coordination{Locker: locker, Spread: 10 * time.Millisecond, Hold: 20 * time.Millisecond}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range sets {
+		wg.Add(1)
+		go func(c *ConfigSet) {
+			defer wg.Done()
+			if err := c.ReloadCoordinated(context.Background(), co); err != nil {
+				t.Error(err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 3*co.Hold {
+		t.Error("Reloads should be held one after another, took", elapsed)
+	}
+	for i, port := range instances {
+		if *port != 2 {
+			t.Errorf("Instance %d wasn't reloaded: %d", i, *port)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	locker.locked <- struct{}{}
+	cancel()
+	if err := sets[0].ReloadCoordinated(ctx, co); err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
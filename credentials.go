@@ -0,0 +1,77 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type credentialsProvider struct {
+	mapping map[string]string
+}
+
+// Credentials returns a Provider that reads secrets from the directory named
+// by $CREDENTIALS_DIRECTORY, which systemd populates for services that use
+// LoadCredential= or SetCredential=, so secrets never appear in the unit's
+// environment or in a config file. The mapping gives the setting each
+// credential is loaded into, such as "db-password" to "db.password". If
+// mapping is nil, every credential is loaded into the setting with the same
+// name. Each setting is set to the contents of its credential file, without a
+// trailing newline. Credentials that don't exist are skipped, and no settings
+// are provided if $CREDENTIALS_DIRECTORY isn't set. Consider marking the
+// settings Sensitive.
+func Credentials(mapping map[string]string) Provider {
+	return &credentialsProvider{mapping}
+}
+
+func (p *credentialsProvider) Name() string { return "credentials" }
+
+func (p *credentialsProvider) Load() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return values, nil
+	}
+
+	mapping := p.mapping
+	if mapping == nil {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		mapping = make(map[string]string)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				mapping[entry.Name()] = entry.Name()
+			}
+		}
+	}
+
+	for credential, name := range mapping {
+		secret, err := ioutil.ReadFile(filepath.Join(dir, credential))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		setPath(values, name, strings.TrimSuffix(string(secret), "\n"))
+	}
+	return values, nil
+}
+
+// setPath sets the value of a dotted config name in a map of nested tables,
+// creating the tables as needed.
+func setPath(values map[string]interface{}, name string, value interface{}) {
+	keys := strings.Split(name, ".")
+	for _, key := range keys[:len(keys)-1] {
+		table, ok := values[key].(map[string]interface{})
+		if !ok {
+			table = make(map[string]interface{})
+			values[key] = table
+		}
+		values = table
+	}
+	values[keys[len(keys)-1]] = value
+}
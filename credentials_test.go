@@ -0,0 +1,49 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "db-password"), []byte("hunter2\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "api.token"), []byte("abc123"), 0600)
+
+	c := NewConfigSet("credentials", ContinueOnError)
+	password := c.String("db.password", "")
+	token := c.String("api.token", "")
+
+	os.Unsetenv("CREDENTIALS_DIRECTORY")
+	if err := c.ParseProvider(Credentials(nil)); err != nil || *password != "" {
+		t.Error("No credentials should be loaded without $CREDENTIALS_DIRECTORY", err)
+	}
+
+	os.Setenv("CREDENTIALS_DIRECTORY", dir)
+	defer os.Unsetenv("CREDENTIALS_DIRECTORY")
+
+	p := Credentials(map[string]string{"db-password": "db.password", "missing": "api.token"})
+	if err := c.ParseProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "hunter2" {
+		t.Error("db.password should be loaded from its credential, is", *password)
+	}
+	if *token != "" {
+		t.Error("api.token should be left unset, is", *token)
+	}
+
+	os.Remove(filepath.Join(dir, "db-password"))
+	if err := c.ParseProvider(Credentials(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if *token != "abc123" {
+		t.Error("api.token should be loaded by name, is", *token)
+	}
+}
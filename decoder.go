@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Decoder decodes config files in a format other than TOML, so that support
+// for other formats can live in separate packages and only binaries that use
+// them pay for their dependencies. See the jsonconfig package for an
+// implementation.
+type Decoder interface {
+	// Decode returns the settings in a document. Tables are represented by
+	// nested map[string]interface{} values, and values by the types the TOML
+	// decoder produces, such as string, int64, and []interface{}.
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// RegisterDecoder sets the Decoder used for config files whose names end in
+// ext, such as ".json", instead of the TOML decoder. Compressed files are
+// matched by their names without ".gz". ParseDir loads files ending in ext
+// along with TOML files.
+func (c *ConfigSet) RegisterDecoder(ext string, d Decoder) {
+	c.decoders[ext] = d
+}
+
+// decoderFor returns the Decoder registered for the extension of the named
+// file, preferring the longest, or nil if the file is TOML.
+func (c *ConfigSet) decoderFor(name string) Decoder {
+	name = strings.TrimSuffix(name, ".gz")
+	var match string
+	for ext := range c.decoders {
+		if strings.HasSuffix(name, ext) && len(ext) > len(match) {
+			match = ext
+		}
+	}
+	return c.decoders[match]
+}
+
+// decode decodes a document with the Decoder registered for its name, or as
+// TOML if there isn't one.
+func (c *ConfigSet) decode(name string, configBytes []byte) (*toml.Tree, error) {
+	d := c.decoderFor(name)
+	if d == nil {
+		return c.decodeTOML(name, configBytes)
+	}
+	values, err := d.Decode(configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s can't be decoded: %s", name, err)
+	}
+	tree, err := toml.TreeFromMap(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return tree, nil
+}
+
+// RegisterDecoder sets the Decoder used for config files whose names end in
+// ext instead of the TOML decoder.
+func RegisterDecoder(ext string, d Decoder) {
+	global().RegisterDecoder(ext, d)
+}
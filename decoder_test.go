@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type decoderFunc func(data []byte) (map[string]interface{}, error)
+
+func (f decoderFunc) Decode(data []byte) (map[string]interface{}, error) { return f(data) }
+
+func TestRegisterDecoder(t *testing.T) {
+	c := NewConfigSet("decoder", ContinueOnError)
+	name := c.String("name", "")
+	port := c.Int("db.port", 0)
+
+	c.RegisterDecoder(".kv", decoderFunc(func(data []byte) (map[string]interface{}, error) {
+		if string(data) == "" {
+			return nil, errors.New("empty document")
+		}
+		return map[string]interface{}{
+			"name": string(data),
+			"db":   map[string]interface{}{"port": int64(5432)},
+		}, nil
+	}))
+	c.RegisterDecoder(".raw.kv", decoderFunc(func(data []byte) (map[string]interface{}, error) {
+		return map[string]interface{}{"name": "raw"}, nil
+	}))
+
+	if err := c.parseBytes("app.kv", []byte("kv")); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "kv" || *port != 5432 {
+		t.Errorf("Unexpected settings: %q %d", *name, *port)
+	}
+	if err := c.parseBytes("app.raw.kv.gz", []byte("kv")); err != nil || *name != "raw" {
+		t.Error("The longest extension should win:", err, *name)
+	}
+	if err := c.parseBytes("app.conf", []byte("name = \"toml\"\n")); err != nil || *name != "toml" {
+		t.Error("Other files should be decoded as TOML:", err, *name)
+	}
+	err := c.parseBytes("app.kv", nil)
+	if err == nil || err.Error() != "app.kv can't be decoded: empty document" {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Decrypter decrypts encrypted values in config files, so that individual
+// secrets can be committed to version control while the rest of the file
+// stays readable. See the ageconfig package for an implementation.
+type Decrypter interface {
+	// Decrypt returns the plaintext of value and true if value is encrypted
+	// in a form the Decrypter handles, or false if it isn't encrypted.
+	Decrypt(value string) (plaintext string, ok bool, err error)
+}
+
+// SetDecrypter sets the Decrypter used to decrypt string values, including
+// the strings in arrays, as they are loaded. Configs loaded from encrypted
+// values are marked Sensitive. Values aren't decrypted if d is nil, which is
+// the default.
+func (c *ConfigSet) SetDecrypter(d Decrypter) {
+	c.decrypter = d
+}
+
+// decrypt decrypts the named config's value if it's encrypted.
+func (c *ConfigSet) decrypt(name string, value interface{}) (interface{}, error) {
+	if c.decrypter == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		plaintext, ok, err := c.decrypter.Decrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("can't decrypt: %s", err)
+		}
+		if ok {
+			c.secrets[name] = true
+			return plaintext, nil
+		}
+	case []interface{}:
+		decrypted := make([]interface{}, len(v))
+		for i, elem := range v {
+			d, err := c.decrypt(name, elem)
+			if err != nil {
+				return nil, elementError{i, err.Error()}
+			}
+			decrypted[i] = d
+		}
+		return decrypted, nil
+	}
+	return value, nil
+}
+
+// FileDecrypter decrypts whole config files, such as "app.toml.gpg". See the
+// gpgconfig package for an implementation.
+type FileDecrypter interface {
+	// DecryptFile returns the plaintext of the file at path, whose contents
+	// are data, and true if the file is encrypted in a form the
+	// FileDecrypter handles, or false if it isn't encrypted.
+	DecryptFile(path string, data []byte) (plaintext []byte, ok bool, err error)
+}
+
+// SetFileDecrypter sets the FileDecrypter used to decrypt config files before
+// they are decoded. Files aren't decrypted if d is nil, which is the default.
+func (c *ConfigSet) SetFileDecrypter(d FileDecrypter) {
+	c.fileDecrypter = d
+}
+
+// readFile reads a config file, decrypting and decompressing it if
+// necessary.
+func (c *ConfigSet) readFile(path string) ([]byte, error) {
+	data, err := c.readLimited(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.fileDecrypter != nil {
+		plaintext, ok, err := c.fileDecrypter.DecryptFile(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s can't be decrypted: %s", path, err)
+		}
+		if ok {
+			if err := c.checkSize(path, len(plaintext)); err != nil {
+				return nil, err
+			}
+			data = plaintext
+		}
+	}
+	return c.decompress(path, data)
+}
+
+// SetDecrypter sets the Decrypter used to decrypt string values as they are
+// loaded.
+func SetDecrypter(d Decrypter) {
+	global().SetDecrypter(d)
+}
+
+// SetFileDecrypter sets the FileDecrypter used to decrypt config files before
+// they are decoded.
+func SetFileDecrypter(d FileDecrypter) {
+	global().SetFileDecrypter(d)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// rot13Decrypter decrypts values written as "rot13:" followed by ROT13 text.
+type rot13Decrypter struct{}
+
+func (rot13Decrypter) Decrypt(value string) (string, bool, error) {
+	if !strings.HasPrefix(value, "rot13:") {
+		return "", false, nil
+	}
+	if strings.ContainsAny(strings.TrimPrefix(value, "rot13:"), "0123456789") {
+		return "", false, errors.New("digits can't be decrypted")
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 'a' + (r-'a'+13)%26
+		case r >= 'A' && r <= 'Z':
+			return 'A' + (r-'A'+13)%26
+		}
+		return r
+	}, strings.TrimPrefix(value, "rot13:")), true, nil
+}
+
+func TestSetDecrypter(t *testing.T) {
+	c := NewConfigSet("decrypt", ContinueOnError)
+	password := c.String("db.password", "")
+	user := c.String("db.user", "")
+	keys := c.StringSet("api.keys", nil)
+	c.SetDecrypter(rot13Decrypter{})
+
+	err := c.parseBytes("decrypt", []byte(`
+[db]
+password = "rot13:uhagre"
+user = "app"
+
+[api]
+keys = ["plain", "rot13:frperg"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *password != "hunter" || *user != "app" {
+		t.Error("Unexpected db settings:", *password, *user)
+	}
+	if len(*keys) != 2 || (*keys)[1] != "secret" {
+		t.Error("Unexpected api.keys:", *keys)
+	}
+	if !c.secrets["db.password"] || c.secrets["db.user"] {
+		t.Error("Only decrypted configs should be marked sensitive")
+	}
+
+	err = c.parseBytes("decrypt", []byte("[db]\npassword = \"rot13:42\"\n"))
+	if err == nil || err.Error() != "The value for db.password is invalid: can't decrypt: digits can't be decrypted" {
+		t.Error("Expected a decryption error, got", err)
+	}
+	err = c.parseBytes("decrypt", []byte("[api]\nkeys = [\"rot13:42\"]\n"))
+	if err == nil || err.Error() != "api.keys[0] can't decrypt: digits can't be decrypted at decrypt:2" {
+		t.Error("Expected a decryption error, got", err)
+	}
+}
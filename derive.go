@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"sort"
+)
+
+// -- derived Value
+
+type derivedValue struct {
+	p   *string
+	fn  func(c *ConfigSet) string
+	seq int
+}
+
+func (d *derivedValue) Set(s string) error {
+	return errors.New("it is derived from other settings and can't be set")
+}
+
+func (d *derivedValue) setTOML(value interface{}) error {
+	return d.Set("")
+}
+
+func (d *derivedValue) snapshot() func() error {
+	v := *d.p
+	return func() error {
+		*d.p = v
+		return nil
+	}
+}
+
+func (d *derivedValue) Get() interface{} { return *d.p }
+
+func (d *derivedValue) String() string {
+	if d == nil || d.p == nil {
+		return ""
+	}
+	return *d.p
+}
+
+// Derive defines a string config variable whose value is computed by fn from
+// other settings, such as a database DSN built from its parts:
+//
+//	dsn := c.Derive("db.dsn", func(c *config.ConfigSet) string {
+//		return fmt.Sprintf("postgres://%s@%s/%s", *user, *host, *name)
+//	})
+//
+// The value is recomputed after every load and reload, and after every
+// change made by Set or a transaction, so it stays in sync with its parts.
+// Derived settings are computed in the order they were defined, so fn may
+// read settings derived before it. Sources can't set a derived setting.
+func (c *ConfigSet) Derive(name string, fn func(c *ConfigSet) string) *string {
+	d := &derivedValue{p: new(string), fn: fn, seq: len(c.derived)}
+	c.Var(d, name, "")
+	c.derived[c.prefix+name] = d
+	return d.p
+}
+
+// derive recomputes the derived settings.
+func (c *ConfigSet) derive() {
+	if len(c.derived) == 0 {
+		return
+	}
+	names := make([]string, 0, len(c.derived))
+	for name := range c.derived {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.derived[names[i]].seq < c.derived[names[j]].seq
+	})
+	for _, name := range names {
+		d := c.derived[name]
+		*d.p = d.fn(c)
+		c.applied[name] = "derived"
+	}
+}
+
+// Derive defines a string config variable in the global config whose value
+// is computed by fn from other settings.
+func Derive(name string, fn func(c *ConfigSet) string) *string {
+	return global().Derive(name, fn)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDerive(t *testing.T) {
+	c := NewConfigSet("derive", ContinueOnError)
+	host := c.String("db.host", "localhost")
+	name := c.String("db.name", "app")
+	dsn := c.Derive("db.dsn", func(c *ConfigSet) string {
+		return fmt.Sprintf("postgres://%s/%s", *host, *name)
+	})
+	url := c.Derive("db.url", func(c *ConfigSet) string {
+		return *dsn + "?sslmode=require"
+	})
+
+	if err := c.ParseLayers(Layers{Baseline: []byte("[db]\nhost = \"db1\"\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if *dsn != "postgres://db1/app" || *url != "postgres://db1/app?sslmode=require" {
+		t.Error("Unexpected derived settings:", *dsn, *url)
+	}
+	if c.Origin("db.dsn") != "derived" || c.Get("db.dsn") != "postgres://db1/app" {
+		t.Error("Unexpected origin or value:", c.Origin("db.dsn"), c.Get("db.dsn"))
+	}
+
+	if err := c.Set("db.name", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if *url != "postgres://db1/other?sslmode=require" {
+		t.Error("Derived settings should follow Set, is", *url)
+	}
+
+	err := c.ParseLayers(Layers{Baseline: []byte("[db]\ndsn = \"x\"\n")})
+	if err == nil || err.Error() != "baseline config: The value for db.dsn is invalid: it is derived from other settings and can't be set" {
+		t.Error("Unexpected error:", err)
+	}
+}
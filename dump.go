@@ -0,0 +1,113 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+)
+
+// redacted replaces the values of sensitive configs in dumps.
+const redacted = "[redacted]"
+
+// Sensitive marks the named config variable as holding a secret, such as a
+// password or API key, so that its value is redacted by Dump. It panics if
+// the config hasn't been defined.
+func (c *ConfigSet) Sensitive(name string) {
+	if c.Lookup(c.prefix+name) == nil {
+		panic(fmt.Sprintf("config: can't mark undefined config %s as sensitive", c.prefix+name))
+	}
+	c.secrets[c.prefix+name] = true
+}
+
+// Origin returns where the named config variable's value came from in the
+// most recent load: the file and line, such as "app.conf:9", "env" for an
+// environment variable, "flag" for a command-line flag, "set" for a value
+// changed since by Set or a transaction, or "default" if no source set it.
+func (c *ConfigSet) Origin(name string) string {
+	if origin := c.applied[name]; origin != "" {
+		return origin
+	}
+	return "default"
+}
+
+// Dump writes the effective value of every config variable to w, one per
+// line in TOML syntax and sorted by name, with a comment giving its origin:
+//
+//	db.password = "[redacted]" # app.conf:12
+//	server.port = 8080 # default
+//
+// The values of configs marked Sensitive are redacted.
+func (c *ConfigSet) Dump(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	c.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		var value string
+		switch {
+		case c.secrets[f.Name]:
+			value = strconv.Quote(redacted)
+		case isBareValue(f.Value):
+			value = f.Value.String()
+		default:
+			value = strconv.Quote(f.Value.String())
+		}
+		_, err = fmt.Fprintf(w, "%s = %s # %s\n", f.Name, value, c.Origin(f.Name))
+	})
+	return err
+}
+
+// isBareValue reports whether a config's value is written in TOML without
+// quotes.
+func isBareValue(value flag.Value) bool {
+	getter, ok := value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	switch getter.Get().(type) {
+	case bool, int, int64, uint, uint64, float64:
+		return true
+	}
+	return false
+}
+
+// DumpOnSignal starts a goroutine that calls Dump with w every time the
+// process receives sig, usually syscall.SIGUSR1, so operators can see the
+// configuration a long-running process is using without restarting it.
+// Errors writing to w are ignored.
+func (c *ConfigSet) DumpOnSignal(sig os.Signal, w io.Writer) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig)
+	go func() {
+		for range signals {
+			c.Dump(w)
+		}
+	}()
+}
+
+// Sensitive marks the named config variable as holding a secret that Dump
+// must redact.
+func Sensitive(name string) {
+	global().Sensitive(name)
+}
+
+// Origin returns where the named config variable's value came from in the
+// most recent load.
+func Origin(name string) string {
+	return global().Origin(name)
+}
+
+// Dump writes the effective value and origin of every config variable to w.
+func Dump(w io.Writer) error {
+	return global().Dump(w)
+}
+
+// DumpOnSignal calls Dump with w every time the process receives sig.
+func DumpOnSignal(sig os.Signal, w io.Writer) {
+	global().DumpOnSignal(sig, w)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	c := NewConfigSet("dump", ContinueOnError)
+	c.String("db.password", "")
+	c.String("db.host", "localhost")
+	c.Int("server.port", 8080)
+	c.Bool("debug", false)
+	c.Sensitive("db.password")
+
+	os.Setenv("DUMP_DEBUG", "true")
+	defer os.Unsetenv("DUMP_DEBUG")
+	err := c.ParseLayers(Layers{
+		Baseline:  []byte("[db]\nhost = \"db.example.com\"\npassword = \"hunter2\"\n"),
+		EnvPrefix: "dump",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if origin := c.Origin("db.host"); origin != "baseline:2" {
+		t.Error("db.host should come from baseline:2, is", origin)
+	}
+
+	var out bytes.Buffer
+	if err := c.Dump(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `db.host = "db.example.com" # baseline:2
+db.password = "[redacted]" # baseline:3
+debug = true # env
+server.port = 8080 # default
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected dump:\n%s", out.String())
+	}
+}
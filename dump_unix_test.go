@@ -0,0 +1,29 @@
+//go:build unix
+
+package config
+
+import (
+	"bufio"
+	"io"
+	"syscall"
+	"testing"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	c := NewConfigSet("dump", ContinueOnError)
+	c.Int("server.port", 8080)
+
+	r, w := io.Pipe()
+	c.DumpOnSignal(syscall.SIGUSR1, w)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "server.port = 8080 # default\n" {
+		t.Error("Unexpected dump:", line)
+	}
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// DuplicateKeys is the policy for keys and tables that are defined more than
+// once in the same document, which usually means a hand-merged file has two
+// copies of a section and a change to the first one does nothing.
+type DuplicateKeys int
+
+const (
+	// DuplicateKeysError fails the load with an error giving the positions of
+	// both definitions. It's the default.
+	DuplicateKeysError DuplicateKeys = iota
+	// DuplicateKeysWarn reports both positions with the ConfigSet's logger
+	// and uses the later definition, merging duplicated tables.
+	DuplicateKeysWarn
+)
+
+// SetDuplicateKeys sets the policy for keys and tables defined more than once
+// in the same document.
+func (c *ConfigSet) SetDuplicateKeys(policy DuplicateKeys) {
+	c.duplicateKeys = policy
+}
+
+// definition is a key or table header found in a document.
+type definition struct {
+	name  string
+	table bool
+	// line and end are the first and last lines of the definition, counting
+	// from 1. They differ for values that span several lines.
+	line, end int
+}
+
+// duplicate is a key or table defined a second time.
+type duplicate struct {
+	first, second definition
+}
+
+func (d duplicate) describe(name string) string {
+	what := "the key " + d.first.name
+	if d.first.table {
+		what = "the table [" + d.first.name + "]"
+	}
+	return fmt.Sprintf("%s:%d: %s is already defined at line %d", name, d.second.line, what, d.first.line)
+}
+
+// decodeDuplicates decodes a document with duplicate definitions, keeping the
+// later ones. Earlier duplicate keys are blanked out, and the document is
+// split at duplicate table headers into parts that are decoded separately and
+// merged in order. Each part is padded with empty lines so that positions
+// still refer to the whole document.
+func (c *ConfigSet) decodeDuplicates(name string, configBytes []byte, duplicates []duplicate) (*toml.Tree, error) {
+	lines := strings.SplitAfter(string(configBytes), "\n")
+	var splits []int
+	for _, d := range duplicates {
+		c.warnf("%s; using the later definition", d.describe(name))
+		if d.second.table {
+			splits = append(splits, d.second.line-1)
+			continue
+		}
+		for i := d.first.line - 1; i < d.first.end; i++ {
+			lines[i] = strings.Repeat("\n", strings.Count(lines[i], "\n"))
+		}
+	}
+	sort.Ints(splits)
+
+	var tree *toml.Tree
+	start := 0
+	for _, end := range append(splits, len(lines)) {
+		if end <= start {
+			continue
+		}
+		part, err := loadTOML([]byte(strings.Repeat("\n", start) + strings.Join(lines[start:end], "")))
+		if err != nil {
+			return nil, err
+		}
+		if tree == nil {
+			tree = part
+		} else {
+			mergeDocument(tree, part, nil)
+		}
+		start = end
+	}
+	return tree, nil
+}
+
+// mergeDocument merges a later part of a document into the earlier parts. It
+// differs from mergeTree in appending to arrays of tables, since each part
+// defines separate elements.
+func mergeDocument(dst, src *toml.Tree, path []string) {
+	for _, key := range src.Keys() {
+		fullPath := append(path[:len(path):len(path)], key)
+		value := src.GetPath([]string{key})
+		switch v := value.(type) {
+		case *toml.Tree:
+			if _, ok := dst.GetPath(fullPath).(*toml.Tree); ok {
+				mergeDocument(dst, v, fullPath)
+				continue
+			}
+		case []*toml.Tree:
+			if elems, ok := dst.GetPath(fullPath).([]*toml.Tree); ok {
+				value = append(elems[:len(elems):len(elems)], v...)
+			}
+		}
+		dst.SetPath(fullPath, value)
+		dst.SetPositionPath(fullPath, src.GetPositionPath([]string{key}))
+	}
+}
+
+// findDuplicates scans a TOML document line by line for keys and tables that
+// are defined more than once. It's only used on documents that go-toml has
+// rejected, and it ignores lines it can't make sense of, since those are
+// reported by go-toml.
+func findDuplicates(configBytes []byte) []duplicate {
+	lines := strings.Split(string(configBytes), "\n")
+	defined := make(map[string]definition)
+	arrays := make(map[string]int)
+	var table []string
+	var duplicates []duplicate
+	define := func(d definition) {
+		if first, ok := defined[d.name]; ok {
+			duplicates = append(duplicates, duplicate{first, d})
+			return
+		}
+		defined[d.name] = d
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "" || line[0] == '#':
+		case line[0] == '[':
+			keys, array, ok := parseHeader(line)
+			if !ok {
+				continue
+			}
+			table = resolveTable(keys, array, arrays)
+			if !array {
+				define(definition{name: strings.Join(table, "."), table: true, line: i + 1, end: i + 1})
+			}
+		default:
+			key, value, ok := splitKeyValue(line)
+			if !ok {
+				continue
+			}
+			keys, err := splitKey(compactKey(key))
+			if err != nil {
+				continue
+			}
+			start := i
+			var s valueScanner
+			for !s.scan(value) && i+1 < len(lines) {
+				i++
+				value = lines[i]
+			}
+			name := table[:len(table):len(table)]
+			for _, key := range keys {
+				name = append(name, quoteKey(key))
+			}
+			define(definition{name: strings.Join(name, "."), line: start + 1, end: i + 1})
+		}
+	}
+	return duplicates
+}
+
+// parseHeader returns the keys of a [table] or [[array]] header.
+func parseHeader(line string) (keys []string, array bool, ok bool) {
+	array = strings.HasPrefix(line, "[[")
+	open, close := "[", "]"
+	if array {
+		open, close = "[[", "]]"
+	}
+	end := indexUnquoted(line, close)
+	if end < len(open) {
+		return nil, false, false
+	}
+	keys, err := splitKey(compactKey(line[len(open):end]))
+	return keys, array, err == nil
+}
+
+// resolveTable returns the path of the table named by a header, with each
+// array of tables followed by the index of its current element, such as
+// "servers[1]". Starting a new element of an array increments its index.
+func resolveTable(keys []string, array bool, arrays map[string]int) []string {
+	path := make([]string, len(keys))
+	for i, key := range keys {
+		path[i] = quoteKey(key)
+		prefix := joinKeys(keys[:i+1])
+		if array && i == len(keys)-1 {
+			arrays[prefix]++
+		}
+		if n, ok := arrays[prefix]; ok {
+			path[i] += fmt.Sprintf("[%d]", n-1)
+		}
+	}
+	return path
+}
+
+// splitKeyValue splits a line at the first equals sign outside a quoted key.
+func splitKeyValue(line string) (key, value string, ok bool) {
+	i := indexUnquoted(line, "=")
+	if i < 0 {
+		return "", "", false
+	}
+	return line[:i], line[i+1:], true
+}
+
+// indexUnquoted returns the index of the first instance of sep in s that
+// isn't inside a quoted string, or -1.
+func indexUnquoted(s, sep string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
+
+// compactKey removes the whitespace TOML allows around the dots of a dotted
+// key, leaving quoted keys alone.
+func compactKey(key string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(key); i++ {
+		switch {
+		case quote == '"' && key[i] == '\\' && i+1 < len(key):
+			b.WriteByte(key[i])
+			i++
+		case quote != 0:
+			if key[i] == quote {
+				quote = 0
+			}
+		case key[i] == '"' || key[i] == '\'':
+			quote = key[i]
+		case key[i] == ' ' || key[i] == '\t':
+			continue
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
+
+// valueScanner follows the strings, arrays, and inline tables of a TOML value
+// across lines, to find the line it ends on.
+type valueScanner struct {
+	depth int
+	// quote is the delimiter of the multi-line string being scanned, if any.
+	quote string
+}
+
+// scan consumes a line of the value and reports whether the value ends on it.
+func (s *valueScanner) scan(line string) bool {
+	for i := 0; i < len(line); i++ {
+		if s.quote != "" {
+			if s.quote[0] == '"' && line[i] == '\\' {
+				i++
+			} else if strings.HasPrefix(line[i:], s.quote) {
+				i += len(s.quote) - 1
+				s.quote = ""
+			}
+			continue
+		}
+		switch line[i] {
+		case '"', '\'':
+			s.quote = line[i : i+1]
+			if strings.HasPrefix(line[i:], strings.Repeat(s.quote, 3)) {
+				s.quote = strings.Repeat(s.quote, 3)
+			}
+			i += len(s.quote) - 1
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			s.depth--
+		case '#':
+			return s.depth <= 0
+		}
+	}
+	if len(s.quote) == 1 {
+		s.quote = ""
+	}
+	return s.quote == "" && s.depth <= 0
+}
+
+// SetDuplicateKeys sets the policy for keys and tables defined more than once
+// in the same document.
+func SetDuplicateKeys(policy DuplicateKeys) {
+	global().SetDuplicateKeys(policy)
+}
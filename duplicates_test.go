@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+const duplicatedDocument = `name = "first"
+[db]
+host = "old"
+port = 5432
+tags = [
+  "a",
+]
+
+[server]
+addr = ":80"
+
+[db]
+host = "new"
+tags = ["b"]
+name = "second"
+`
+
+func TestDuplicateKeys(t *testing.T) {
+	c := NewConfigSet("duplicates", ContinueOnError)
+	name := c.String("name", "")
+	host := c.String("db.host", "")
+	port := c.Int("db.port", 0)
+	tags := c.StringSet("db.tags", nil)
+	addr := c.String("server.addr", "")
+	c.String("db.name", "")
+
+	err := c.parseBytes("app.conf", []byte(duplicatedDocument))
+	if err == nil || err.Error() != "app.conf:12: the table [db] is already defined at line 2" {
+		t.Fatal("Unexpected error:", err)
+	}
+	err = c.parseBytes("app.conf", []byte("a = 1\n\"a\" = 2\n"))
+	if err == nil || err.Error() != "app.conf:2: the key a is already defined at line 1" {
+		t.Error("Unexpected error:", err)
+	}
+	err = c.parseBytes("app.conf", []byte("a = [\n"))
+	if err == nil || err.Error() != "app.conf is not a valid TOML file. See https://github.com/mojombo/toml" {
+		t.Error("Unexpected error:", err)
+	}
+
+	var out bytes.Buffer
+	c.SetLogger(log.New(&out, "", 0))
+	c.SetDuplicateKeys(DuplicateKeysWarn)
+	if err := c.parseBytes("app.conf", []byte(duplicatedDocument)); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "first" || *host != "new" || *port != 5432 || len(*tags) != 1 || (*tags)[0] != "b" || *addr != ":80" {
+		t.Errorf("Unexpected settings: %q %q %d %s %q", *name, *host, *port, tags, *addr)
+	}
+	expected := `config: app.conf:12: the table [db] is already defined at line 2; using the later definition
+config: app.conf:13: the key db.host is already defined at line 3; using the later definition
+config: app.conf:14: the key db.tags is already defined at line 5; using the later definition
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected warnings:\n%s", out.String())
+	}
+
+	tree := c.trees[len(c.trees)-1]
+	if pos := tree.GetPosition("db.host"); pos.Line != 13 {
+		t.Error("Unexpected position:", pos)
+	}
+
+	doc := "[[replicas]]\nhost = \"r1\"\n[t]\n[[replicas]]\nhost = \"r2\"\n[t]\n[[replicas]]\nhost = \"r3\"\n"
+	tree, err = c.decodeTOML("replicas.conf", []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas, _ := tree.Get("replicas").([]*toml.Tree); len(replicas) != 3 || replicas[2].Get("host") != "r3" {
+		t.Error("Unexpected replicas:", tree.Get("replicas"))
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	doc := `s = """
+a = 1
+"""
+a = 2 # a = 3
+[[x]]
+b = 1
+[[x]]
+b = 2
+[x.y]
+[t]
+c.d = 1
+c . d = 2
+`
+	duplicates := findDuplicates([]byte(doc))
+	if len(duplicates) != 1 || duplicates[0].describe("t") != "t:12: the key t.c.d is already defined at line 11" {
+		t.Errorf("Unexpected duplicates: %v", duplicates)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -- enum Value
+
+type enumValue struct {
+	p       *int
+	mapping map[string]int
+}
+
+func (e *enumValue) Set(s string) error {
+	v, ok := e.mapping[s]
+	if !ok {
+		return fmt.Errorf("%q is not one of %s", s, strings.Join(e.names(), ", "))
+	}
+	*e.p = v
+	return nil
+}
+
+func (e *enumValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return e.Set(s)
+	}
+	return errParse
+}
+
+func (e *enumValue) Get() interface{} { return *e.p }
+
+func (e *enumValue) snapshot() func() error {
+	v := *e.p
+	return func() error {
+		*e.p = v
+		return nil
+	}
+}
+
+// String returns the name mapped to the current value. If several names map
+// to it, the first in sorted order is used.
+func (e *enumValue) String() string {
+	if e.p == nil {
+		return ""
+	}
+	for _, name := range e.names() {
+		if e.mapping[name] == *e.p {
+			return name
+		}
+	}
+	return ""
+}
+
+func (e *enumValue) names() []string {
+	names := make([]string, 0, len(e.mapping))
+	for name := range e.mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnumVar defines an enumerated config with a given name and default value for
+// a ConfigSet. The config is given by name in the config file, such as
+// compression = "zstd", and mapping gives the int stored in p for each
+// accepted name. It panics if the default isn't in mapping.
+func (c *ConfigSet) EnumVar(p *int, name string, value string, mapping map[string]int) {
+	e := &enumValue{p, mapping}
+	if err := e.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(e, name, "")
+}
+
+// Enum defines an enumerated config variable with a given name, default value,
+// and mapping from names to values for a ConfigSet.
+func (c *ConfigSet) Enum(name string, value string, mapping map[string]int) *int {
+	p := new(int)
+	c.EnumVar(p, name, value, mapping)
+	return p
+}
+
+// EnumVar defines an enumerated config with a given name and default value.
+// The argument p points to an int variable in which to store the value mapped
+// to the configured name.
+func EnumVar(p *int, name string, value string, mapping map[string]int) {
+	global().EnumVar(p, name, value, mapping)
+}
+
+// Enum defines an enumerated config variable with a given name, default value,
+// and mapping from names to values.
+func Enum(name string, value string, mapping map[string]int) *int {
+	return global().Enum(name, value, mapping)
+}
+
+// -- choice Value
+
+type choiceValue struct {
+	p       *string
+	allowed []string
+}
+
+func (ch *choiceValue) Set(s string) error {
+	for _, allowed := range ch.allowed {
+		if s == allowed {
+			*ch.p = s
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", s, strings.Join(ch.allowed, ", "))
+}
+
+func (ch *choiceValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return ch.Set(s)
+	}
+	return errParse
+}
+
+func (ch *choiceValue) Get() interface{} { return *ch.p }
+
+func (ch *choiceValue) snapshot() func() error {
+	v := *ch.p
+	return func() error {
+		*ch.p = v
+		return nil
+	}
+}
+
+func (ch *choiceValue) String() string {
+	if ch.p == nil {
+		return ""
+	}
+	return *ch.p
+}
+
+// ChoiceVar defines a string config with a given name and default value for a
+// ConfigSet whose value must be one of the allowed strings. The argument p
+// points to a string variable in which to store the value of the config.
+// Other values fail the load with an error listing the allowed ones. It
+// panics if the default isn't allowed.
+func (c *ConfigSet) ChoiceVar(p *string, name string, value string, allowed ...string) {
+	ch := &choiceValue{p, allowed}
+	if err := ch.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(ch, name, "")
+}
+
+// Choice defines a string config variable with a given name and default value
+// for a ConfigSet whose value must be one of the allowed strings, such as
+//
+//	mode := c.Choice("mode", "dev", "dev", "staging", "prod")
+//
+// Unlike Enum, it stores the string itself.
+func (c *ConfigSet) Choice(name string, value string, allowed ...string) *string {
+	p := new(string)
+	c.ChoiceVar(p, name, value, allowed...)
+	return p
+}
+
+// ChoiceVar defines a string config with a given name and default value whose
+// value must be one of the allowed strings. The argument p points to a string
+// variable in which to store the value of the config.
+func ChoiceVar(p *string, name string, value string, allowed ...string) {
+	global().ChoiceVar(p, name, value, allowed...)
+}
+
+// Choice defines a string config variable with a given name and default value
+// whose value must be one of the allowed strings.
+func Choice(name string, value string, allowed ...string) *string {
+	return global().Choice(name, value, allowed...)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+)
+
+const (
+	compressNone = iota
+	compressGzip
+	compressZstd
+)
+
+var compressions = map[string]int{"none": compressNone, "gzip": compressGzip, "zstd": compressZstd}
+
+func TestEnum(t *testing.T) {
+	c := NewConfigSet("enum", ContinueOnError)
+	var compression int
+	c.EnumVar(&compression, "compression", "none", compressions)
+
+	if compression != compressNone {
+		t.Error("compression should default to none, is", compression)
+	}
+
+	err := c.parseBytes("enum", []byte("compression = \"zstd\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compression != compressZstd {
+		t.Error("compression should be zstd, is", compression)
+	}
+	if s := c.Lookup("compression").Value.String(); s != "zstd" {
+		t.Error("compression should print as zstd, is", s)
+	}
+
+	err = c.parseBytes("enum", []byte("compression = \"lz4\"\n"))
+	if err == nil || err.Error() != `The value for compression is invalid: "lz4" is not one of gzip, none, zstd` {
+		t.Error("Expected an invalid enum error, got", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid default to panic")
+		}
+	}()
+	c.Enum("fallback", "brotli", compressions)
+}
+
+func TestChoice(t *testing.T) {
+	c := NewConfigSet("enum", ContinueOnError)
+	mode := c.Choice("mode", "dev", "dev", "staging", "prod")
+
+	if *mode != "dev" {
+		t.Fatal("mode should default to dev, is", *mode)
+	}
+	if err := c.parseBytes("enum", []byte(`mode = "prod"`)); err != nil {
+		t.Fatal(err)
+	}
+	if *mode != "prod" {
+		t.Error("mode should be prod, is", *mode)
+	}
+
+	err := c.parseBytes("enum", []byte(`mode = "production"`))
+	if err == nil || err.Error() != `The value for mode is invalid: "production" is not one of dev, staging, prod` {
+		t.Error("Expected an invalid choice error, got", err)
+	}
+
+	defer func() {
+		if r := recover(); r != `config: invalid default for level: "loud" is not one of quiet, normal` {
+			t.Error("Expected an invalid default panic, got", r)
+		}
+	}()
+	c.Choice("level", "loud", "quiet", "normal")
+}
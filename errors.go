@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// ErrorKind identifies a kind of error whose message can be changed with
+// SetErrorTemplate.
+type ErrorKind int
+
+const (
+	// UnknownKey is returned when a document sets a config that isn't
+	// defined, such as "colour is not a valid config setting".
+	UnknownKey ErrorKind = iota
+	// InvalidValue is returned when a config can't hold the value it's
+	// given, such as "The value for port is invalid".
+	InvalidValue
+	// MissingFile is returned when a config file passed to Parse or
+	// ParseFiles doesn't exist.
+	MissingFile
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case UnknownKey:
+		return "UnknownKey"
+	case InvalidValue:
+		return "InvalidValue"
+	case MissingFile:
+		return "MissingFile"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// ErrorData is the data passed to the templates given to SetErrorTemplate.
+type ErrorData struct {
+	// Name is the config the error is about, for UnknownKey and InvalidValue.
+	Name string
+	// Reason says why a value is invalid, such as "value out of range" or
+	// "element 3 is not a valid integer". It may be empty.
+	Reason string
+	// Pos is where the value came from, such as "app.conf:9", if known.
+	Pos string
+	// Path is the file that doesn't exist, for MissingFile.
+	Path string
+	// Err is the error that would have been returned without a template.
+	Err error
+}
+
+// SetErrorTemplate replaces the message of errors of the given kind with the
+// output of a text/template executed with an ErrorData, so that products can
+// localize or brand their errors:
+//
+//	c.SetErrorTemplate(config.UnknownKey, "{{.Pos}}: unbekannte Einstellung {{.Name}}")
+//
+// The errors returned still unwrap to the original error. It panics if tmpl
+// can't be parsed.
+func (c *ConfigSet) SetErrorTemplate(kind ErrorKind, tmpl string) {
+	t, err := template.New(kind.String()).Parse(tmpl)
+	if err != nil {
+		panic(fmt.Sprintf("config: invalid error template for %s: %s", kind, err))
+	}
+	c.errorTemplates[kind] = t
+}
+
+// templateError is an error whose message comes from an error template.
+type templateError struct {
+	msg string
+	err error
+}
+
+func (e *templateError) Error() string { return e.msg }
+
+func (e *templateError) Unwrap() error { return e.err }
+
+// formatError returns err with the message from the template for kind, if
+// one has been set and it executes successfully, and err otherwise.
+func (c *ConfigSet) formatError(kind ErrorKind, data ErrorData, err error) error {
+	t := c.errorTemplates[kind]
+	if t == nil {
+		return err
+	}
+	data.Err = err
+	var buf bytes.Buffer
+	if t.Execute(&buf, data) != nil {
+		return err
+	}
+	return &templateError{buf.String(), err}
+}
+
+// valueError builds the error returned when the named config can't hold a
+// value from pos.
+func (c *ConfigSet) valueError(name string, err error, pos string) error {
+	if _, ok := err.(loadError); ok {
+		return invalidValueError(name, err)
+	}
+	data := ErrorData{Name: name, Pos: pos}
+	if err != errParse {
+		data.Reason = err.Error()
+	}
+	if ee, ok := err.(elementError); ok {
+		return c.formatError(InvalidValue, data, elementLoadError(name, ee, pos))
+	}
+	return c.formatError(InvalidValue, data, invalidValueError(name, err))
+}
+
+// fileError builds the error returned when the config file at path can't be
+// read.
+func (c *ConfigSet) fileError(path string, err error) error {
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return c.formatError(MissingFile, ErrorData{Path: path}, err)
+}
+
+// SetErrorTemplate replaces the message of errors of the given kind from the
+// global config.
+func SetErrorTemplate(kind ErrorKind, tmpl string) {
+	global().SetErrorTemplate(kind, tmpl)
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSetErrorTemplate(t *testing.T) {
+	c := NewConfigSet("errors", ContinueOnError)
+	c.Int("port", 0)
+	c.StringSet("names", nil)
+	c.Flag("beta")
+
+	err := c.parseBytes("app.conf", []byte("colour = 1\n"))
+	if err == nil || err.Error() != "colour is not a valid config setting" {
+		t.Error("Unexpected default error:", err)
+	}
+
+	c.SetErrorTemplate(UnknownKey, "{{.Pos}}: unbekannte Einstellung {{.Name}}")
+	c.SetErrorTemplate(InvalidValue, "{{.Pos}}: ungültiger Wert für {{.Name}}{{with .Reason}} ({{.}}){{end}}")
+	c.SetErrorTemplate(MissingFile, "Datei {{.Path}} fehlt")
+
+	for _, test := range []struct {
+		doc, err string
+	}{
+		{"colour = 1\n", "app.conf:1: unbekannte Einstellung colour"},
+		{"\nport = \"x\"\n", "app.conf:2: ungültiger Wert für port"},
+		{"beta = 150\n", "app.conf:1: ungültiger Wert für beta (value out of range)"},
+		{"names = [\"a\", 1]\n", "app.conf:1: ungültiger Wert für names (element 1 is not a string)"},
+	} {
+		err := c.parseBytes("app.conf", []byte(test.doc))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Expected %q, got %v", test.err, err)
+		}
+	}
+
+	err = c.Parse("/nonexistent/app.conf")
+	if err == nil || err.Error() != "Datei /nonexistent/app.conf fehlt" {
+		t.Error("Unexpected error:", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("The error should still unwrap to the original error")
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// ExecOption changes how Exec resolves the configuration and runs the child
+// process.
+type ExecOption func(*execOptions)
+
+type execOptions struct {
+	envPrefix string
+	layers    *Layers
+}
+
+// WithEnvPrefix sets the prefix of the environment variables Exec passes to
+// the child process. It defaults to the prefix given to SetEnvPrefix.
+func WithEnvPrefix(prefix string) ExecOption {
+	return func(o *execOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithLayers makes Exec load the configuration with ParseLayers before
+// running the child process, instead of using the settings already loaded.
+func WithLayers(layers Layers) ExecOption {
+	return func(o *execOptions) {
+		o.layers = &layers
+	}
+}
+
+// Exec runs the command argv with the effective value of every config
+// variable in its environment, as returned by Environ, in addition to the
+// current process's environment, and waits for it to exit. The child shares
+// the current process's standard input, output, and error, and is killed if
+// ctx is done before it exits. This lets programs that only understand the
+// environment be configured through the same layered sources:
+//
+//	err := c.Exec(ctx, os.Args[1:], config.WithEnvPrefix("MYAPP"),
+//		config.WithLayers(config.Layers{System: "/etc/myapp.conf"}))
+//
+// If the child fails, the error is an *exec.ExitError.
+func (c *ConfigSet) Exec(ctx context.Context, argv []string, opts ...ExecOption) error {
+	o := execOptions{envPrefix: c.envPrefix}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(argv) == 0 {
+		return errors.New("config: Exec needs a command to run")
+	}
+	if o.envPrefix == "" {
+		return errors.New("config: Exec needs an environment variable prefix")
+	}
+	if o.layers != nil {
+		if err := c.ParseLayers(*o.layers); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), c.Environ(o.envPrefix)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// Exec runs the command argv with the effective value of every config
+// variable in the global config in its environment.
+func Exec(ctx context.Context, argv []string, opts ...ExecOption) error {
+	return global().Exec(ctx, argv, opts...)
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package config
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	c := NewConfigSet("exec", ContinueOnError)
+	c.Int("server.port", 8080)
+	c.String("server.host", "localhost")
+	ctx := context.Background()
+
+	err := c.Exec(ctx, []string{"sh", "-c", `test "$MYAPP_SERVER_PORT" = 9000 && test "$MYAPP_SERVER_HOST" = localhost`},
+		WithEnvPrefix("myapp"),
+		WithLayers(Layers{Baseline: []byte("[server]\nport = 9000\n")}))
+	if err != nil {
+		t.Error("The child should see the settings:", err)
+	}
+
+	err = c.Exec(ctx, []string{"sh", "-c", "exit 3"}, WithEnvPrefix("myapp"))
+	if ee, ok := err.(*exec.ExitError); !ok || ee.ExitCode() != 3 {
+		t.Error("Expected an *exec.ExitError, got", err)
+	}
+
+	if err := c.Exec(ctx, []string{"true"}); err == nil || err.Error() != "config: Exec needs an environment variable prefix" {
+		t.Error("Unexpected error:", err)
+	}
+}
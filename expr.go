@@ -0,0 +1,257 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+)
+
+// exprVars are the variables available in expressions.
+var exprVars = map[string]func() float64{
+	"numcpu":     func() float64 { return float64(runtime.NumCPU()) },
+	"gomaxprocs": func() float64 { return float64(runtime.GOMAXPROCS(0)) },
+}
+
+// exprFuncs are the functions available in expressions.
+var exprFuncs = map[string]func(args []float64) (float64, error){
+	"min": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("min needs at least one argument")
+		}
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Min(v, arg)
+		}
+		return v, nil
+	},
+	"max": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("max needs at least one argument")
+		}
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Max(v, arg)
+		}
+		return v, nil
+	},
+}
+
+// EnableExpressions allows numeric config variables to be set with simple
+// arithmetic expressions in strings, so that settings can be sized relative
+// to the machine:
+//
+//	cache_bytes = "256 * 1024 * 1024"
+//	workers = "max(numcpu * 2, 4)"
+//
+// Expressions may use numbers, the operators + - * / % and parentheses, the
+// variables numcpu and gomaxprocs, and the functions min and max. Settings
+// that are integers must evaluate to whole numbers, and sized settings such
+// as Int8 reject results out of their range. Strings that aren't
+// expressions, such as "0x1F", are parsed as before.
+func (c *ConfigSet) EnableExpressions() {
+	c.expressions = true
+}
+
+// evalNumeric evaluates value if it's a string holding an expression and the
+// config is numeric, returning the result as an int64 or float64 as the
+// config requires. Other values are returned unchanged.
+func evalNumeric(v flag.Value, value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	getter, ok := v.(flag.Getter)
+	if !ok {
+		return value, nil
+	}
+	var integer bool
+	switch getter.Get().(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		integer = true
+	case float32, float64:
+	default:
+		return value, nil
+	}
+
+	p := &exprParser{s: s}
+	result, err := p.parse()
+	if err == errNotExpr {
+		return value, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !integer {
+		return result, nil
+	}
+	if result != math.Trunc(result) || math.Abs(result) > 1<<63 {
+		return nil, fmt.Errorf("%q is %v, which is not an integer", s, result)
+	}
+	return int64(result), nil
+}
+
+// errNotExpr is returned by exprParser.parse if the string isn't an
+// expression at all.
+var errNotExpr = errors.New("not an expression")
+
+// exprParser evaluates an arithmetic expression by recursive descent.
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) parse() (float64, error) {
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return 0, errNotExpr
+	}
+	return v, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end.
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// expr parses terms separated by + and -.
+func (p *exprParser) expr() (float64, error) {
+	v, err := p.term()
+	for err == nil {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+		var rhs float64
+		if rhs, err = p.term(); op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+	return v, err
+}
+
+// term parses factors separated by *, /, and %.
+func (p *exprParser) term() (float64, error) {
+	v, err := p.factor()
+	for err == nil {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			break
+		}
+		p.pos++
+		var rhs float64
+		if rhs, err = p.factor(); err != nil {
+			break
+		}
+		if rhs == 0 && op != '*' {
+			return 0, fmt.Errorf("%q divides by zero", p.s)
+		}
+		switch op {
+		case '*':
+			v *= rhs
+		case '/':
+			v /= rhs
+		case '%':
+			v = math.Mod(v, rhs)
+		}
+	}
+	return v, err
+}
+
+// factor parses a number, variable, function call, parenthesized
+// expression, or negation.
+func (p *exprParser) factor() (float64, error) {
+	switch c := p.peek(); {
+	case c == '-':
+		p.pos++
+		v, err := p.factor()
+		return -v, err
+	case c == '(':
+		p.pos++
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, errNotExpr
+		}
+		p.pos++
+		return v, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.' || p.s[p.pos] == '_') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return 0, errNotExpr
+		}
+		return v, nil
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z') {
+			p.pos++
+		}
+		name := p.s[start:p.pos]
+		if p.peek() == '(' {
+			return p.call(name)
+		}
+		if fn, ok := exprVars[name]; ok {
+			return fn(), nil
+		}
+		return 0, errNotExpr
+	}
+	return 0, errNotExpr
+}
+
+// call parses the arguments of a function call and calls it.
+func (p *exprParser) call(name string) (float64, error) {
+	fn, ok := exprFuncs[name]
+	if !ok {
+		return 0, fmt.Errorf("%q calls the unknown function %s", p.s, name)
+	}
+	p.pos++
+	var args []float64
+	for p.peek() != ')' {
+		if len(args) > 0 {
+			if p.peek() != ',' {
+				return 0, errNotExpr
+			}
+			p.pos++
+		}
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	p.pos++
+	v, err := fn(args)
+	if err != nil {
+		return 0, fmt.Errorf("%q: %s", p.s, err)
+	}
+	return v, nil
+}
+
+// EnableExpressions allows numeric config variables in the global config to
+// be set with simple arithmetic expressions.
+func EnableExpressions() {
+	global().EnableExpressions()
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestEnableExpressions(t *testing.T) {
+	c := NewConfigSet("expr", ContinueOnError)
+	cacheBytes := c.Int64("cache_bytes", 0)
+	workers := c.Int("workers", 1)
+	ratio := c.Float64("ratio", 0)
+	mask := c.Int("mask", 0)
+	name := c.String("name", "")
+
+	doc := []byte(`
+cache_bytes = "256 * 1024 * 1024"
+workers = "max(numcpu * 2, 4)"
+ratio = "(1 + 2) / 4"
+mask = "0x1F"
+name = "1 + 1"
+`)
+	if err := c.parseBytes("expr", doc); err == nil {
+		t.Error("Expressions should be rejected unless enabled")
+	}
+
+	c.EnableExpressions()
+	if err := c.parseBytes("expr", doc); err != nil {
+		t.Fatal(err)
+	}
+	expectedWorkers := runtime.NumCPU() * 2
+	if expectedWorkers < 4 {
+		expectedWorkers = 4
+	}
+	if *cacheBytes != 256*1024*1024 || *workers != expectedWorkers || *ratio != 0.75 || *mask != 31 || *name != "1 + 1" {
+		t.Error("Unexpected values:", *cacheBytes, *workers, *ratio, *mask, *name)
+	}
+
+	for doc, msg := range map[string]string{
+		`workers = "7 / 2"`:        `The value for workers is invalid: "7 / 2" is 3.5, which is not an integer`,
+		`workers = "1 % 0"`:        `The value for workers is invalid: "1 % 0" divides by zero`,
+		`workers = "avg(1, 2)"`:    `The value for workers is invalid: "avg(1, 2)" calls the unknown function avg`,
+		`workers = "numcpus * 2"`:  `The value for workers is invalid`,
+		`workers = "-(3 - 5) * 2"`: ``,
+	} {
+		err := c.parseBytes("expr", []byte(doc))
+		if msg == "" && err != nil || msg != "" && (err == nil || err.Error() != msg) {
+			t.Errorf("%s: expected %q, got %v", doc, msg, err)
+		}
+	}
+	if *workers != 4 {
+		t.Error("Expected 4 workers, got", *workers)
+	}
+}
+
+func TestExpressionsSized(t *testing.T) {
+	c := NewConfigSet("expr", ContinueOnError)
+	c.EnableExpressions()
+	small := c.Int8("small", 0)
+	port := c.Uint16("port", 0)
+	scale := c.Float32("scale", 0)
+	buffer := c.ByteSize("buffer", "0")
+
+	doc := `
+small = "60 * 2"
+port = "8000 + 80"
+scale = "3 / 4"
+buffer = "64 * 1024"
+`
+	if err := c.parseBytes("expr", []byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if *small != 120 || *port != 8080 || *scale != 0.75 || *buffer != 64*1024 {
+		t.Error("Unexpected values:", *small, *port, *scale, *buffer)
+	}
+
+	for doc, msg := range map[string]string{
+		`small = "100 + 100"`: `The value for small is invalid: value out of range`,
+		`port = "1 - 2"`:      `The value for port is invalid: value out of range`,
+	} {
+		err := c.parseBytes("expr", []byte(doc))
+		if err == nil || err.Error() != msg {
+			t.Errorf("%s: expected %q, got %v", doc, msg, err)
+		}
+	}
+}
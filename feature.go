@@ -0,0 +1,116 @@
+package config
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Feature is a feature flag that is on, off, or on for a percentage of keys,
+// such as user or account IDs, so that a change can be rolled out gradually.
+// It's set with a bool or a percentage between 0 and 100:
+//
+//	[newpath]
+//	enabled = 25 # or true, false, "25%"
+//
+// Enabled can be called safely while the ConfigSet is being reloaded.
+type Feature struct {
+	name    string
+	percent uint64
+}
+
+// Enabled reports whether the feature is on for key. Whether a key is in a
+// partial rollout depends only on the key and the feature's name, so the same
+// key stays enabled as the percentage increases, and different features are
+// rolled out to different keys.
+func (f *Feature) Enabled(key string) bool {
+	percent := f.Percent()
+	switch {
+	case percent >= 100:
+		return true
+	case percent <= 0:
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(f.name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) < percent*100
+}
+
+// Percent returns the percentage of keys the feature is enabled for.
+func (f *Feature) Percent() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.percent))
+}
+
+func (f *Feature) store(percent float64) error {
+	if percent < 0 || percent > 100 || math.IsNaN(percent) {
+		return errRange
+	}
+	atomic.StoreUint64(&f.percent, math.Float64bits(percent))
+	return nil
+}
+
+// Set accepts "true", "false", or a percentage with an optional "%" suffix.
+// Other spellings of booleans, such as "1" and "t", aren't accepted, so that
+// "1" means 1% rather than 100%.
+func (f *Feature) Set(s string) error {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "true":
+		return f.store(100)
+	case "false":
+		return f.store(0)
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return numError(err)
+	}
+	return f.store(v)
+}
+
+func (f *Feature) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return f.store(100)
+		}
+		return f.store(0)
+	case int64:
+		return f.store(float64(v))
+	case float64:
+		return f.store(v)
+	case string:
+		return f.Set(v)
+	}
+	return errParse
+}
+
+func (f *Feature) Get() interface{} { return f.Percent() }
+
+func (f *Feature) snapshot() func() error {
+	v := f.Percent()
+	return func() error { return f.store(v) }
+}
+
+func (f *Feature) String() string {
+	if f == nil {
+		return "0"
+	}
+	return strconv.FormatFloat(f.Percent(), 'g', -1, 64)
+}
+
+// Flag defines a feature flag with the given name, which is off by default.
+func (c *ConfigSet) Flag(name string) *Feature {
+	f := &Feature{name: c.prefix + name}
+	c.Var(f, name, "")
+	return f
+}
+
+// Flag defines a feature flag with the given name in the global config, which
+// is off by default.
+func Flag(name string) *Feature {
+	return global().Flag(name)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	c := NewConfigSet("feature", ContinueOnError)
+	on := c.Flag("on.enabled")
+	off := c.Flag("off.enabled")
+	half := c.Flag("half.enabled")
+	pct := c.Flag("pct.enabled")
+	unset := c.Flag("unset.enabled")
+
+	err := c.parseBytes("feature", []byte(`
+on.enabled = true
+off.enabled = false
+half.enabled = 50
+pct.enabled = "12.5%"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !on.Enabled("a") || off.Enabled("a") || unset.Enabled("a") {
+		t.Error("Unexpected flags:", on.Enabled("a"), off.Enabled("a"), unset.Enabled("a"))
+	}
+	if pct.Percent() != 12.5 {
+		t.Error("Expected 12.5%, got", pct.Percent())
+	}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprint("user-", i)
+		if half.Enabled(key) != half.Enabled(key) {
+			t.Fatal("Enabled should be stable for", key)
+		}
+		if half.Enabled(key) {
+			enabled++
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Error("Expected about half of the keys to be enabled, got", enabled)
+	}
+
+	// Keys enabled at a lower percentage stay enabled at a higher one.
+	var rolledOut []string
+	for i := 0; i < 1000; i++ {
+		if key := fmt.Sprint("user-", i); pct.Enabled(key) {
+			rolledOut = append(rolledOut, key)
+		}
+	}
+	c.Set("pct.enabled", "60")
+	for _, key := range rolledOut {
+		if !pct.Enabled(key) {
+			t.Fatal(key, "should stay enabled as the rollout grows")
+		}
+	}
+
+	for value, percent := range map[string]float64{"1": 1, "0": 0, "TRUE": 100, "false": 0, "5%": 5} {
+		if err := c.Set("pct.enabled", value); err != nil {
+			t.Fatal(err)
+		}
+		if pct.Percent() != percent {
+			t.Errorf("Set(%q) should enable %v%%, got %v%%", value, percent, pct.Percent())
+		}
+	}
+	if err := c.Set("pct.enabled", "t"); err == nil {
+		t.Error("Expected an error for t")
+	}
+
+	err = c.parseBytes("feature", []byte("half.enabled = 150\n"))
+	if err == nil || err.Error() != "The value for half.enabled is invalid: value out of range" {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// ParseFiles loads several TOML files into the ConfigSet. The files are read
+// and decoded concurrently but applied in the order given, so settings in
+// later files override those in earlier ones. No settings are applied if any
+// file can't be read or decoded.
+func (c *ConfigSet) ParseFiles(paths ...string) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseFiles(paths...) }
+	key := loadKey("files", paths...)
+	if c.unchanged(key) {
+		return nil
+	}
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	c.cache.key = key
+	return c.parseFiles(paths)
+}
+
+func (c *ConfigSet) parseFiles(paths []string) error {
+	type document struct {
+		path  string
+		bytes []byte
+		tree  *toml.Tree
+		err   error
+	}
+	documents := make([]document, len(paths))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		documents[i].path = path
+		go func(doc *document) {
+			defer wg.Done()
+			doc.bytes, doc.err = c.readFile(doc.path)
+			if doc.err != nil {
+				doc.err = c.fileError(doc.path, doc.err)
+			} else {
+				doc.bytes = c.normalizeText(doc.path, doc.bytes)
+				doc.tree, doc.err = c.decode(doc.path, doc.bytes)
+			}
+		}(&documents[i])
+	}
+	wg.Wait()
+	c.stats.DecodeTime += time.Since(start)
+
+	for _, doc := range documents {
+		if doc.err != nil {
+			return doc.err
+		}
+	}
+
+	for _, doc := range documents {
+		c.recordFile(doc.path)
+		if err := c.loadDocument(doc.path, doc.bytes, doc.tree); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseDir loads every file ending in ".conf" or ".toml" in the given
+// directory, in lexical order, in the manner of a conf.d directory. Files
+// compressed with gzip, ending in ".conf.gz" or ".toml.gz", are loaded too, as
+// are files with the extensions given to RegisterDecoder. See ParseFiles.
+func (c *ConfigSet) ParseDir(dir string) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseDir(dir) }
+	key := loadKey("dir", dir)
+	if c.unchanged(key) {
+		return nil
+	}
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	c.cache.key = key
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	c.recordState(dir, fi, nil)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		isTOML := strings.HasSuffix(name, ".conf") || strings.HasSuffix(name, ".toml")
+		if entry.IsDir() || !(isTOML || c.decoderFor(name) != nil) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+
+	return c.parseFiles(paths)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDir(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("%02d-tenant.conf", i))
+		body := fmt.Sprintf("last = %d\n[tenants]\nt%d = true\n", i, i)
+		if err := os.WriteFile(name, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not toml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfigSet("files", ContinueOnError)
+	last := c.Int("last", -1)
+	tenants := make([]*bool, 20)
+	for i := range tenants {
+		tenants[i] = c.Bool(fmt.Sprintf("tenants.t%d", i), false)
+	}
+
+	if err := c.ParseDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if *last != 19 {
+		t.Error("last should be 19, is", *last)
+	}
+	for i, tenant := range tenants {
+		if !*tenant {
+			t.Errorf("tenants.t%d should be true", i)
+		}
+	}
+}
+
+func TestParseFilesError(t *testing.T) {
+	c := NewConfigSet("files", ContinueOnError)
+	port := c.Int("port", 80)
+
+	err := c.ParseFiles(SIMPLE_CONFIG_PATH, INVALID_CONFIG_PATH)
+	if err == nil || err.Error() != "examples/invalid.conf is not a valid TOML file. See https://github.com/mojombo/toml" {
+		t.Error("Expected an invalid TOML error, got", err)
+	}
+	if *port != 80 {
+		t.Error("port should be unchanged, is", *port)
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Describe sets the description of the named config variable, which is used
+// as its usage text when it's mirrored as a command-line flag. It panics if
+// the config hasn't been defined.
+func (c *ConfigSet) Describe(name string, usage string) {
+	f := c.Lookup(c.prefix + name)
+	if f == nil {
+		panic(fmt.Sprintf("config: can't describe undefined config %s", c.prefix+name))
+	}
+	f.Usage = usage
+}
+
+// FlagName returns the name of the command-line flag that mirrors the named
+// config variable: the config name with its dots replaced by dashes, such as
+// "server-port" for "server.port".
+func FlagName(name string) string {
+	return strings.Replace(name, ".", "-", -1)
+}
+
+// -- cmdline Value
+
+// cmdlineValue is the flag.Value registered with a flag.FlagSet by
+// RegisterFlags. It sets the config variable and remembers the value so that
+// it can be applied again after config files are loaded.
+type cmdlineValue struct {
+	c *ConfigSet
+	f *flag.Flag
+}
+
+func (v *cmdlineValue) Set(s string) error {
+	if v.c.frozen {
+		return &FrozenError{v.f.Name, caller()}
+	}
+	if err := v.f.Value.Set(s); err != nil {
+		return err
+	}
+	v.c.cmdline[v.f.Name] = s
+	v.c.invalidateCache()
+	return nil
+}
+
+func (v *cmdlineValue) String() string {
+	if v.f == nil {
+		return ""
+	}
+	return v.f.Value.String()
+}
+
+func (v *cmdlineValue) IsBoolFlag() bool {
+	if b, ok := v.f.Value.(interface{ IsBoolFlag() bool }); ok {
+		return b.IsBoolFlag()
+	}
+	_, ok := v.f.Value.(*boolValue)
+	return ok
+}
+
+// RegisterFlags defines a command-line flag in fs for every config variable
+// defined so far, named by FlagName and using the config's description as its
+// usage text. The usage message of fs is replaced by one that lists the flags
+// grouped by TOML table; see PrintFlagDefaults. Flags take precedence over
+// config files and environment variables: values given on the command line are
+// applied again at the end of every Parse. Typically fs is flag.CommandLine:
+//
+//	config.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//	config.Parse("/etc/myapp.conf")
+func (c *ConfigSet) RegisterFlags(fs *flag.FlagSet) {
+	c.VisitAll(func(f *flag.Flag) {
+		fs.Var(&cmdlineValue{c, f}, FlagName(f.Name), f.Usage)
+	})
+	fs.Usage = func() {
+		if fs.Name() == "" {
+			fmt.Fprintf(fs.Output(), "Usage:\n")
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		}
+		c.PrintFlagDefaults(fs)
+	}
+}
+
+// PrintFlagDefaults writes the usage of every flag in fs to its output, like
+// flag.PrintDefaults, but with the flags that mirror config variables grouped
+// under the TOML table they belong to:
+//
+//	  -debug
+//	    	log every request
+//
+//	[server]
+//	  -server-port int
+//	    	port to listen on (default 8080)
+//
+// Other flags, and configs not in a table, are listed first.
+func (c *ConfigSet) PrintFlagDefaults(fs *flag.FlagSet) {
+	groups := make(map[string][]*flag.Flag)
+	fs.VisitAll(func(f *flag.Flag) {
+		var table string
+		if v, ok := f.Value.(*cmdlineValue); ok && v.c == c {
+			if dot := strings.LastIndex(v.f.Name, "."); dot >= 0 {
+				table = v.f.Name[:dot]
+			}
+		}
+		groups[table] = append(groups[table], f)
+	})
+
+	tables := make([]string, 0, len(groups))
+	for table := range groups {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	w := fs.Output()
+	for _, table := range tables {
+		if table != "" {
+			fmt.Fprintf(w, "\n[%s]\n", table)
+		}
+		for _, f := range groups[table] {
+			name, usage := flag.UnquoteUsage(f)
+			if v, ok := f.Value.(*cmdlineValue); ok && name == "value" {
+				name = typeName(v.f.Value)
+			}
+			if !isZeroDefault(f.DefValue) {
+				usage += fmt.Sprintf(" (default %s)", f.DefValue)
+			}
+			line := "  -" + f.Name
+			if name != "" {
+				line += " " + name
+			}
+			if usage = strings.TrimSpace(usage); usage != "" {
+				line += "\n    \t" + strings.Replace(usage, "\n", "\n    \t", -1)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// typeName returns the name used for a config's type in usage messages, or
+// "" for bools, which don't take an argument.
+func typeName(value flag.Value) string {
+	getter, ok := value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+	switch getter.Get().(type) {
+	case bool:
+		return ""
+	case int, int64:
+		return "int"
+	case uint, uint64:
+		return "uint"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case time.Duration:
+		return "duration"
+	}
+	return "value"
+}
+
+// isZeroDefault reports whether a flag's default is a zero value that isn't
+// worth printing.
+func isZeroDefault(value string) bool {
+	switch value {
+	case "", "0", "false", "0s":
+		return true
+	}
+	return false
+}
+
+// applyFlags sets the values given on the command line again, so that they
+// override any loaded from config files. They were accepted when the command
+// line was parsed, so errors aren't expected. Nothing is set once the
+// ConfigSet has been frozen.
+func (c *ConfigSet) applyFlags() {
+	if c.frozen {
+		return
+	}
+	for name, s := range c.cmdline {
+		if c.Lookup(name).Value.Set(s) == nil {
+			c.recordApplied(name, "flag")
+		}
+	}
+}
+
+// Describe sets the description of the named config variable.
+func Describe(name string, usage string) {
+	global().Describe(name, usage)
+}
+
+// RegisterFlags defines a command-line flag in fs for every config variable
+// defined so far.
+func RegisterFlags(fs *flag.FlagSet) {
+	global().RegisterFlags(fs)
+}
+
+// PrintFlagDefaults writes the usage of every flag in fs to its output, with
+// the flags that mirror config variables grouped by TOML table.
+func PrintFlagDefaults(fs *flag.FlagSet) {
+	global().PrintFlagDefaults(fs)
+}
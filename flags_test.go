@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	c := NewConfigSet("flags", ContinueOnError)
+	port := c.Int("server.port", 8080)
+	host := c.String("server.host", "localhost")
+	debug := c.Bool("debug", false)
+	c.Describe("server.port", "port to listen on")
+
+	fs := flag.NewFlagSet("flags", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	c.RegisterFlags(fs)
+
+	f := fs.Lookup("server-port")
+	if f == nil || f.Usage != "port to listen on" || f.DefValue != "8080" {
+		t.Fatal("Expected a server-port flag, got", f)
+	}
+
+	if err := fs.Parse([]string{"-server-port=9000", "-debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || !*debug {
+		t.Error("Flags should be set by the command line, are", *port, *debug)
+	}
+
+	err := c.ParseLayers(Layers{Baseline: []byte("debug = false\n[server]\nport = 80\nhost = \"example.com\"\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || !*debug {
+		t.Error("Flags should override the config file, are", *port, *debug)
+	}
+	if *host != "example.com" {
+		t.Error("server.host should be set by the config file, is", *host)
+	}
+
+	if err := fs.Parse([]string{"-server-port=http"}); err == nil {
+		t.Error("Expected an invalid flag error")
+	}
+}
+
+func TestRegisterBoolFlags(t *testing.T) {
+	c := NewConfigSet("flags", ContinueOnError)
+	atomic := c.AtomicBool("atomic", false)
+	optional := c.OptionalBool("optional")
+	tri := c.TriBool("tri")
+	c.OptionalInt("count")
+
+	fs := flag.NewFlagSet("flags", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	c.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-atomic", "-optional", "-tri"}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := optional.Get(); !atomic.Load() || !v || !ok || *tri != TriTrue {
+		t.Error("Flags should be set without a value, are", atomic.Load(), v, ok, *tri)
+	}
+	if err := fs.Parse([]string{"-count"}); err == nil {
+		t.Error("Expected a missing value error for an optional int")
+	}
+}
+
+func TestPrintFlagDefaults(t *testing.T) {
+	c := NewConfigSet("flags", ContinueOnError)
+	c.Int("server.port", 8080)
+	c.Bool("debug", false)
+	c.Duration("server.timeout", 0)
+	c.Describe("server.port", "port to listen on")
+	c.Describe("debug", "log every request")
+
+	fs := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	fs.String("config", "", "path to the config `file`")
+	c.RegisterFlags(fs)
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.Usage()
+
+	expected := `Usage of myapp:
+  -config file
+    	path to the config file
+  -debug
+    	log every request
+
+[server]
+  -server-port int
+    	port to listen on (default 8080)
+  -server-timeout duration
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected usage:\n%s", out.String())
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten returns the values in a map of nested tables, as returned by
+// Provider.Load, keyed by their dotted names, such as "server.port". Keys
+// that can't be written bare in TOML, such as keys containing dots, are
+// quoted as in a TOML dotted key, so the table {"hosts": {"eu.west": 1}}
+// flattens to {`hosts."eu.west"`: 1}. Empty tables are kept as empty maps.
+func Flatten(values map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flatten(flat, "", values)
+	return flat
+}
+
+func flatten(flat map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range values {
+		name := prefix + quoteKey(key)
+		if table, ok := value.(map[string]interface{}); ok && len(table) > 0 {
+			flatten(flat, name+".", table)
+		} else {
+			flat[name] = value
+		}
+	}
+}
+
+// Expand is the inverse of Flatten: it returns a map of nested tables holding
+// the values in flat, which are keyed by dotted names that may contain quoted
+// keys. It returns an error if a name is malformed, or if a name refers both
+// to a value and to a table, such as "server" and "server.port".
+func Expand(flat map[string]interface{}) (map[string]interface{}, error) {
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]interface{})
+	for _, name := range names {
+		keys, err := splitKey(name)
+		if err != nil {
+			return nil, err
+		}
+		table := values
+		for i, key := range keys[:len(keys)-1] {
+			switch sub := table[key].(type) {
+			case map[string]interface{}:
+				table = sub
+			case nil:
+				table[key] = make(map[string]interface{})
+				table = table[key].(map[string]interface{})
+			default:
+				return nil, fmt.Errorf("%s is both a value and a table", joinKeys(keys[:i+1]))
+			}
+		}
+		last := keys[len(keys)-1]
+		if _, exists := table[last]; exists {
+			return nil, fmt.Errorf("%s is both a value and a table", joinKeys(keys))
+		}
+		table[last] = flat[name]
+	}
+	return values, nil
+}
+
+// isBareKey reports whether key can be written in TOML without quotes.
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteKey returns key as written in a TOML dotted key.
+func quoteKey(key string) string {
+	if isBareKey(key) {
+		return key
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range key {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func joinKeys(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = quoteKey(key)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// splitKey splits a TOML dotted key, such as `hosts."eu.west".port`, into
+// its keys.
+func splitKey(name string) ([]string, error) {
+	var keys []string
+	for i := 0; ; {
+		var key string
+		switch {
+		case i < len(name) && name[i] == '"':
+			end := i + 1
+			for ; end < len(name) && name[end] != '"'; end++ {
+				if name[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(name) {
+				return nil, fmt.Errorf("%s has an unterminated quoted key", name)
+			}
+			s, err := strconv.Unquote(name[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%s has an invalid quoted key", name)
+			}
+			key, i = s, end+1
+		case i < len(name) && name[i] == '\'':
+			end := strings.IndexByte(name[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%s has an unterminated quoted key", name)
+			}
+			key, i = name[i+1:i+1+end], i+end+2
+		default:
+			end := strings.IndexByte(name[i:], '.')
+			if end < 0 {
+				end = len(name) - i
+			}
+			key, i = name[i:i+end], i+end
+			if !isBareKey(key) {
+				return nil, fmt.Errorf("%s is not a valid dotted key", name)
+			}
+		}
+		keys = append(keys, key)
+		if i == len(name) {
+			return keys, nil
+		}
+		if name[i] != '.' {
+			return nil, fmt.Errorf("%s is not a valid dotted key", name)
+		}
+		i++
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	values := map[string]interface{}{
+		"name": "app",
+		"server": map[string]interface{}{
+			"port": int64(8080),
+			"tls":  map[string]interface{}{"enabled": true},
+		},
+		"hosts": map[string]interface{}{
+			"eu.west":  "10.0.0.1",
+			`say "hi"`: "x",
+		},
+		"empty": map[string]interface{}{},
+	}
+
+	flat := Flatten(values)
+	expected := map[string]interface{}{
+		"name":               "app",
+		"server.port":        int64(8080),
+		"server.tls.enabled": true,
+		`hosts."eu.west"`:    "10.0.0.1",
+		`hosts."say \"hi\""`: "x",
+		"empty":              map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Unexpected flattened map: %#v", flat)
+	}
+
+	expanded, err := Expand(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expanded, values) {
+		t.Errorf("Unexpected expanded map: %#v", expanded)
+	}
+
+	expanded, err = Expand(map[string]interface{}{`a.'b.c'`: 1})
+	if err != nil || !reflect.DeepEqual(expanded, map[string]interface{}{"a": map[string]interface{}{"b.c": 1}}) {
+		t.Error("Unexpected result:", expanded, err)
+	}
+
+	for name, msg := range map[string]string{
+		`a."b`: `a."b has an unterminated quoted key`,
+		`a..b`: `a..b is not a valid dotted key`,
+		`a b`:  `a b is not a valid dotted key`,
+	} {
+		if _, err := Expand(map[string]interface{}{name: 1}); err == nil || err.Error() != msg {
+			t.Errorf("Expected %q, got %v", msg, err)
+		}
+	}
+
+	_, err = Expand(map[string]interface{}{"server": 1, "server.port": 2})
+	if err == nil || err.Error() != "server is both a value and a table" {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+)
+
+// FrozenError is returned when a config variable is changed after Freeze.
+type FrozenError struct {
+	// Name is the config that was changed.
+	Name string
+	// Caller describes the code that tried to change it, such as
+	// "plugin.go:42 (example.com/plugin.Init)".
+	Caller string
+}
+
+func (e *FrozenError) Error() string {
+	return fmt.Sprintf("config: can't set %s after Freeze, at %s", e.Name, e.Caller)
+}
+
+// Freeze makes the ConfigSet read-only. Afterwards, Set returns a
+// *FrozenError naming the config and the code that tried to change it, and
+// every Parse method, Reload, and Rollback return one naming "the
+// configuration" before changing anything, instead of changing configs while
+// other goroutines may be reading them.
+func (c *ConfigSet) Freeze() {
+	c.frozen = true
+}
+
+// checkFrozen returns a *FrozenError if the ConfigSet has been frozen. Every
+// load calls it before changing anything.
+func (c *ConfigSet) checkFrozen() error {
+	if c.frozen {
+		return &FrozenError{"the configuration", caller()}
+	}
+	return nil
+}
+
+// Frozen reports whether Freeze has been called.
+func (c *ConfigSet) Frozen() bool {
+	return c.frozen
+}
+
+// Set sets the value of the named config variable, saving the change with the
+// Persister given to SetPersister, if any. It returns a *FrozenError if the
+// ConfigSet has been frozen.
+func (c *ConfigSet) Set(name string, value string) error {
+	if c.frozen {
+		return &FrozenError{name, caller()}
+	}
+	var restore func() error
+	if f := c.Lookup(name); f != nil {
+		restore = saveValue(f.Value)
+	}
+	if err := c.FlagSet.Set(name, value); err != nil {
+		return err
+	}
+	if err := c.persist(name, restore); err != nil {
+		return err
+	}
+	c.recordSet(c.Lookup(name).Name)
+	c.derive()
+	c.invalidateCache()
+	return nil
+}
+
+// Freeze makes the global config read-only.
+func Freeze() {
+	global().Freeze()
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	c := NewConfigSet("freeze", ContinueOnError)
+	port := c.Int("server.port", 8080)
+
+	if err := c.Set("server.port", "9000"); err != nil || *port != 9000 {
+		t.Fatal("server.port should be settable before Freeze", err)
+	}
+
+	c.Freeze()
+	err := c.Set("server.port", "80")
+	fe, ok := err.(*FrozenError)
+	if !ok || fe.Name != "server.port" || !strings.HasPrefix(fe.Caller, "freeze_test.go:") {
+		t.Fatal("Expected a FrozenError, got", err)
+	}
+	if !strings.HasPrefix(err.Error(), "config: can't set server.port after Freeze, at freeze_test.go:") {
+		t.Error("Unexpected error message:", err)
+	}
+
+	stats := c.Stats()
+	err = c.ParseLayers(Layers{Baseline: []byte("[server]\nport = 80\n")})
+	if _, ok := err.(*FrozenError); !ok {
+		t.Error("Expected a FrozenError loading a frozen ConfigSet, got", err)
+	}
+	if err := c.ParseEnvOnly(); err == nil || !strings.HasPrefix(err.Error(), "config: can't set the configuration after Freeze, at freeze_test.go:") {
+		t.Error("Expected a FrozenError loading a frozen ConfigSet, got", err)
+	}
+	if *port != 9000 {
+		t.Error("server.port shouldn't change after Freeze, is", *port)
+	}
+	if c.Stats() != stats || c.LoadInfo().Generation != 0 {
+		t.Error("A load after Freeze shouldn't reset the stats, are", c.Stats(), c.LoadInfo())
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+)
+
+// -- func Value
+
+type funcValue struct {
+	fn  func(raw string) error
+	raw string
+	set bool
+}
+
+func (f *funcValue) Set(s string) error {
+	if err := f.fn(s); err != nil {
+		return err
+	}
+	f.raw, f.set = s, true
+	return nil
+}
+
+// setTOML passes strings to the function as they are, and other values as
+// they're formatted by fmt.
+func (f *funcValue) setTOML(value interface{}) error {
+	return f.Set(fmt.Sprint(value))
+}
+
+func (f *funcValue) String() string { return f.raw }
+
+// snapshot passes the saved value to the function again when it's restored.
+// If the config hadn't been set, the function isn't called, so the effects of
+// the value being undone stay in place.
+func (f *funcValue) snapshot() func() error {
+	raw, set := f.raw, f.set
+	return func() error {
+		if !set {
+			f.raw, f.set = "", false
+			return nil
+		}
+		return f.Set(raw)
+	}
+}
+
+// Func defines a config with the given name whose value is handled by fn,
+// like flag.Func, so that a one-off setting with unusual syntax doesn't need
+// its own flag.Value type. fn is called with the value each time the config
+// is loaded; strings are passed as they are, and other TOML values as
+// formatted by fmt, so 8080 is passed as "8080". If fn returns an error, the
+// load fails with it. fn isn't called for configs that no source sets.
+func (c *ConfigSet) Func(name string, fn func(raw string) error) {
+	c.Var(&funcValue{fn: fn}, name, "")
+}
+
+// -- tree func Value
+
+type treeFuncValue struct {
+	fn    func(value interface{}) error
+	value interface{}
+}
+
+func (t *treeFuncValue) Set(s string) error {
+	return t.setTOML(s)
+}
+
+func (t *treeFuncValue) setTOML(value interface{}) error {
+	if err := t.fn(value); err != nil {
+		return err
+	}
+	t.value = value
+	return nil
+}
+
+func (t *treeFuncValue) isTable() {}
+
+func (t *treeFuncValue) Get() interface{} { return t.value }
+
+// snapshot passes the saved value to the function again when it's restored,
+// unless the config hadn't been set.
+func (t *treeFuncValue) snapshot() func() error {
+	value := t.value
+	return func() error {
+		if value == nil {
+			t.value = nil
+			return nil
+		}
+		return t.setTOML(value)
+	}
+}
+
+func (t *treeFuncValue) String() string {
+	if t == nil || t.value == nil {
+		return ""
+	}
+	return fmt.Sprint(t.value)
+}
+
+// TreeFunc defines a config with the given name whose value is handled by fn,
+// which receives the value as decoded from TOML: a string, int64, float64,
+// bool, time.Time, or []interface{}, or a *toml.Tree if the config is a
+// table, such as [db] for a config named "db". Values from environment
+// variables and command-line flags are passed as strings. If fn returns an
+// error, the load fails with it.
+func (c *ConfigSet) TreeFunc(name string, fn func(value interface{}) error) {
+	c.Var(&treeFuncValue{fn: fn}, name, "")
+}
+
+// Func defines a config with the given name whose value is handled by fn.
+func Func(name string, fn func(raw string) error) {
+	global().Func(name, fn)
+}
+
+// TreeFunc defines a config with the given name whose decoded TOML value is
+// handled by fn.
+func TreeFunc(name string, fn func(value interface{}) error) {
+	global().TreeFunc(name, fn)
+}
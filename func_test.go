@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+func TestFunc(t *testing.T) {
+	c := NewConfigSet("func", ContinueOnError)
+	var hosts []string
+	c.Func("hosts", func(raw string) error {
+		if raw == "" {
+			return errors.New("no hosts given")
+		}
+		hosts = strings.Split(raw, "|")
+		return nil
+	})
+	var port string
+	c.Func("port", func(raw string) error {
+		port = raw
+		return nil
+	})
+
+	if err := c.parseBytes("func", []byte("hosts = \"a|b\"\nport = 8080\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[1] != "b" || port != "8080" || c.Lookup("hosts").Value.String() != "a|b" {
+		t.Errorf("Unexpected values: %q %q", hosts, port)
+	}
+
+	err := c.parseBytes("func", []byte("hosts = \"\"\n"))
+	if err == nil || err.Error() != "The value for hosts is invalid: no hosts given" {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestTreeFunc(t *testing.T) {
+	c := NewConfigSet("func", ContinueOnError)
+	var weights map[string]int64
+	c.TreeFunc("weights", func(value interface{}) error {
+		tree, ok := value.(*toml.Tree)
+		if !ok {
+			return errors.New("must be a table")
+		}
+		weights = make(map[string]int64)
+		for _, key := range tree.Keys() {
+			weights[key], _ = tree.Get(key).(int64)
+		}
+		return nil
+	})
+	var ports []interface{}
+	c.TreeFunc("ports", func(value interface{}) error {
+		ports, _ = value.([]interface{})
+		return nil
+	})
+
+	if err := c.parseBytes("func", []byte("ports = [80, 443]\n[weights]\na = 1\nb = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(weights) != 2 || weights["b"] != 2 || len(ports) != 2 || ports[1] != int64(443) {
+		t.Errorf("Unexpected values: %v %v", weights, ports)
+	}
+
+	err := c.parseBytes("func", []byte("weights = 3\n"))
+	if err == nil || err.Error() != "The value for weights is invalid: must be a table" {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Globs is a list of path.Match patterns. In addition to path.Match syntax, a
+// "**" path element matches zero or more path elements.
+type Globs []string
+
+// Match reports whether name matches any of the patterns.
+func (g Globs) Match(name string) bool {
+	names := strings.Split(name, "/")
+	for _, pattern := range g {
+		if matchElems(strings.Split(pattern, "/"), names) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchElems(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := len(names); i >= 0; i-- {
+				if matchElems(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+// validateGlob returns path.ErrBadPattern if pattern is malformed.
+func validateGlob(pattern string) error {
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Globs) Set(s string) error {
+	return g.setTOML(s)
+}
+
+func (g *Globs) setTOML(value interface{}) error {
+	patterns, err := tomlStrings(value)
+	if err != nil {
+		return err
+	}
+	for i, pattern := range patterns {
+		if err := validateGlob(pattern); err != nil {
+			return elementError{i, fmt.Sprintf("(%q) is not a valid glob pattern", pattern)}
+		}
+	}
+	*g = patterns
+	return nil
+}
+
+func (g *Globs) Get() interface{} { return *g }
+
+func (g *Globs) snapshot() func() error {
+	v := slices.Clone(*g)
+	return func() error {
+		*g = v
+		return nil
+	}
+}
+
+func (g *Globs) String() string { return strings.Join(*g, ",") }
+
+// GlobListVar defines a glob pattern list config with a given name for a
+// ConfigSet. The argument p points to a Globs variable in which to store the
+// value of the config.
+func (c *ConfigSet) GlobListVar(p *Globs, name string) {
+	c.Var(p, name, "")
+}
+
+// GlobList defines a glob pattern list config variable with a given name for
+// a ConfigSet. The TOML value is an array of patterns, each of which is
+// checked when the config is loaded so that malformed patterns are reported
+// instead of silently never matching.
+func (c *ConfigSet) GlobList(name string) *Globs {
+	p := new(Globs)
+	c.GlobListVar(p, name)
+	return p
+}
+
+// GlobListVar defines a glob pattern list config with a given name. The
+// argument p points to a Globs variable in which to store the value of the
+// config.
+func GlobListVar(p *Globs, name string) {
+	global().GlobListVar(p, name)
+}
+
+// GlobList defines a glob pattern list config variable with a given name.
+func GlobList(name string) *Globs {
+	return global().GlobList(name)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestGlobList(t *testing.T) {
+	c := NewConfigSet("glob", ContinueOnError)
+	ignore := c.GlobList("watch.ignore")
+
+	err := c.parseBytes("glob", []byte("[watch]\nignore = [\"*.tmp\", \"build/**\", \"**/node_modules/**\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"a.tmp":                     true,
+		"dir/a.tmp":                 false,
+		"build":                     true,
+		"build/out/app":             true,
+		"src/web/node_modules/x.js": true,
+		"src/main.go":               false,
+	}
+	for name, expected := range tests {
+		if got := ignore.Match(name); got != expected {
+			t.Errorf("Match(%q) should be %v, is %v", name, expected, got)
+		}
+	}
+
+	err = c.parseBytes("glob", []byte("[watch]\nignore = [\"*.tmp\", \"[a-\"]\n"))
+	if err == nil || err.Error() != `watch.ignore[1] ("[a-") is not a valid glob pattern at glob:2` {
+		t.Error("Expected an invalid pattern error, got", err)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// GlobalOption changes how the global ConfigSet, used by the package-level
+// functions, handles errors and names itself.
+type GlobalOption func(*globalOptions)
+
+type globalOptions struct {
+	name          string
+	errorHandling flag.ErrorHandling
+}
+
+// WithName sets the name of the global ConfigSet, which is os.Args[0] by
+// default.
+func WithName(name string) GlobalOption {
+	return func(o *globalOptions) {
+		o.name = name
+	}
+}
+
+// WithErrorHandling sets the error handling policy of the global ConfigSet,
+// which is ContinueOnError by default. Applications that want errors to end
+// the process opt in with ExitOnError, which makes the package-level Parse
+// functions and Reload print the error and exit with status 2, as a
+// flag.FlagSet does. PanicOnError makes them panic with the error instead.
+func WithErrorHandling(errorHandling flag.ErrorHandling) GlobalOption {
+	return func(o *globalOptions) {
+		o.errorHandling = errorHandling
+	}
+}
+
+var (
+	globalOnce sync.Once
+	globalSet  *ConfigSet
+)
+
+// global returns the global ConfigSet, creating it on first use, so that
+// merely importing the package has no side effects.
+func global() *ConfigSet {
+	globalOnce.Do(func() {
+		globalSet = NewConfigSet(os.Args[0], ContinueOnError)
+	})
+	return globalSet
+}
+
+// handleGlobalError applies the global ConfigSet's error handling policy to an
+// error from loading it.
+func handleGlobalError(err error) error {
+	if err == nil {
+		return nil
+	}
+	c := global()
+	switch c.ErrorHandling() {
+	case ExitOnError:
+		fmt.Fprintln(c.Output(), err)
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// SetGlobalOptions changes the global ConfigSet used by the package-level
+// functions. It's meant to be called once, early in main:
+//
+//	config.SetGlobalOptions(config.WithErrorHandling(config.ExitOnError))
+func SetGlobalOptions(opts ...GlobalOption) {
+	c := global()
+	o := globalOptions{name: c.Name(), errorHandling: c.ErrorHandling()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c.Init(o.name, o.errorHandling)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSetGlobalOptions(t *testing.T) {
+	c := global()
+	defer c.Init(c.Name(), c.ErrorHandling())
+	if c.ErrorHandling() != ContinueOnError {
+		t.Error("The global config should continue on errors by default")
+	}
+	if global() != c {
+		t.Error("The global config should only be created once")
+	}
+
+	SetGlobalOptions(WithErrorHandling(PanicOnError))
+	if c.ErrorHandling() != PanicOnError || c.Name() == "myapp" {
+		t.Error("Unexpected global options:", c.ErrorHandling(), c.Name())
+	}
+	SetGlobalOptions(WithName("myapp"))
+	if c.ErrorHandling() != PanicOnError || c.Name() != "myapp" {
+		t.Error("Unexpected global options:", c.ErrorHandling(), c.Name())
+	}
+}
+
+func TestGlobalErrorHandling(t *testing.T) {
+	if os.Getenv("CONFIG_TEST_EXIT") == "1" {
+		SetGlobalOptions(WithErrorHandling(ExitOnError))
+		ParseLayers(Layers{Baseline: []byte("bogus = 1\n")})
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGlobalErrorHandling$")
+	cmd.Env = append(os.Environ(), "CONFIG_TEST_EXIT=1")
+	output, err := cmd.CombinedOutput()
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 2 {
+		t.Fatal("Expected exit status 2, got", err)
+	}
+	if !strings.Contains(string(output), "bogus is not a valid config setting") {
+		t.Errorf("Expected the error to be printed, got %q", output)
+	}
+
+	c := global()
+	defer c.Init(c.Name(), c.ErrorHandling())
+	SetGlobalOptions(WithErrorHandling(PanicOnError))
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	ParseLayers(Layers{Baseline: []byte("bogus = 1\n")})
+}
@@ -0,0 +1,103 @@
+// Package gpgconfig decrypts config files encrypted with OpenPGP, such as
+// "app.toml.gpg", for teams whose secret distribution is already built on
+// GPG:
+//
+//	config.SetFileDecrypter(gpgconfig.Agent())
+//	err := config.Parse("/etc/myapp/app.toml.gpg")
+package gpgconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/stvp/go-toml-config"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+// Ext is the file extension of the config files that are decrypted.
+const Ext = ".gpg"
+
+// Decrypter decrypts config files whose names end in Ext, either with a
+// keyring or by running gpg, which uses gpg-agent. It implements
+// config.FileDecrypter.
+type Decrypter struct {
+	keyring openpgp.KeyRing
+	prompt  openpgp.PromptFunction
+	gpg     string
+}
+
+var _ config.FileDecrypter = (*Decrypter)(nil)
+
+// Keyring returns a Decrypter that decrypts files with the private keys in
+// keyring, which can be read with openpgp.ReadKeyRing or
+// openpgp.ReadArmoredKeyRing. The prompt is called to decrypt keys protected
+// by a passphrase, and may be nil if none are.
+func Keyring(keyring openpgp.KeyRing, prompt openpgp.PromptFunction) *Decrypter {
+	return &Decrypter{keyring: keyring, prompt: prompt}
+}
+
+// Agent returns a Decrypter that decrypts files by running "gpg --decrypt",
+// which gets keys from gpg-agent.
+func Agent() *Decrypter {
+	return &Decrypter{gpg: "gpg"}
+}
+
+// DecryptFile decrypts data, the contents of the file at path, if path ends
+// in Ext. Both binary and ASCII-armored messages are accepted. A signed
+// message is rejected unless its signature is verified by a key in the
+// keyring.
+func (d *Decrypter) DecryptFile(path string, data []byte) ([]byte, bool, error) {
+	if !strings.HasSuffix(path, Ext) {
+		return nil, false, nil
+	}
+	if d.gpg != "" {
+		plaintext, err := d.runGPG(data)
+		return plaintext, true, err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP MESSAGE-----")) {
+		block, err := armor.Decode(r)
+		if err != nil {
+			return nil, true, err
+		}
+		r = block.Body
+	}
+	md, err := openpgp.ReadMessage(r, d.keyring, d.prompt, nil)
+	if err != nil {
+		return nil, true, err
+	}
+	plaintext, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, true, err
+	}
+	// The signature is only checked once the body has been read to the end.
+	if md.SignatureError != nil {
+		return nil, true, fmt.Errorf("bad signature: %s", md.SignatureError)
+	}
+	if md.IsSigned && (md.SignedBy == nil || md.Signature == nil && md.SignatureV3 == nil) {
+		return nil, true, errors.New("the message is signed, but the signature can't be verified")
+	}
+	return plaintext, true, nil
+}
+
+func (d *Decrypter) runGPG(data []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(d.gpg, "--batch", "--quiet", "--decrypt")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
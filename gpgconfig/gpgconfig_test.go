@@ -0,0 +1,93 @@
+package gpgconfig
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stvp/go-toml-config"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func TestKeyring(t *testing.T) {
+	cfg := &packet.Config{DefaultHash: crypto.SHA256}
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	out, _ := armor.Encode(&buf, "PGP MESSAGE", nil)
+	w, err := openpgp.Encrypt(out, []*openpgp.Entity{entity}, nil, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "[db]\npassword = \"hunter2\"\n")
+	w.Close()
+	out.Close()
+
+	dir, err := ioutil.TempDir("", "gpgconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.toml.gpg")
+	ioutil.WriteFile(path, buf.Bytes(), 0600)
+	plainPath := filepath.Join(dir, "plain.toml")
+	ioutil.WriteFile(plainPath, []byte("[db]\nuser = \"app\"\n"), 0644)
+
+	c := config.NewConfigSet("gpgconfig", config.ContinueOnError)
+	password := c.String("db.password", "")
+	user := c.String("db.user", "")
+	c.SetFileDecrypter(Keyring(openpgp.EntityList{entity}, nil))
+
+	if err := c.ParseFiles(plainPath, path); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "hunter2" || *user != "app" {
+		t.Error("Unexpected settings:", *password, *user)
+	}
+
+	other, _ := openpgp.NewEntity("Other", "", "other@example.com", cfg)
+	c.SetFileDecrypter(Keyring(openpgp.EntityList{other}, nil))
+	if err := c.Parse(path); err == nil {
+		t.Error("Expected a decryption error")
+	}
+}
+
+func TestKeyringSigned(t *testing.T) {
+	cfg := &packet.Config{DefaultHash: crypto.SHA256}
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := openpgp.NewEntity("Stranger", "", "stranger@example.com", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt := func(signer *openpgp.Entity) []byte {
+		var buf bytes.Buffer
+		w, err := openpgp.Encrypt(&buf, []*openpgp.Entity{entity}, signer, nil, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "password = \"hunter2\"\n")
+		w.Close()
+		return buf.Bytes()
+	}
+	d := Keyring(openpgp.EntityList{entity}, nil)
+
+	plaintext, _, err := d.DecryptFile("app.toml.gpg", encrypt(entity))
+	if err != nil || string(plaintext) != "password = \"hunter2\"\n" {
+		t.Errorf("Unexpected result: %q, %v", plaintext, err)
+	}
+	if _, _, err := d.DecryptFile("app.toml.gpg", encrypt(stranger)); err == nil {
+		t.Error("Expected an error for a message signed by an unknown key")
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Group defines a config variable for each exported field of the struct that
+// opts points to, in the table named by prefix, using the field's current
+// value as its default. The struct's fields are set as configs are loaded:
+//
+//	type ServerOpts struct {
+//		Host    string
+//		Port    int
+//		MaxConn int `toml:"max_connections"`
+//	}
+//	server := &ServerOpts{Host: "0.0.0.0", Port: 8080}
+//	c.Group("server", server)
+//
+// A field's config name is given by its toml tag, or else is the field name
+// in snake case, such as "max_conn" for MaxConn. Fields tagged `toml:"-"` are
+// skipped. Fields may be bools, strings, ints, uints, float64s, durations,
+// times, string slices (see StringSet), types whose pointers implement
+// flag.Value, or structs, which become nested tables. Group panics if opts
+// isn't a pointer to a struct or if a field has another type.
+func (c *ConfigSet) Group(prefix string, opts interface{}) {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("config: Group needs a pointer to a struct, not %T", opts))
+	}
+	if prefix != "" {
+		prefix += "."
+	}
+	c.defineGroup(prefix, v.Elem())
+}
+
+func (c *ConfigSet) defineGroup(prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("toml")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		name = prefix + name
+
+		fv := v.Field(i)
+		p := fv.Addr().Interface()
+		if value, ok := p.(flag.Value); ok {
+			c.Var(value, name, "")
+			continue
+		}
+
+		switch p := p.(type) {
+		case *bool:
+			c.BoolVar(p, name, *p)
+		case *string:
+			c.StringVar(p, name, *p)
+		case *int:
+			c.IntVar(p, name, *p)
+		case *int64:
+			c.Int64Var(p, name, *p)
+		case *uint:
+			c.UintVar(p, name, *p)
+		case *uint64:
+			c.Uint64Var(p, name, *p)
+		case *float64:
+			c.Float64Var(p, name, *p)
+		case *time.Duration:
+			c.DurationVar(p, name, *p)
+		case *time.Time:
+			c.TimeVar(p, name, *p)
+		case *[]string:
+			c.StringSetVar(p, name, *p)
+		default:
+			if fv.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("config: %s has unsupported type %s", name, fv.Type()))
+			}
+			c.defineGroup(name+".", fv)
+		}
+	}
+}
+
+// snakeCase converts a Go field name such as "MaxIdleConns" or "HTTPPort" to
+// the snake case used in config files, such as "max_idle_conns" or
+// "http_port".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Group defines a config variable for each exported field of the struct that
+// opts points to, in the table named by prefix.
+func Group(prefix string, opts interface{}) {
+	global().Group(prefix, opts)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type testServerOpts struct {
+	Host     string
+	Port     int
+	MaxConns int `toml:"max_connections"`
+	Timeout  time.Duration
+	Aliases  []string
+	Quiet    Clock
+	TLS      struct {
+		Enabled  bool
+		CertFile string
+	}
+	internal int
+	Skipped  int `toml:"-"`
+}
+
+func TestGroup(t *testing.T) {
+	c := NewConfigSet("group", ContinueOnError)
+	server := &testServerOpts{Host: "0.0.0.0", Port: 8080, Timeout: time.Second}
+	c.Group("server", server)
+
+	if c.Lookup("server.skipped") != nil || c.Lookup("server.internal") != nil {
+		t.Error("Skipped and unexported fields shouldn't be defined")
+	}
+
+	err := c.parseBytes("group", []byte(`
+[server]
+port = 9000
+max_connections = 100
+timeout = "5s"
+aliases = ["a", "b"]
+quiet = "22:00"
+
+[server.tls]
+enabled = true
+cert_file = "/etc/cert.pem"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.Host != "0.0.0.0" || server.Port != 9000 || server.MaxConns != 100 || server.Timeout != 5*time.Second {
+		t.Errorf("Unexpected server settings: %+v", server)
+	}
+	if len(server.Aliases) != 2 || server.Quiet != (Clock{22, 0, 0}) {
+		t.Errorf("Unexpected server settings: %+v", server)
+	}
+	if !server.TLS.Enabled || server.TLS.CertFile != "/etc/cert.pem" {
+		t.Errorf("Unexpected TLS settings: %+v", server.TLS)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an unsupported type to panic")
+		}
+	}()
+	c.Group("bad", &struct{ Ch chan int }{})
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Host":         "host",
+		"MaxIdleConns": "max_idle_conns",
+		"HTTPPort":     "http_port",
+		"TLS":          "tls",
+		"CertFile":     "cert_file",
+	} {
+		if got := snakeCase(name); got != expected {
+			t.Errorf("snakeCase(%q) should be %q, is %q", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// gzipMagic begins every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns the decompressed contents of a config file if they are
+// compressed with gzip, as large generated configs often are, and data
+// unchanged otherwise. Files are recognized by their contents rather than by
+// a ".gz" extension, so an encrypted "app.toml.gz.gpg" works too. The size
+// limit applies to the decompressed contents.
+func (c *ConfigSet) decompress(path string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s can't be decompressed: %s", path, err)
+	}
+	var src io.Reader = r
+	if max := c.limits.MaxFileSize; max > 0 {
+		src = io.LimitReader(r, max+1)
+	}
+	plaintext, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s can't be decompressed: %s", path, err)
+	}
+	return plaintext, c.checkSize(path, len(plaintext))
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gzipBytes(data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "10-base.toml.gz"), gzipBytes("port = 8081\nhost = \"a\"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "20-override.conf"), []byte("host = \"b\"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt.gz"), gzipBytes("junk"), 0644)
+
+	c := NewConfigSet("gzip", ContinueOnError)
+	port := c.Int("port", 8080)
+	host := c.String("host", "")
+
+	if err := c.Parse(filepath.Join(dir, "10-base.toml.gz")); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8081 || *host != "a" {
+		t.Error("Unexpected settings:", *port, *host)
+	}
+
+	*port = 0
+	if err := c.ParseDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8081 || *host != "b" {
+		t.Error("Unexpected settings from ParseDir:", *port, *host)
+	}
+
+	bomb := filepath.Join(dir, "bomb.toml.gz")
+	ioutil.WriteFile(bomb, gzipBytes("#"+strings.Repeat(" ", 1<<20)), 0644)
+	c.SetLimits(Limits{MaxFileSize: 1024})
+	if err := c.Parse(bomb); err == nil || err.Error() != bomb+" is larger than the limit of 1024 bytes" {
+		t.Error("Unexpected error:", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.toml.gz")
+	ioutil.WriteFile(corrupt, gzipBytes("port = 1\n")[:12], 0644)
+	if err := c.Parse(corrupt); err == nil || !strings.HasPrefix(err.Error(), corrupt+" can't be decompressed: ") {
+		t.Error("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// -- http.Header Value
+
+type headerValue http.Header
+
+func (h *headerValue) Set(s string) error {
+	return h.setTOML(s)
+}
+
+// setTOML accepts a table of header names to a string or array of strings,
+// or an array of "Name: value" strings.
+func (h *headerValue) setTOML(value interface{}) error {
+	header := make(http.Header)
+
+	if tree, ok := value.(*toml.Tree); ok {
+		keys := tree.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			values, err := headerStrings(tree.Get(key))
+			if err != nil {
+				return fmt.Errorf("the value for header %s must be a string or an array of strings", key)
+			}
+			for _, v := range values {
+				if err := addHeader(header, key, v); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		lines, err := headerStrings(value)
+		if err != nil {
+			return err
+		}
+		for i, line := range lines {
+			colon := strings.Index(line, ":")
+			if colon < 0 {
+				return elementError{i, fmt.Sprintf("(%q) is not of the form \"Name: value\"", line)}
+			}
+			if err := addHeader(header, line[:colon], strings.TrimSpace(line[colon+1:])); err != nil {
+				return elementError{i, "is not a valid header: " + err.Error()}
+			}
+		}
+	}
+
+	*h = headerValue(header)
+	return nil
+}
+
+func (h *headerValue) isTable() {}
+
+func (h *headerValue) Get() interface{} { return http.Header(*h) }
+
+func (h *headerValue) snapshot() func() error {
+	v := http.Header(*h).Clone()
+	return func() error {
+		*h = headerValue(v)
+		return nil
+	}
+}
+
+func (h *headerValue) String() string {
+	var lines []string
+	for name, values := range *h {
+		for _, v := range values {
+			lines = append(lines, name+": "+v)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, ",")
+}
+
+// headerStrings is like tomlStrings, but keeps a single string whole, since
+// header values such as "no-cache, no-store" contain commas.
+func headerStrings(value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return []string{}, nil
+		}
+		return []string{s}, nil
+	}
+	return tomlStrings(value)
+}
+
+// addHeader validates and adds a header to h.
+func addHeader(h http.Header, name, value string) error {
+	if !validHeaderName(name) {
+		return fmt.Errorf("%q is not a valid header name", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("the value for header %s contains a line break", name)
+	}
+	h.Add(name, value)
+	return nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name,
+// which is an RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= 0x80 || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}\x7f", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// HeadersVar defines an http.Header config with a given name for a
+// ConfigSet. The argument p points to an http.Header variable in which to
+// store the value of the config.
+func (c *ConfigSet) HeadersVar(p *http.Header, name string) {
+	c.Var((*headerValue)(p), name, "")
+}
+
+// Headers defines an http.Header config variable with a given name for a
+// ConfigSet. The TOML value may be a table mapping header names to a string
+// or an array of strings, or an array of "Name: value" strings. A string is
+// used as a single value even if it contains commas.
+func (c *ConfigSet) Headers(name string) *http.Header {
+	p := new(http.Header)
+	c.HeadersVar(p, name)
+	return p
+}
+
+// HeadersVar defines an http.Header config with a given name. The argument p
+// points to an http.Header variable in which to store the value of the
+// config.
+func HeadersVar(p *http.Header, name string) {
+	global().HeadersVar(p, name)
+}
+
+// Headers defines an http.Header config variable with a given name.
+func Headers(name string) *http.Header {
+	return global().Headers(name)
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	c := NewConfigSet("http", ContinueOnError)
+	static := c.Headers("outbound.headers")
+	extra := c.Headers("outbound.extra")
+
+	err := c.parseBytes("http", []byte(`
+[outbound]
+extra = ["X-Request-Source: config", "x-trace:on"]
+
+[outbound.headers]
+User-Agent = "myapp/1.0"
+Cache-Control = "no-cache, no-store"
+Accept = ["text/html", "application/json"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := static.Values("Accept"); !reflect.DeepEqual(got, []string{"text/html", "application/json"}) {
+		t.Error("Unexpected Accept values:", got)
+	}
+	if got := static.Get("User-Agent"); got != "myapp/1.0" {
+		t.Error("Unexpected User-Agent:", got)
+	}
+	if got := static.Values("Cache-Control"); !reflect.DeepEqual(got, []string{"no-cache, no-store"}) {
+		t.Error("Unexpected Cache-Control values:", got)
+	}
+	if got := extra.Get("X-Trace"); got != "on" {
+		t.Error("Unexpected X-Trace:", got)
+	}
+
+	if err := c.Set("outbound.extra", "Cache-Control: no-cache, no-store"); err != nil {
+		t.Fatal(err)
+	}
+	if got := extra.Values("Cache-Control"); !reflect.DeepEqual(got, []string{"no-cache, no-store"}) {
+		t.Error("Unexpected Cache-Control values:", got)
+	}
+
+	err = c.parseBytes("http", []byte(`
+[outbound]
+extra = ["Bad Header: x"]
+`))
+	if err == nil || err.Error() != `outbound.extra[0] is not a valid header: "Bad Header" is not a valid header name at http:3` {
+		t.Error("Expected an invalid header name error, got", err)
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ParseIncremental loads a TOML file like Parse, but decodes and applies it a
+// section at a time as it's read instead of decoding the whole document
+// first, so that very large generated files, such as ones with thousands of
+// [[upstreams]] entries for a Wildcard, load without holding the document
+// and its whole tree in memory. The tables loaded by a single config, such
+// as a KeyValueList or one element of a Wildcard, are always decoded
+// together.
+//
+// Since the document isn't kept, its settings aren't seen by Tree, Provided,
+// ProvidedKeys, or Table. Files compressed with gzip are decompressed, but a
+// FileDecrypter isn't used.
+func (c *ConfigSet) ParseIncremental(path string) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseIncremental(path) }
+	key := loadKey("incremental", path)
+	if c.unchanged(key) {
+		return nil
+	}
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	c.cache.key = key
+
+	f, err := os.Open(path)
+	if err != nil {
+		return c.fileError(path, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	c.recordState(path, fi, nil)
+	c.recordFile(path)
+
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%s can't be decompressed: %s", path, err)
+		}
+		return c.parseReader(path, gz)
+	}
+	return c.parseReader(path, r)
+}
+
+// errReaderReload is returned by Reload after ParseReader, since the reader
+// can't be read again.
+var errReaderReload = errors.New("config: a document loaded by ParseReader can't be reloaded")
+
+// ParseReader loads the TOML document read from r a section at a time, as
+// ParseIncremental does. The name is used in errors and in Origin. Reload
+// can't load the document again, and returns an error.
+func (c *ConfigSet) ParseReader(name string, r io.Reader) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return errReaderReload }
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	return c.parseReader(name, r)
+}
+
+// sectionSize is the size at which a section ends, at the next table header
+// that can start a new one. Decoding a few tables at a time, rather than each
+// on its own, saves the decoder's overhead.
+var sectionSize = 64 << 10
+
+// section is a part of a document that's decoded on its own.
+type section struct {
+	buf bytes.Buffer
+	// line is the line the section starts on, counting from 1.
+	line int
+	// wildcards are the Wildcards with tables in the section.
+	wildcards []*wildcardValue
+}
+
+// parseReader reads a document a line at a time, collecting lines into
+// sections and loading each section when the next one starts. A section can
+// only end at a table header, and not in the middle of the tables loaded by a
+// single config, such as a KeyValueList or one element of a Wildcard.
+func (c *ConfigSet) parseReader(name string, r io.Reader) error {
+	c.startSource(name)
+	sum := sha256.New()
+	var hash io.Writer = sum
+	if c.hash != nil {
+		hash = io.MultiWriter(sum, c.hash)
+	}
+	var size int64
+	defer func() {
+		c.stats.FileSize = size
+		c.stats.FileHash = hex.EncodeToString(sum.Sum(nil))
+	}()
+
+	br := bufio.NewReader(io.TeeReader(r, hash))
+	started := make(map[*wildcardValue]bool)
+	cur := &section{line: 1}
+	var (
+		value   valueScanner
+		inValue bool
+		long    []byte
+
+		// The table config of the last table header that could have
+		// started a section.
+		group    string
+		wildcard *wildcardValue
+		element  bool
+	)
+	for line := 1; ; line++ {
+		data, err := br.ReadSlice('\n')
+		for err == bufio.ErrBufferFull {
+			long = append(long, data...)
+			data, err = br.ReadSlice('\n')
+		}
+		if len(long) > 0 {
+			data = append(long, data...)
+			long = long[:0]
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		size += int64(len(data))
+		if err := c.checkSize(name, int(size)); err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(string(data))
+		switch {
+		case inValue:
+			inValue = !value.scan(string(data))
+		case strings.HasPrefix(trimmed, "["):
+			keys, array, ok := parseHeader(trimmed)
+			if !ok {
+				break
+			}
+			g, w, e := c.sectionGroup(keys, array)
+			// Subtables of an element of an array of tables belong to that
+			// element.
+			continues := !e && g != "" && (g == group || w != nil && w == wildcard && element)
+			if !continues {
+				if cur.buf.Len() >= sectionSize {
+					if err := c.loadSection(name, cur, started); err != nil {
+						return err
+					}
+					cur = &section{line: line}
+				}
+				group, wildcard, element = g, w, e
+			}
+			if w != nil && (len(cur.wildcards) == 0 || cur.wildcards[len(cur.wildcards)-1] != w) {
+				cur.wildcards = append(cur.wildcards, w)
+			}
+		case trimmed != "" && trimmed[0] != '#':
+			if _, v, ok := splitKeyValue(trimmed); ok {
+				value = valueScanner{}
+				inValue = !value.scan(v)
+			}
+		}
+		cur.buf.Write(data)
+
+		if err == io.EOF {
+			return c.loadSection(name, cur, started)
+		}
+	}
+}
+
+// sectionGroup returns the name of the table config that loads the table
+// with the given header, if any. For a Wildcard, it returns the name of the
+// element the table belongs to, the Wildcard, and whether the header starts
+// a new element of an array of tables.
+func (c *ConfigSet) sectionGroup(keys []string, array bool) (group string, w *wildcardValue, element bool) {
+	for i := 1; i <= len(keys); i++ {
+		name := strings.Join(keys[:i], ".")
+		f := c.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if _, ok := f.Value.(tableValue); !ok {
+			continue
+		}
+		w, _ := f.Value.(*wildcardValue)
+		if w == nil || i == len(keys) {
+			return name, w, w != nil && array
+		}
+		return name + "." + keys[i], w, false
+	}
+	return "", nil, false
+}
+
+// loadSection decodes a section and loads it into the ConfigSet's config
+// variables. Sections after the first with tables for a Wildcard add to its
+// tables.
+func (c *ConfigSet) loadSection(name string, s *section, started map[*wildcardValue]bool) error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	start := time.Now()
+	tree, err := loadTOML(s.buf.Bytes())
+	c.stats.DecodeTime += time.Since(start)
+	if err != nil {
+		return fmt.Errorf("%s:%d: the section starting here is not valid TOML. See https://github.com/mojombo/toml", name, s.line)
+	}
+
+	c.lineOffset = s.line - 1
+	defer func() { c.lineOffset = 0 }()
+	for _, w := range s.wildcards {
+		w.appending = started[w]
+	}
+	err = c.loadTomlTree(tree, []string{})
+	for _, w := range s.wildcards {
+		w.appending = false
+		started[w] = true
+	}
+	return err
+}
+
+// ParseIncremental loads a TOML file into the global config a section at a
+// time as it's read.
+func ParseIncremental(path string) error {
+	return handleGlobalError(global().ParseIncremental(path))
+}
+
+// ParseReader loads the TOML document read from r into the global config a
+// section at a time.
+func ParseReader(name string, r io.Reader) error {
+	return handleGlobalError(global().ParseReader(name, r))
+}
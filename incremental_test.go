@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const incrementalDocument = `name = "app"
+motd = """
+[not a header]
+"""
+
+[[upstreams]]
+host = "a.internal"
+[upstreams.tls]
+enabled = true
+
+[[upstreams]]
+port = 8080
+
+[server]
+addr = ":80"
+
+[tenants.acme]
+weight = 5
+[tenants.globex]
+
+[headers]
+X-One = "1"
+X-Two = "2"
+`
+
+// defineIncremental defines the configs set by incrementalDocument.
+func defineIncremental(c *ConfigSet, hosts map[string]*string, tls map[string]*bool, weights map[string]*int) {
+	c.String("name", "")
+	c.String("motd", "")
+	c.String("server.addr", "")
+	c.KeyValueList("headers")
+	c.Wildcard("upstreams.*", func(name string, sub *ConfigSet) {
+		hosts[name] = sub.String("host", "localhost")
+		sub.Int("port", 80)
+		tls[name] = sub.Bool("tls.enabled", false)
+	})
+	c.Wildcard("tenants.*", func(name string, sub *ConfigSet) {
+		weights[name] = sub.Int("weight", 1)
+	})
+}
+
+func TestParseReader(t *testing.T) {
+	defer func(size int) { sectionSize = size }(sectionSize)
+	for _, sectionSize = range []int{1, 1 << 16} {
+		testParseReader(t)
+	}
+}
+
+func testParseReader(t *testing.T) {
+	c := NewConfigSet("incremental", ContinueOnError)
+	hosts, tls, weights := make(map[string]*string), make(map[string]*bool), make(map[string]*int)
+	defineIncremental(c, hosts, tls, weights)
+
+	if err := c.ParseReader("app.conf", strings.NewReader(incrementalDocument)); err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || *hosts["0"] != "a.internal" || *hosts["1"] != "localhost" || !*tls["0"] || *tls["1"] {
+		t.Errorf("Unexpected upstreams: %v %v", hosts, tls)
+	}
+	if len(weights) != 2 || *weights["acme"] != 5 || *weights["globex"] != 1 {
+		t.Errorf("Unexpected tenants: %v", weights)
+	}
+	if c.Lookup("motd").Value.String() != "[not a header]\n" || c.Lookup("headers").Value.String() != "X-One=1,X-Two=2" {
+		t.Errorf("Unexpected settings: %q %q", c.Lookup("motd").Value, c.Lookup("headers").Value)
+	}
+	if origin := c.Origin("server.addr"); origin != "app.conf:15" {
+		t.Error("Unexpected origin:", origin)
+	}
+	if c.Stats().FileSize != int64(len(incrementalDocument)) {
+		t.Error("Unexpected size:", c.Stats().FileSize)
+	}
+	if err := c.Reload(); err != errReaderReload {
+		t.Error("Unexpected reload error:", err)
+	}
+
+	line := 1
+	if sectionSize == 1 {
+		line = 2
+	}
+	err := c.ParseReader("app.conf", strings.NewReader("name = \"app\"\n[server]\naddr = \n"))
+	expected := fmt.Sprintf("app.conf:%d: the section starting here is not valid TOML. See https://github.com/mojombo/toml", line)
+	if err == nil || err.Error() != expected {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestParseIncremental(t *testing.T) {
+	dir, err := ioutil.TempDir("", "incremental")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(incrementalDocument))
+	w.Close()
+	path := filepath.Join(dir, "app.conf.gz")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfigSet("incremental", ContinueOnError)
+	hosts, tls, weights := make(map[string]*string), make(map[string]*bool), make(map[string]*int)
+	defineIncremental(c, hosts, tls, weights)
+	if err := c.ParseIncremental(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || len(weights) != 2 || c.Origin("name") != path+":1" {
+		t.Errorf("Unexpected settings: %v %v %s", hosts, weights, c.Origin("name"))
+	}
+	if err := c.ParseIncremental(filepath.Join(dir, "missing.conf")); !os.IsNotExist(err) {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+// writeLargeConfig writes a config file with n [[upstreams]] entries and
+// returns its path.
+func writeLargeConfig(b *testing.B, n int) string {
+	var buf bytes.Buffer
+	buf.WriteString("name = \"large\"\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "\n[[upstreams]]\nhost = \"host%d.internal\"\nport = %d\n[upstreams.tls]\nenabled = true\n", i, 8000+i%1000)
+	}
+	f, err := ioutil.TempFile("", "large")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(buf.Len()))
+	return f.Name()
+}
+
+func benchmarkLargeConfig(b *testing.B, parse func(c *ConfigSet, path string) error) {
+	path := writeLargeConfig(b, 10000)
+	defer os.Remove(path)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewConfigSet("large", ContinueOnError)
+		hosts, tls, weights := make(map[string]*string), make(map[string]*bool), make(map[string]*int)
+		defineIncremental(c, hosts, tls, weights)
+		if err := parse(c, path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseLarge(b *testing.B) {
+	benchmarkLargeConfig(b, func(c *ConfigSet, path string) error { return c.Parse(path) })
+}
+
+func BenchmarkParseIncrementalLarge(b *testing.B) {
+	benchmarkLargeConfig(b, (*ConfigSet).ParseIncremental)
+}
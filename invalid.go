@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+)
+
+// InvalidValues is the policy for values a config variable can't hold, such
+// as a string given for an integer.
+type InvalidValues int
+
+const (
+	// InvalidValuesError fails the load. It's the default.
+	InvalidValuesError InvalidValues = iota
+	// InvalidValuesWarn reports the value with the ConfigSet's logger and
+	// leaves the config at the value it had, which is its default unless an
+	// earlier source set it, so that a bad setting for a non-critical tunable
+	// doesn't stop the program from starting.
+	InvalidValuesWarn
+)
+
+// SetInvalidValues sets the policy for values that config variables can't
+// hold. Unknown keys and missing files are still errors.
+func (c *ConfigSet) SetInvalidValues(policy InvalidValues) {
+	c.invalidValues = policy
+}
+
+// rejectValue handles a value that the named config couldn't be set to. It
+// returns err unless the policy is InvalidValuesWarn, in which case the
+// config is put back with restore and the value is reported instead. If the
+// config can't be put back, err is returned after all.
+func (c *ConfigSet) rejectValue(name string, value interface{}, pos string, err error, restore func() error) error {
+	if restore == nil {
+		return err
+	}
+	if restore() != nil {
+		return err
+	}
+
+	if pos == "" {
+		pos = c.loading
+	}
+	got := fmt.Sprintf("%T %#v", value, value)
+	if c.secrets[name] {
+		got = fmt.Sprintf("%T %s", value, redacted)
+	}
+	c.warnf("%s: %s (got %s); leaving %s at %s", pos, err, got, name, c.Lookup(name).Value)
+	return nil
+}
+
+// SetInvalidValues sets the policy for values that config variables can't
+// hold.
+func SetInvalidValues(policy InvalidValues) {
+	global().SetInvalidValues(policy)
+}
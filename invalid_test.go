@@ -0,0 +1,53 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestInvalidValuesWarn(t *testing.T) {
+	c := NewConfigSet("invalid", ContinueOnError)
+	port := c.Int("port", 8080)
+	name := c.String("name", "")
+	timeout := c.Duration("timeout", 0)
+
+	doc := []byte("port = \"eighty\"\nname = \"app\"\ntimeout = \"5 minutes\"\n")
+	if err := c.parseBytes("app.conf", doc); err == nil {
+		t.Fatal("Invalid values should be errors by default")
+	}
+
+	c = NewConfigSet("invalid", ContinueOnError)
+	port = c.Int("port", 8080)
+	name = c.String("name", "")
+	timeout = c.Duration("timeout", 0)
+	var out bytes.Buffer
+	c.SetLogger(log.New(&out, "", 0))
+	c.SetInvalidValues(InvalidValuesWarn)
+	if err := c.parseBytes("app.conf", []byte("port = 9090\ntimeout = \"5s\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.parseBytes("app.conf", doc); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9090 || *name != "app" || timeout.String() != "5s" {
+		t.Errorf("Unexpected settings: %d %q %s", *port, *name, timeout)
+	}
+	warnings := strings.SplitAfter(out.String(), "\n")
+	sort.Strings(warnings)
+	expected := `config: app.conf:1: The value for port is invalid (got string "eighty"); leaving port at 9090
+config: app.conf:3: The value for timeout is invalid (got string "5 minutes"); leaving timeout at 5s
+`
+	if strings.Join(warnings, "") != expected {
+		t.Errorf("Unexpected warnings:\n%s", out.String())
+	}
+	if c.Origin("port") != "app.conf:1" {
+		t.Error("Unexpected origin:", c.Origin("port"))
+	}
+
+	if err := c.parseBytes("app.conf", []byte("missing = 1\n")); err == nil {
+		t.Error("Unknown keys should still be errors")
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// -- JSON Value
+
+type jsonValue struct {
+	p   interface{}
+	raw string
+}
+
+// Set unmarshals s into a fresh value and only assigns it to p if it's valid,
+// so a bad value leaves the previous one intact.
+func (j *jsonValue) Set(s string) error {
+	dest := reflect.ValueOf(j.p).Elem()
+	v := reflect.New(dest.Type())
+	if err := json.Unmarshal([]byte(s), v.Interface()); err != nil {
+		return err
+	}
+	dest.Set(v.Elem())
+	j.raw = s
+	return nil
+}
+
+func (j *jsonValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return j.Set(s)
+	}
+	return errParse
+}
+
+func (j *jsonValue) Get() interface{} { return j.p }
+
+func (j *jsonValue) snapshot() func() error {
+	dest := reflect.ValueOf(j.p).Elem()
+	v := reflect.New(dest.Type()).Elem()
+	v.Set(dest)
+	raw := j.raw
+	return func() error {
+		dest.Set(v)
+		j.raw = raw
+		return nil
+	}
+}
+
+func (j *jsonValue) String() string { return j.raw }
+
+// JSONVar defines a config with a given name and default value for a
+// ConfigSet whose TOML value is a string containing JSON. The JSON is
+// unmarshaled into p, which must be a non-nil pointer, when the config is
+// loaded. It panics if the default isn't valid JSON for p.
+func (c *ConfigSet) JSONVar(p interface{}, name string, value string) {
+	if v := reflect.ValueOf(p); v.Kind() != reflect.Ptr || v.IsNil() {
+		panic(fmt.Sprintf("config: JSONVar for %s requires a non-nil pointer", name))
+	}
+	j := &jsonValue{p: p}
+	if err := j.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(j, name, "")
+}
+
+// JSONVar defines a config with a given name and default value whose TOML
+// value is a string containing JSON, which is unmarshaled into p.
+func JSONVar(p interface{}, name string, value string) {
+	global().JSONVar(p, name, value)
+}
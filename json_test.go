@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestJSONVar(t *testing.T) {
+	var client struct {
+		ID     string   `json:"client_id"`
+		Scopes []string `json:"scopes"`
+	}
+	c := NewConfigSet("json", ContinueOnError)
+	c.JSONVar(&client, "oauth.client", `{"client_id": "default"}`)
+
+	if client.ID != "default" {
+		t.Fatal("client should have the default ID, has", client.ID)
+	}
+
+	err := c.parseBytes("json", []byte(`
+[oauth]
+client = '{"client_id": "abc", "scopes": ["read"]}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.ID != "abc" || len(client.Scopes) != 1 || client.Scopes[0] != "read" {
+		t.Errorf("Unexpected client: %#v", client)
+	}
+
+	err = c.parseBytes("json", []byte(`
+[oauth]
+client = '{"client_id": '
+`))
+	if err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	if client.ID != "abc" {
+		t.Error("client should be unchanged after an invalid value, is", client.ID)
+	}
+}
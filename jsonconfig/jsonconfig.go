@@ -0,0 +1,66 @@
+// Package jsonconfig decodes config files written in JSON, for programs that
+// share their configuration with tools that don't speak TOML:
+//
+//	config.RegisterDecoder(".json", jsonconfig.Decoder{})
+//	err := config.Parse("/etc/myapp/myapp.json")
+//
+// Objects are loaded as tables, so {"db": {"port": 5432}} sets the config
+// "db.port".
+package jsonconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/stvp/go-toml-config"
+)
+
+// Decoder decodes JSON documents. It implements config.Decoder.
+type Decoder struct{}
+
+var _ config.Decoder = Decoder{}
+
+// Decode returns the settings in a JSON document, whose top level must be an
+// object. Numbers are decoded as int64 if they're integers and as float64
+// otherwise, as they would be in TOML.
+func (Decoder) Decode(data []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, errors.New("unexpected data after the top-level object")
+	}
+	values, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the top level isn't an object")
+	}
+	return convert(values).(map[string]interface{}), nil
+}
+
+// convert replaces json.Number values with int64 or float64 values.
+func convert(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = convert(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = convert(elem)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return fmt.Sprint(v)
+	}
+	return value
+}
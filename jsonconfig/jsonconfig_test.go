@@ -0,0 +1,45 @@
+package jsonconfig
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stvp/go-toml-config"
+)
+
+func TestDecoder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	doc := `{"name": "app", "db": {"port": 5432, "ratio": 0.5, "hosts": ["a", "b"]}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.json"), []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "override.conf"), []byte("name = \"override\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.NewConfigSet("json", flag.ContinueOnError)
+	name := c.String("name", "")
+	port := c.Int("db.port", 0)
+	ratio := c.Float64("db.ratio", 0)
+	hosts := c.StringSet("db.hosts", nil)
+	c.RegisterDecoder(".json", Decoder{})
+	if err := c.ParseDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "override" || *port != 5432 || *ratio != 0.5 || len(*hosts) != 2 {
+		t.Errorf("Unexpected settings: %q %d %v %v", *name, *port, *ratio, *hosts)
+	}
+
+	for _, doc := range []string{`[1]`, `{"a": 1} {}`, `{`} {
+		if _, err := (Decoder{}).Decode([]byte(doc)); err == nil {
+			t.Errorf("%s should be rejected", doc)
+		}
+	}
+}
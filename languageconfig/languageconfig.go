@@ -0,0 +1,61 @@
+// Package languageconfig defines config variables holding BCP 47 language
+// tags, such as "pt-BR", parsed with golang.org/x/text/language. It's kept
+// out of the config package so that programs that don't use it don't depend
+// on golang.org/x/text:
+//
+//	locale := languageconfig.Tag(c, "i18n.locale", "en-US")
+//
+// With the global ConfigSet, define the config with config.Var:
+//
+//	var locale language.Tag
+//	config.Var(languageconfig.NewValue(&locale, "en-US"), "i18n.locale", "")
+package languageconfig
+
+import (
+	"fmt"
+
+	"github.com/stvp/go-toml-config"
+	"golang.org/x/text/language"
+)
+
+// Value is a config value holding a language tag. It implements flag.Value.
+type Value language.Tag
+
+// NewValue returns a Value that stores its tag in p, which is set to the
+// default value. It panics if the default isn't a valid tag.
+func NewValue(p *language.Tag, value string) *Value {
+	v := (*Value)(p)
+	if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("languageconfig: invalid default: %s", err))
+	}
+	return v
+}
+
+func (l *Value) Set(s string) error {
+	tag, err := language.Parse(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid BCP 47 language tag", s)
+	}
+	*l = Value(tag)
+	return nil
+}
+
+func (l *Value) Get() interface{} { return language.Tag(*l) }
+
+func (l *Value) String() string { return language.Tag(*l).String() }
+
+// TagVar defines a BCP 47 language tag config with a given name and default
+// value for a ConfigSet. The argument p points to a language.Tag variable in
+// which to store the value of the config. It panics if the default isn't a
+// valid tag.
+func TagVar(c *config.ConfigSet, p *language.Tag, name string, value string) {
+	c.Var(NewValue(p, value), name, "")
+}
+
+// Tag defines a BCP 47 language tag config variable with a given name and
+// default value for a ConfigSet.
+func Tag(c *config.ConfigSet, name string, value string) *language.Tag {
+	p := new(language.Tag)
+	TagVar(c, p, name, value)
+	return p
+}
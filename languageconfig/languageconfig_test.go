@@ -0,0 +1,41 @@
+package languageconfig
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stvp/go-toml-config"
+	"golang.org/x/text/language"
+)
+
+func TestTag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "languageconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+
+	c := config.NewConfigSet("language", flag.ContinueOnError)
+	locale := Tag(c, "i18n.locale", "en-US")
+	if *locale != language.AmericanEnglish {
+		t.Fatal("i18n.locale should default to en-US, is", locale)
+	}
+
+	ioutil.WriteFile(path, []byte("[i18n]\nlocale = \"pt-BR\"\n"), 0600)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if *locale != language.BrazilianPortuguese {
+		t.Error("i18n.locale should be pt-BR, is", locale)
+	}
+
+	ioutil.WriteFile(path, []byte("[i18n]\nlocale = \"portuguese!\"\n"), 0600)
+	err = c.Parse(path)
+	if err == nil || err.Error() != `"portuguese!" is not a valid BCP 47 language tag` {
+		t.Error("Expected an invalid tag error, got", err)
+	}
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Layers describes the configuration sources loaded by ParseLayers. Sources
+// are applied in field order, so later layers override earlier ones.
+type Layers struct {
+	// Baseline is a TOML document that is always loaded first, usually the
+	// contents of a file embedded in the binary with go:embed.
+	Baseline []byte
+
+	// System is the path to a system-wide config file, such as
+	// "/etc/myapp/myapp.conf". It is skipped if empty or missing.
+	System string
+
+	// User is the path to a per-user config file, such as
+	// "~/.config/myapp/myapp.conf". It is skipped if empty or missing. A
+	// leading "~" and $VAR in either path are expanded, as for Path configs.
+	User string
+
+	// Providers are loaded after the user config file, in order.
+	Providers []Provider
+
+	// Deadline limits the time ParseLayers spends loading, measured from
+	// when it's called. A provider that hasn't loaded by the deadline fails
+	// as if it had timed out. Zero means no limit. Use WithTimeout to limit
+	// the time taken by a single provider.
+	Deadline time.Duration
+
+	// OptionalProviders makes a provider that fails to load or times out
+	// provide no settings, leaving configs at the values from earlier layers
+	// or their defaults, instead of failing ParseLayers. The error is reported
+	// through the ConfigSet's logger.
+	OptionalProviders bool
+
+	// EnvPrefix enables overrides from environment variables. A config named
+	// "section.name" is overridden by the variable PREFIX_SECTION_NAME. If it
+	// is empty, the prefix given to SetEnvPrefix is used, and if that is empty
+	// too, only the variables given to BindEnv are consulted.
+	EnvPrefix string
+}
+
+// LayerError is returned by ParseLayers when one of the layers fails to load.
+type LayerError struct {
+	// Layer is one of "baseline", "system", "user", "env", or the name of a
+	// Provider.
+	Layer string
+	// Path is the file the layer was loaded from, if any.
+	Path string
+	Err  error
+}
+
+func (e *LayerError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("%s config %s: %s", e.Layer, e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s config: %s", e.Layer, e.Err)
+}
+
+// ParseLayers loads the embedded baseline, then the system config file, then
+// the user config file, then any providers, then any environment variable
+// overrides. Missing system or user files are not an error. If a layer fails
+// to load, a *LayerError identifying it is returned and later layers are not
+// loaded.
+func (c *ConfigSet) ParseLayers(layers Layers) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseLayers(layers) }
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+
+	if layers.Baseline != nil {
+		if err := c.parseBytes("baseline", layers.Baseline); err != nil {
+			return &LayerError{Layer: "baseline", Err: err}
+		}
+	}
+
+	for _, layer := range []struct{ name, path string }{
+		{"system", layers.System},
+		{"user", layers.User},
+	} {
+		if layer.path == "" {
+			continue
+		}
+		path, err := expandPath(layer.path)
+		if err != nil {
+			return &LayerError{Layer: layer.name, Path: layer.path, Err: err}
+		}
+		configBytes, err := c.readFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil {
+			c.recordFile(path)
+			err = c.parseBytes(path, configBytes)
+		}
+		if err != nil {
+			return &LayerError{Layer: layer.name, Path: path, Err: err}
+		}
+	}
+
+	for _, p := range layers.Providers {
+		var err error
+		timeout := layers.Deadline - time.Since(start)
+		if layers.Deadline == 0 {
+			err = c.loadProvider(p, 0)
+		} else if timeout > 0 {
+			err = c.loadProvider(p, timeout)
+		} else {
+			err = fmt.Errorf("the %s deadline passed before loading", layers.Deadline)
+		}
+		if err != nil && layers.OptionalProviders {
+			c.warnf("%s: %s; using the settings from other layers", p.Name(), err)
+			continue
+		}
+		if err != nil {
+			return &LayerError{Layer: p.Name(), Err: err}
+		}
+	}
+
+	prefix := layers.EnvPrefix
+	if prefix == "" {
+		prefix = c.envPrefix
+	}
+	if err := c.loadEnv(prefix); err != nil {
+		return &LayerError{Layer: "env", Err: err}
+	}
+
+	return nil
+}
+
+// SetEnvPrefix sets the prefix of the environment variables that override
+// config variables when no other prefix is given, as by ParseEnvOnly. A
+// config named "section.name" is overridden by PREFIX_SECTION_NAME.
+func (c *ConfigSet) SetEnvPrefix(prefix string) {
+	c.envPrefix = prefix
+}
+
+// BindEnv sets the environment variables that override the named config
+// variable, in place of the one named after the prefix. If several are set,
+// the first one listed wins. It panics if the config hasn't been defined.
+func (c *ConfigSet) BindEnv(name string, vars ...string) {
+	if c.Lookup(c.prefix+name) == nil {
+		panic(fmt.Sprintf("config: can't bind undefined config %s", c.prefix+name))
+	}
+	c.envBindings[c.prefix+name] = vars
+}
+
+// ParseEnvOnly sets config variables from environment variables alone, for
+// deployments that have no config file, such as containers. Each config is
+// set from the variables given to BindEnv, or else from the variable named
+// after the prefix given to SetEnvPrefix. Configs with no variable set keep
+// their defaults.
+func (c *ConfigSet) ParseEnvOnly() (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = c.ParseEnvOnly
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	return c.loadEnv(c.envPrefix)
+}
+
+// loadEnv overrides config variables with any matching environment variables.
+// Variables named after the prefix are only consulted if it isn't empty.
+func (c *ConfigSet) loadEnv(prefix string) error {
+	c.startSource("env")
+	var err error
+	c.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		vars, bound := c.envBindings[f.Name]
+		if !bound {
+			if prefix == "" {
+				return
+			}
+			vars = []string{envName(prefix, f.Name)}
+		}
+		for _, name := range vars {
+			if value, ok := os.LookupEnv(name); ok {
+				err = c.applyValue(f.Name, value, "")
+				return
+			}
+		}
+	})
+	return err
+}
+
+// envName returns the environment variable that overrides the config with the
+// given name, e.g. "MYAPP_SECTION_NAME" for "section.name".
+func envName(prefix, name string) string {
+	name = strings.ToUpper(strings.Replace(name, ".", "_", -1))
+	return strings.ToUpper(prefix) + "_" + name
+}
+
+// Environ returns the effective value of every config variable as an
+// environment variable named after prefix, such as
+// "MYAPP_SECTION_NAME=value" for "section.name", sorted by name, so that the
+// resolved configuration can be passed to child processes and shell hooks
+// that only understand the environment. The result can be appended to
+// os.Environ() and set as an exec.Cmd's Env. Configs given to BindEnv use the
+// first variable bound to them instead, so a child using this package with
+// the same prefix and bindings loads the same settings.
+func (c *ConfigSet) Environ(prefix string) []string {
+	var env []string
+	c.VisitAll(func(f *flag.Flag) {
+		name := envName(prefix, f.Name)
+		if vars := c.envBindings[f.Name]; len(vars) > 0 {
+			name = vars[0]
+		}
+		env = append(env, name+"="+f.Value.String())
+	})
+	return env
+}
+
+// SetEnvPrefix sets the prefix of the environment variables that override
+// config variables when no other prefix is given.
+func SetEnvPrefix(prefix string) {
+	global().SetEnvPrefix(prefix)
+}
+
+// BindEnv sets the environment variables that override the named config
+// variable.
+func BindEnv(name string, vars ...string) {
+	global().BindEnv(name, vars...)
+}
+
+// ParseEnvOnly sets config variables from environment variables alone.
+func ParseEnvOnly() error {
+	return handleGlobalError(global().ParseEnvOnly())
+}
+
+// Environ returns the effective value of every config variable in the global
+// config as an environment variable named after prefix.
+func Environ(prefix string) []string {
+	return global().Environ(prefix)
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLayers(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.conf")
+	if err := os.WriteFile(userPath, []byte("port = 9000\nhost = \"user\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MYAPP_HOST", "env")
+
+	c := NewConfigSet("layers", ContinueOnError)
+	name := c.String("name", "default")
+	host := c.String("host", "default")
+	port := c.Int("port", 0)
+
+	err := c.ParseLayers(Layers{
+		Baseline:  []byte("name = \"baseline\"\nport = 80\n"),
+		System:    filepath.Join(dir, "missing.conf"),
+		User:      userPath,
+		EnvPrefix: "myapp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *name != "baseline" {
+		t.Error("name should be \"baseline\", is", *name)
+	}
+	if *port != 9000 {
+		t.Error("port should be 9000, is", *port)
+	}
+	if *host != "env" {
+		t.Error("host should be \"env\", is", *host)
+	}
+}
+
+func TestParseLayersExpandsPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("MYAPP_ETC", home)
+	if err := os.MkdirAll(filepath.Join(home, ".config", "myapp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".config", "myapp", "myapp.conf"), []byte("port = 9000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "system.conf"), []byte("host = \"system\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfigSet("layers", ContinueOnError)
+	host := c.String("host", "default")
+	port := c.Int("port", 0)
+
+	err := c.ParseLayers(Layers{
+		System: "$MYAPP_ETC/system.conf",
+		User:   "~/.config/myapp/myapp.conf",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *host != "system" || *port != 9000 {
+		t.Errorf("Unexpected values: %q %d", *host, *port)
+	}
+
+	err = c.ParseLayers(Layers{User: "$MYAPP_MISSING/myapp.conf"})
+	if layerErr, ok := err.(*LayerError); !ok || layerErr.Layer != "user" {
+		t.Error("Expected user layer error, got", err)
+	}
+}
+
+func TestParseLayersError(t *testing.T) {
+	c := NewConfigSet("layers", ContinueOnError)
+	c.Int("port", 0)
+
+	err := c.ParseLayers(Layers{System: INVALID_CONFIG_PATH})
+	layerErr, ok := err.(*LayerError)
+	if !ok {
+		t.Fatalf("Expected a *LayerError, got %#v", err)
+	}
+	if layerErr.Layer != "system" || layerErr.Path != INVALID_CONFIG_PATH {
+		t.Errorf("Unexpected layer error: %#v", layerErr)
+	}
+
+	t.Setenv("MYAPP_PORT", "eighty")
+	err = c.ParseLayers(Layers{EnvPrefix: "MYAPP"})
+	if layerErr, ok := err.(*LayerError); !ok || layerErr.Layer != "env" {
+		t.Error("Expected env layer error, got", err)
+	}
+}
+
+func TestParseEnvOnly(t *testing.T) {
+	c := NewConfigSet("env", ContinueOnError)
+	port := c.Int("server.port", 8080)
+	host := c.String("server.host", "localhost")
+	url := c.String("db.url", "")
+	c.SetEnvPrefix("envonly")
+	c.BindEnv("db.url", "ENVONLY_DATABASE_URL", "DATABASE_URL")
+
+	os.Setenv("ENVONLY_SERVER_PORT", "9000")
+	os.Setenv("DATABASE_URL", "postgres://fallback")
+	os.Setenv("ENVONLY_DB_URL", "ignored")
+	defer os.Unsetenv("ENVONLY_SERVER_PORT")
+	defer os.Unsetenv("DATABASE_URL")
+	defer os.Unsetenv("ENVONLY_DB_URL")
+
+	if err := c.ParseEnvOnly(); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || *host != "localhost" {
+		t.Error("Unexpected server settings:", *port, *host)
+	}
+	if *url != "postgres://fallback" {
+		t.Error("db.url should come from a bound variable, is", *url)
+	}
+
+	os.Setenv("ENVONLY_DATABASE_URL", "postgres://primary")
+	defer os.Unsetenv("ENVONLY_DATABASE_URL")
+	if err := c.ParseLayers(Layers{Baseline: []byte("[db]\nurl = \"postgres://file\"\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if *url != "postgres://primary" {
+		t.Error("The first bound variable should win, is", *url)
+	}
+
+	os.Setenv("ENVONLY_SERVER_PORT", "http")
+	if err := c.ParseEnvOnly(); err == nil || err.Error() != "The value for server.port is invalid" {
+		t.Error("Expected an invalid value error, got", err)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	c := NewConfigSet("environ", ContinueOnError)
+	c.Int("server.port", 8080)
+	c.String("db.url", "")
+	c.StringSet("hosts", nil)
+	c.BindEnv("db.url", "DATABASE_URL")
+	err := c.parseBytes("environ", []byte("hosts = [\"a\", \"b\"]\n[db]\nurl = \"postgres://db\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := c.Environ("myapp")
+	expected := []string{"DATABASE_URL=postgres://db", "MYAPP_HOSTS=a,b", "MYAPP_SERVER_PORT=8080"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Error("Unexpected environment:", env)
+	}
+}
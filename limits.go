@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Limits bounds the resources used to load configuration, so that a config
+// file that's accidentally huge or deeply nested fails to load with a clear
+// error instead of exhausting the service's memory at startup. A zero field
+// means no limit.
+type Limits struct {
+	// MaxFileSize is the largest config file or document, in bytes, that
+	// will be loaded.
+	MaxFileSize int64
+
+	// MaxKeys is the largest number of keys, counting tables, that a single
+	// load may contain across all of its documents.
+	MaxKeys int
+
+	// MaxDepth is the deepest that tables may be nested, so a key such as
+	// "a.b.c" is at depth 3.
+	MaxDepth int
+
+	// MaxIncludeDepth is the deepest that documents in a bundle loaded by
+	// ParseBundle may include one another, so a document included by the
+	// main document is at depth 1.
+	MaxIncludeDepth int
+}
+
+// SetLimits sets the limits enforced by Parse and the other load methods.
+// There are no limits by default.
+func (c *ConfigSet) SetLimits(limits Limits) {
+	c.limits = limits
+}
+
+// readLimited reads a file, failing without reading all of it if it's larger
+// than the size limit.
+// The file's state is recorded for the load cache.
+func (c *ConfigSet) readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = f
+	if c.limits.MaxFileSize > 0 {
+		r = io.LimitReader(f, c.limits.MaxFileSize+1)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSize(path, len(data)); err != nil {
+		return nil, err
+	}
+	c.recordState(path, fi, data)
+	return data, nil
+}
+
+// checkSize returns an error if a document of size bytes is over the limit.
+func (c *ConfigSet) checkSize(name string, size int) error {
+	if max := c.limits.MaxFileSize; max > 0 && int64(size) > max {
+		return fmt.Errorf("%s is larger than the limit of %d bytes", name, max)
+	}
+	return nil
+}
+
+// checkKey counts the key at path being loaded and returns an error if it's
+// nested too deeply or the load has too many keys.
+func (c *ConfigSet) checkKey(path []string) error {
+	if max := c.limits.MaxDepth; max > 0 && len(path) > max {
+		return fmt.Errorf("%s in %s is nested deeper than the limit of %d levels", strings.Join(path, "."), c.loading, max)
+	}
+	c.keysLoaded++
+	if max := c.limits.MaxKeys; max > 0 && c.keysLoaded > max {
+		return fmt.Errorf("%s has more than the limit of %d keys", c.loading, max)
+	}
+	return nil
+}
+
+// SetLimits sets the limits enforced when loading the global config.
+func SetLimits(limits Limits) {
+	global().SetLimits(limits)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "big.conf")
+	ioutil.WriteFile(path, []byte("name = \""+strings.Repeat("x", 100)+"\"\n"), 0644)
+
+	c := NewConfigSet("limits", ContinueOnError)
+	c.String("name", "")
+	c.Int("a.b.c", 0)
+	c.Int("a.b.d", 0)
+	c.SetLimits(Limits{MaxFileSize: 64, MaxKeys: 3, MaxDepth: 2})
+
+	if err := c.Parse(path); err == nil || err.Error() != path+" is larger than the limit of 64 bytes" {
+		t.Error("Unexpected error:", err)
+	}
+	err = c.ParseLayers(Layers{Baseline: []byte(strings.Repeat("#", 65))})
+	if err == nil || err.Error() != "baseline config: baseline is larger than the limit of 64 bytes" {
+		t.Error("Unexpected error:", err)
+	}
+
+	err = c.parseBytes("app.conf", []byte("[a.b]\nc = 1\n"))
+	if err == nil || err.Error() != "a.b.c in app.conf is nested deeper than the limit of 2 levels" {
+		t.Error("Unexpected error:", err)
+	}
+
+	c.SetLimits(Limits{MaxKeys: 3})
+	if err := c.ParseLayers(Layers{Baseline: []byte("[a.b]\nc = 1\n")}); err != nil {
+		t.Error("Three keys should be within the limit:", err)
+	}
+	err = c.ParseLayers(Layers{Baseline: []byte("[a.b]\nc = 1\nd = 2\n")})
+	if err == nil || err.Error() != "baseline config: baseline has more than the limit of 3 keys" {
+		t.Error("Unexpected error:", err)
+	}
+}
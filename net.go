@@ -0,0 +1,375 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// -- email Value
+
+type emailValue string
+
+func (e *emailValue) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if _, err := mail.ParseAddress(s); err != nil {
+		return fmt.Errorf("%q is not a valid email address", s)
+	}
+	*e = emailValue(s)
+	return nil
+}
+
+func (e *emailValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return e.Set(s)
+	}
+	return errParse
+}
+
+func (e *emailValue) Get() interface{} { return string(*e) }
+
+func (e *emailValue) snapshot() func() error {
+	v := *e
+	return func() error {
+		*e = v
+		return nil
+	}
+}
+
+func (e *emailValue) String() string { return string(*e) }
+
+// EmailVar defines an email address config with a given name and default
+// value for a ConfigSet. The argument p points to a string variable in which
+// to store the value of the config. Values must be RFC 5322 addresses, such as
+// "ops@example.com" or "Ops <ops@example.com>". An empty default is allowed.
+func (c *ConfigSet) EmailVar(p *string, name string, value string) {
+	v := (*emailValue)(p)
+	if value == "" {
+		*p = ""
+	} else if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// Email defines an email address config variable with a given name and
+// default value for a ConfigSet.
+func (c *ConfigSet) Email(name string, value string) *string {
+	p := new(string)
+	c.EmailVar(p, name, value)
+	return p
+}
+
+// EmailVar defines an email address config with a given name and default
+// value. The argument p points to a string variable in which to store the
+// value of the config.
+func EmailVar(p *string, name string, value string) {
+	global().EmailVar(p, name, value)
+}
+
+// Email defines an email address config variable with a given name and
+// default value.
+func Email(name string, value string) *string {
+	return global().Email(name, value)
+}
+
+// -- net.HardwareAddr Value
+
+type macValue net.HardwareAddr
+
+func (m *macValue) Set(s string) error {
+	v, err := net.ParseMAC(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid MAC address", s)
+	}
+	*m = macValue(v)
+	return nil
+}
+
+func (m *macValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return m.Set(s)
+	}
+	return errParse
+}
+
+func (m *macValue) Get() interface{} { return net.HardwareAddr(*m) }
+
+func (m *macValue) snapshot() func() error {
+	v := slices.Clone(*m)
+	return func() error {
+		*m = v
+		return nil
+	}
+}
+
+func (m *macValue) String() string { return net.HardwareAddr(*m).String() }
+
+// MACVar defines a MAC address config with a given name and default value for
+// a ConfigSet. The argument p points to a net.HardwareAddr variable in which
+// to store the value of the config. Values are parsed with net.ParseMAC. An
+// empty default is allowed.
+func (c *ConfigSet) MACVar(p *net.HardwareAddr, name string, value string) {
+	v := (*macValue)(p)
+	if value == "" {
+		*p = nil
+	} else if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// MAC defines a MAC address config variable with a given name and default
+// value for a ConfigSet.
+func (c *ConfigSet) MAC(name string, value string) *net.HardwareAddr {
+	p := new(net.HardwareAddr)
+	c.MACVar(p, name, value)
+	return p
+}
+
+// MACVar defines a MAC address config with a given name and default value. The
+// argument p points to a net.HardwareAddr variable in which to store the value
+// of the config.
+func MACVar(p *net.HardwareAddr, name string, value string) {
+	global().MACVar(p, name, value)
+}
+
+// MAC defines a MAC address config variable with a given name and default
+// value.
+func MAC(name string, value string) *net.HardwareAddr {
+	return global().MAC(name, value)
+}
+
+// -- net.IP Value
+
+type ipValue net.IP
+
+func (i *ipValue) Set(s string) error {
+	v := net.ParseIP(strings.TrimSpace(s))
+	if v == nil {
+		return fmt.Errorf("%q is not a valid IP address", s)
+	}
+	*i = ipValue(v)
+	return nil
+}
+
+func (i *ipValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return i.Set(s)
+	}
+	return errParse
+}
+
+func (i *ipValue) Get() interface{} { return net.IP(*i) }
+
+func (i *ipValue) snapshot() func() error {
+	v := slices.Clone(*i)
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *ipValue) String() string {
+	if len(*i) == 0 {
+		return ""
+	}
+	return net.IP(*i).String()
+}
+
+// IPVar defines an IP address config with a given name and default value for
+// a ConfigSet. The argument p points to a net.IP variable in which to store
+// the value of the config. Values are IPv4 or IPv6 addresses, such as
+// "10.0.0.1" or "::1", parsed with net.ParseIP.
+func (c *ConfigSet) IPVar(p *net.IP, name string, value net.IP) {
+	*p = value
+	c.Var((*ipValue)(p), name, "")
+}
+
+// IP defines an IP address config variable with a given name and default
+// value for a ConfigSet.
+func (c *ConfigSet) IP(name string, value net.IP) *net.IP {
+	p := new(net.IP)
+	c.IPVar(p, name, value)
+	return p
+}
+
+// IPVar defines an IP address config with a given name and default value. The
+// argument p points to a net.IP variable in which to store the value of the
+// config.
+func IPVar(p *net.IP, name string, value net.IP) {
+	global().IPVar(p, name, value)
+}
+
+// IP defines an IP address config variable with a given name and default
+// value.
+func IP(name string, value net.IP) *net.IP {
+	return global().IP(name, value)
+}
+
+// -- net.IPNet Value
+
+type cidrValue net.IPNet
+
+func (n *cidrValue) Set(s string) error {
+	_, v, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%q is not a valid CIDR network", s)
+	}
+	*n = cidrValue(*v)
+	return nil
+}
+
+func (n *cidrValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return n.Set(s)
+	}
+	return errParse
+}
+
+func (n *cidrValue) Get() interface{} { return (*net.IPNet)(n) }
+
+func (n *cidrValue) snapshot() func() error {
+	v := cidrValue{IP: slices.Clone(n.IP), Mask: slices.Clone(n.Mask)}
+	return func() error {
+		*n = v
+		return nil
+	}
+}
+
+func (n *cidrValue) String() string {
+	if n == nil || n.IP == nil {
+		return ""
+	}
+	return (*net.IPNet)(n).String()
+}
+
+// CIDRVar defines a CIDR network config with a given name and default value
+// for a ConfigSet. The argument p points to a net.IPNet variable in which to
+// store the value of the config. Values are parsed with net.ParseCIDR, and
+// the host bits are cleared, so "10.1.2.3/8" is the network 10.0.0.0/8. An
+// empty default is allowed. It panics if the default isn't a valid network.
+func (c *ConfigSet) CIDRVar(p *net.IPNet, name string, value string) {
+	v := (*cidrValue)(p)
+	if value == "" {
+		*p = net.IPNet{}
+	} else if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// CIDR defines a CIDR network config variable with a given name and default
+// value, such as "10.0.0.0/8", for a ConfigSet.
+func (c *ConfigSet) CIDR(name string, value string) *net.IPNet {
+	p := new(net.IPNet)
+	c.CIDRVar(p, name, value)
+	return p
+}
+
+// CIDRVar defines a CIDR network config with a given name and default value.
+// The argument p points to a net.IPNet variable in which to store the value of
+// the config.
+func CIDRVar(p *net.IPNet, name string, value string) {
+	global().CIDRVar(p, name, value)
+}
+
+// CIDR defines a CIDR network config variable with a given name and default
+// value.
+func CIDR(name string, value string) *net.IPNet {
+	return global().CIDR(name, value)
+}
+
+// -- url.URL Value
+
+type urlValue struct {
+	p       *url.URL
+	schemes []string
+}
+
+func (u *urlValue) Set(s string) error {
+	v, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || v.Scheme == "" {
+		return fmt.Errorf("%q is not an absolute URL", s)
+	}
+	if len(u.schemes) > 0 && !u.allowed(v.Scheme) {
+		return fmt.Errorf("%q does not use %s", s, strings.Join(u.schemes, " or "))
+	}
+	*u.p = *v
+	return nil
+}
+
+// allowed reports whether a scheme is one of the schemes allowed, which are
+// compared without regard to case.
+func (u *urlValue) allowed(scheme string) bool {
+	for _, s := range u.schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *urlValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return u.Set(s)
+	}
+	return errParse
+}
+
+func (u *urlValue) Get() interface{} { return u.p }
+
+func (u *urlValue) snapshot() func() error {
+	v := *u.p
+	return func() error {
+		*u.p = v
+		return nil
+	}
+}
+
+func (u *urlValue) String() string {
+	if u.p == nil {
+		return ""
+	}
+	return u.p.String()
+}
+
+// URLVar defines a URL config with a given name and default value for a
+// ConfigSet. The argument p points to a url.URL variable in which to store
+// the value of the config. Values must be absolute URLs, and if any schemes
+// are given, such as "https", they must use one of them. An empty default is
+// allowed. It panics if the default isn't valid.
+func (c *ConfigSet) URLVar(p *url.URL, name string, value string, schemes ...string) {
+	v := &urlValue{p: p, schemes: schemes}
+	if value == "" {
+		*p = url.URL{}
+	} else if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// URL defines a URL config variable with a given name and default value, such
+// as "https://api.example.com/v1", for a ConfigSet. Values must use one of the
+// given schemes, if any.
+func (c *ConfigSet) URL(name string, value string, schemes ...string) *url.URL {
+	p := new(url.URL)
+	c.URLVar(p, name, value, schemes...)
+	return p
+}
+
+// URLVar defines a URL config with a given name and default value. The
+// argument p points to a url.URL variable in which to store the value of the
+// config.
+func URLVar(p *url.URL, name string, value string, schemes ...string) {
+	global().URLVar(p, name, value, schemes...)
+}
+
+// URL defines a URL config variable with a given name and default value.
+// Values must use one of the given schemes, if any.
+func URL(name string, value string, schemes ...string) *url.URL {
+	return global().URL(name, value, schemes...)
+}
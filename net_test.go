@@ -0,0 +1,111 @@
+package config
+
+import (
+	"net"
+	"testing"
+)
+
+func TestEmail(t *testing.T) {
+	c := NewConfigSet("net", ContinueOnError)
+	from := c.Email("alerts.from", "noreply@example.com")
+	to := c.Email("alerts.to", "")
+
+	err := c.parseBytes("net", []byte("[alerts]\nfrom = \"Alerts <alerts@example.com>\"\nto = \"ops@example.com\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *from != "Alerts <alerts@example.com>" || *to != "ops@example.com" {
+		t.Errorf("Unexpected addresses: %q, %q", *from, *to)
+	}
+
+	err = c.parseBytes("net", []byte("[alerts]\nto = \"ops at example.com\"\n"))
+	if err == nil || err.Error() != `The value for alerts.to is invalid: "ops at example.com" is not a valid email address` {
+		t.Error("Expected an invalid address error, got", err)
+	}
+}
+
+func TestMAC(t *testing.T) {
+	c := NewConfigSet("net", ContinueOnError)
+	mac := c.MAC("iface.mac", "")
+
+	err := c.parseBytes("net", []byte("[iface]\nmac = \"00:1A:2b:3c:4d:5e\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mac.String() != "00:1a:2b:3c:4d:5e" {
+		t.Error("iface.mac should be 00:1a:2b:3c:4d:5e, is", mac)
+	}
+
+	err = c.parseBytes("net", []byte("[iface]\nmac = \"00:1a:2b\"\n"))
+	if err == nil || err.Error() != `The value for iface.mac is invalid: "00:1a:2b" is not a valid MAC address` {
+		t.Error("Expected an invalid MAC error, got", err)
+	}
+}
+
+func TestIP(t *testing.T) {
+	c := NewConfigSet("net", ContinueOnError)
+	bind := c.IP("bind", net.IPv4(127, 0, 0, 1))
+	peer := c.IP("peer", nil)
+
+	if c.Lookup("peer").Value.String() != "" || !bind.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatal("Unexpected defaults:", *bind, *peer)
+	}
+	if err := c.parseBytes("net", []byte("bind = \"0.0.0.0\"\npeer = \"2001:db8::1\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !bind.Equal(net.IPv4zero) || peer.String() != "2001:db8::1" {
+		t.Errorf("Unexpected addresses: %s, %s", *bind, *peer)
+	}
+
+	err := c.parseBytes("net", []byte(`peer = "10.0.0.256"`))
+	if err == nil || err.Error() != `The value for peer is invalid: "10.0.0.256" is not a valid IP address` {
+		t.Error("Expected an invalid address error, got", err)
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	c := NewConfigSet("net", ContinueOnError)
+	trusted := c.CIDR("trusted_net", "127.0.0.0/8")
+	v6 := c.CIDR("v6_net", "")
+
+	if err := c.parseBytes("net", []byte("trusted_net = \"10.1.2.3/8\"\nv6_net = \"2001:db8::/32\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if trusted.String() != "10.0.0.0/8" || !trusted.Contains(net.ParseIP("10.200.0.1")) {
+		t.Error("trusted_net should be 10.0.0.0/8, is", trusted)
+	}
+	if v6.String() != "2001:db8::/32" {
+		t.Error("v6_net should be 2001:db8::/32, is", v6)
+	}
+
+	err := c.parseBytes("net", []byte(`trusted_net = "10.0.0.0"`))
+	if err == nil || err.Error() != `The value for trusted_net is invalid: "10.0.0.0" is not a valid CIDR network` {
+		t.Error("Expected an invalid network error, got", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	c := NewConfigSet("net", ContinueOnError)
+	base := c.URL("api.base_url", "http://localhost:8080", "http", "https")
+	proxy := c.URL("proxy", "")
+
+	if base.Host != "localhost:8080" || proxy.String() != "" {
+		t.Fatal("Unexpected defaults:", base, proxy)
+	}
+	err := c.parseBytes("net", []byte("proxy = \"socks5://proxy:1080\"\n[api]\nbase_url = \"HTTPS://api.example.com/v1\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.String() != "https://api.example.com/v1" || proxy.Scheme != "socks5" {
+		t.Errorf("Unexpected URLs: %s, %s", base, proxy)
+	}
+
+	err = c.parseBytes("net", []byte("[api]\nbase_url = \"ftp://api.example.com\"\n"))
+	if err == nil || err.Error() != `The value for api.base_url is invalid: "ftp://api.example.com" does not use http or https` {
+		t.Error("Expected a scheme error, got", err)
+	}
+	err = c.parseBytes("net", []byte(`proxy = "/relative/path"`))
+	if err == nil || err.Error() != `The value for proxy is invalid: "/relative/path" is not an absolute URL` {
+		t.Error("Expected a relative URL error, got", err)
+	}
+}
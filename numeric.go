@@ -0,0 +1,413 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// -- Percent Value
+
+type percentValue float64
+
+// parsePercent parses either a percentage like "12.5%" or a fraction like
+// "0.125", returning a fraction between 0 and 1.
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+		scale = 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errParse
+	}
+	return checkFraction(v / scale)
+}
+
+func checkFraction(v float64) (float64, error) {
+	if !(v >= 0 && v <= 1) {
+		return 0, fmt.Errorf("%g%% is not between 0%% and 100%%", v*100)
+	}
+	return v, nil
+}
+
+func (p *percentValue) Set(s string) error {
+	v, err := parsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = percentValue(v)
+	return nil
+}
+
+func (p *percentValue) setTOML(value interface{}) error {
+	var v float64
+	var err error
+	switch value := value.(type) {
+	case float64:
+		v, err = checkFraction(value)
+	case int64:
+		v, err = checkFraction(float64(value))
+	case string:
+		v, err = parsePercent(value)
+	default:
+		err = errParse
+	}
+	if err != nil {
+		return err
+	}
+	*p = percentValue(v)
+	return nil
+}
+
+func (p *percentValue) Get() interface{} { return float64(*p) }
+
+func (p *percentValue) snapshot() func() error {
+	v := *p
+	return func() error {
+		*p = v
+		return nil
+	}
+}
+
+func (p *percentValue) String() string {
+	return strconv.FormatFloat(float64(*p)*100, 'g', -1, 64) + "%"
+}
+
+// PercentVar defines a percentage config with a given name and default value
+// for a ConfigSet. The argument p points to a float64 variable in which to
+// store the value of the config as a fraction between 0 and 1. It panics if
+// the default is out of range.
+func (c *ConfigSet) PercentVar(p *float64, name string, value float64) {
+	if _, err := checkFraction(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	*p = value
+	c.Var((*percentValue)(p), name, "")
+}
+
+// Percent defines a percentage config variable with a given name and default
+// value for a ConfigSet. The TOML value may be a percentage string such as
+// "12.5%" or a fraction such as 0.125; either way the config holds the
+// fraction.
+func (c *ConfigSet) Percent(name string, value float64) *float64 {
+	p := new(float64)
+	c.PercentVar(p, name, value)
+	return p
+}
+
+// PercentVar defines a percentage config with a given name and default value.
+// The argument p points to a float64 variable in which to store the value of
+// the config as a fraction between 0 and 1.
+func PercentVar(p *float64, name string, value float64) {
+	global().PercentVar(p, name, value)
+}
+
+// Percent defines a percentage config variable with a given name and default
+// value.
+func Percent(name string, value float64) *float64 {
+	return global().Percent(name, value)
+}
+
+// -- Fixed
+
+// Fixed is a fixed-point decimal number, equal to Value / 10^Scale. It's used
+// for amounts like prices and fees that must not pick up float64 rounding
+// errors.
+type Fixed struct {
+	Value int64
+	Scale int
+}
+
+// ParseFixed parses a decimal number such as "12.34" or "-0.005" exactly.
+func ParseFixed(s string) (Fixed, error) {
+	s = strings.TrimSpace(s)
+	whole, frac := s, ""
+	if dot := strings.Index(s, "."); dot >= 0 {
+		whole, frac = s[:dot], s[dot+1:]
+		if frac == "" || strings.ContainsAny(frac, "+-") {
+			return Fixed{}, fmt.Errorf("%q is not a valid decimal number", s)
+		}
+	}
+	if whole == "" || whole == "-" || whole == "+" {
+		whole += "0"
+	}
+
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		if numError(err) == errRange {
+			return Fixed{}, fmt.Errorf("%q has too many digits", s)
+		}
+		return Fixed{}, fmt.Errorf("%q is not a valid decimal number", s)
+	}
+	return Fixed{v, len(frac)}, nil
+}
+
+// MinorUnits returns f as an integer number of 10^-scale units, e.g. cents
+// for a scale of 2. It returns an error if f can't be represented exactly.
+func (f Fixed) MinorUnits(scale int) (int64, error) {
+	v := f.Value
+	for s := f.Scale; s < scale; s++ {
+		if v > (1<<63-1)/10 || v < (-1<<63)/10 {
+			return 0, errRange
+		}
+		v *= 10
+	}
+	for s := f.Scale; s > scale; s-- {
+		if v%10 != 0 {
+			return 0, fmt.Errorf("%s has more than %d decimal places", f, scale)
+		}
+		v /= 10
+	}
+	return v, nil
+}
+
+func (f Fixed) String() string {
+	if f.Scale == 0 {
+		return strconv.FormatInt(f.Value, 10)
+	}
+	digits := strconv.FormatInt(f.Value, 10)
+	sign := ""
+	if f.Value < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= f.Scale {
+		digits = strings.Repeat("0", f.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - f.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+var errFloatDecimal = errors.New("decimal values must be written as strings or integers to avoid rounding")
+
+func (f *Fixed) Set(s string) error {
+	v, err := ParseFixed(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+func (f *Fixed) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return f.Set(v)
+	case int64:
+		*f = Fixed{v, 0}
+	case float64:
+		return errFloatDecimal
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (f *Fixed) Get() interface{} { return *f }
+
+func (f *Fixed) snapshot() func() error {
+	v := *f
+	return func() error {
+		*f = v
+		return nil
+	}
+}
+
+// DecimalVar defines a decimal config with a given name and default value for
+// a ConfigSet. The argument p points to a Fixed variable in which to store the
+// value of the config. It panics if the default isn't a valid decimal.
+func (c *ConfigSet) DecimalVar(p *Fixed, name string, value string) {
+	if err := p.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(p, name, "")
+}
+
+// Decimal defines a decimal config variable with a given name and default
+// value, such as "9.99", for a ConfigSet. TOML values must be strings or
+// integers; floats are rejected so that amounts are never rounded.
+func (c *ConfigSet) Decimal(name string, value string) *Fixed {
+	p := new(Fixed)
+	c.DecimalVar(p, name, value)
+	return p
+}
+
+// DecimalVar defines a decimal config with a given name and default value. The
+// argument p points to a Fixed variable in which to store the value of the
+// config.
+func DecimalVar(p *Fixed, name string, value string) {
+	global().DecimalVar(p, name, value)
+}
+
+// Decimal defines a decimal config variable with a given name and default
+// value.
+func Decimal(name string, value string) *Fixed {
+	return global().Decimal(name, value)
+}
+
+// -- big.Int Value
+
+type bigIntValue struct{ p *big.Int }
+
+func (b bigIntValue) Set(s string) error {
+	v, ok := new(big.Int).SetString(strings.TrimSpace(s), 0)
+	if !ok {
+		return fmt.Errorf("%q is not a valid integer", s)
+	}
+	b.p.Set(v)
+	return nil
+}
+
+func (b bigIntValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return b.Set(v)
+	case int64:
+		b.p.SetInt64(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (b bigIntValue) Get() interface{} { return b.p }
+
+func (b bigIntValue) snapshot() func() error {
+	v := new(big.Int).Set(b.p)
+	return func() error {
+		b.p.Set(v)
+		return nil
+	}
+}
+
+func (b bigIntValue) String() string {
+	if b.p == nil {
+		return ""
+	}
+	return b.p.String()
+}
+
+// BigIntVar defines an arbitrary-precision integer config with a given name
+// and default value for a ConfigSet. The argument p points to a big.Int
+// variable in which to store the value of the config. It panics if the
+// default isn't a valid integer.
+func (c *ConfigSet) BigIntVar(p *big.Int, name string, value string) {
+	v := bigIntValue{p}
+	if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// BigInt defines an arbitrary-precision integer config variable with a given
+// name and default value for a ConfigSet. TOML values may be integers, or
+// strings such as "1000000000000000000000000" for values that don't fit in
+// 64 bits.
+func (c *ConfigSet) BigInt(name string, value string) *big.Int {
+	p := new(big.Int)
+	c.BigIntVar(p, name, value)
+	return p
+}
+
+// BigIntVar defines an arbitrary-precision integer config with a given name
+// and default value. The argument p points to a big.Int variable in which to
+// store the value of the config.
+func BigIntVar(p *big.Int, name string, value string) {
+	global().BigIntVar(p, name, value)
+}
+
+// BigInt defines an arbitrary-precision integer config variable with a given
+// name and default value.
+func BigInt(name string, value string) *big.Int {
+	return global().BigInt(name, value)
+}
+
+// -- big.Float Value
+
+// bigFloatPrec is the precision, in bits, of BigFloat configs.
+const bigFloatPrec = 256
+
+type bigFloatValue struct{ p *big.Float }
+
+func (b bigFloatValue) Set(s string) error {
+	v, _, err := big.ParseFloat(strings.TrimSpace(s), 10, bigFloatPrec, big.ToNearestEven)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid number", s)
+	}
+	b.p.Set(v)
+	return nil
+}
+
+// setTOML accepts strings and integers. TOML floats are rejected, since
+// they've already been rounded to 64 bits.
+func (b bigFloatValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return b.Set(v)
+	case int64:
+		b.p.SetInt64(v)
+	case float64:
+		return errFloatDecimal
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (b bigFloatValue) Get() interface{} { return b.p }
+
+func (b bigFloatValue) snapshot() func() error {
+	v := new(big.Float).Copy(b.p)
+	return func() error {
+		b.p.Copy(v)
+		return nil
+	}
+}
+
+func (b bigFloatValue) String() string {
+	if b.p == nil {
+		return ""
+	}
+	return b.p.Text('g', -1)
+}
+
+// BigFloatVar defines an arbitrary-precision floating point config with a
+// given name and default value for a ConfigSet. The argument p points to a
+// big.Float variable in which to store the value of the config, whose
+// precision is set to 256 bits. It panics if the default isn't a valid
+// number.
+func (c *ConfigSet) BigFloatVar(p *big.Float, name string, value string) {
+	p.SetPrec(bigFloatPrec)
+	v := bigFloatValue{p}
+	if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// BigFloat defines an arbitrary-precision floating point config variable with
+// a given name and default value for a ConfigSet. TOML values may be strings,
+// which are parsed with 256 bits of precision, or integers. Floats are
+// rejected, since TOML decoding rounds them to 64 bits.
+func (c *ConfigSet) BigFloat(name string, value string) *big.Float {
+	p := new(big.Float)
+	c.BigFloatVar(p, name, value)
+	return p
+}
+
+// BigFloatVar defines an arbitrary-precision floating point config with a
+// given name and default value. The argument p points to a big.Float variable
+// in which to store the value of the config.
+func BigFloatVar(p *big.Float, name string, value string) {
+	global().BigFloatVar(p, name, value)
+}
+
+// BigFloat defines an arbitrary-precision floating point config variable with
+// a given name and default value.
+func BigFloat(name string, value string) *big.Float {
+	return global().BigFloat(name, value)
+}
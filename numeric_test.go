@@ -0,0 +1,106 @@
+package config
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPercent(t *testing.T) {
+	c := NewConfigSet("numeric", ContinueOnError)
+	sampling := c.Percent("tracing.sampling", 0.01)
+	rollout := c.Percent("rollout", 0)
+
+	err := c.parseBytes("numeric", []byte("rollout = \"12.5%\"\n[tracing]\nsampling = 0.25\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *rollout != 0.125 {
+		t.Error("rollout should be 0.125, is", *rollout)
+	}
+	if *sampling != 0.25 {
+		t.Error("tracing.sampling should be 0.25, is", *sampling)
+	}
+
+	err = c.parseBytes("numeric", []byte("rollout = \"150%\"\n"))
+	if err == nil || err.Error() != "The value for rollout is invalid: 150% is not between 0% and 100%" {
+		t.Error("Expected an out of range error, got", err)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	c := NewConfigSet("numeric", ContinueOnError)
+	price := c.Decimal("billing.price", "9.99")
+	fee := c.Decimal("billing.fee", "0")
+
+	err := c.parseBytes("numeric", []byte("[billing]\nprice = \"19.95\"\nfee = 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cents, err := price.MinorUnits(2); err != nil || cents != 1995 {
+		t.Error("billing.price should be 1995 cents, is", cents, err)
+	}
+	if cents, err := fee.MinorUnits(2); err != nil || cents != 200 {
+		t.Error("billing.fee should be 200 cents, is", cents, err)
+	}
+
+	err = c.parseBytes("numeric", []byte("[billing]\nprice = 19.95\n"))
+	if err == nil {
+		t.Error("Expected an error for a float decimal")
+	}
+
+	tests := map[string]string{"-0.05": "-0.05", "12": "12", ".5": "0.5", "-1.250": "-1.250"}
+	for given, expected := range tests {
+		f, err := ParseFixed(given)
+		if err != nil || f.String() != expected {
+			t.Errorf("ParseFixed(%q) should be %s, is %s (%v)", given, expected, f, err)
+		}
+	}
+	for _, bad := range []string{"1.", "1.2.3", "abc", "1e5", "99999999999999999999"} {
+		if _, err := ParseFixed(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	c := NewConfigSet("numeric", ContinueOnError)
+	supply := c.BigInt("token.supply", "0")
+	threshold := c.BigFloat("token.threshold", "0.5")
+
+	err := c.parseBytes("numeric", []byte(`
+[token]
+supply = "1000000000000000000000000000"
+threshold = "0.000000000000000000000000001"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSupply, _ := new(big.Int).SetString("1000000000000000000000000000", 10)
+	if supply.Cmp(expectedSupply) != 0 {
+		t.Error("token.supply should be 10^27, is", supply)
+	}
+	expectedThreshold, _, _ := big.ParseFloat("1e-27", 10, bigFloatPrec, big.ToNearestEven)
+	if threshold.Cmp(expectedThreshold) != 0 {
+		t.Error("token.threshold should be 1e-27, is", threshold)
+	}
+
+	var fee big.Float
+	c.BigFloatVar(&fee, "token.fee", "0")
+	err = c.parseBytes("numeric", []byte("[token]\nsupply = 42\nfee = 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if supply.Int64() != 42 || fee.Prec() != bigFloatPrec || fee.String() != "3" {
+		t.Error("Unexpected values:", supply, fee.String())
+	}
+	err = c.parseBytes("numeric", []byte("[token]\nfee = 0.1\n"))
+	if err == nil || err.Error() != "The value for token.fee is invalid: "+errFloatDecimal.Error() {
+		t.Error("Expected a float error, got", err)
+	}
+
+	err = c.parseBytes("numeric", []byte("[token]\nsupply = \"lots\"\n"))
+	if err == nil || err.Error() != `The value for token.supply is invalid: "lots" is not a valid integer` {
+		t.Error("Expected an invalid integer error, got", err)
+	}
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// -- Tristate
+
+// Tristate is a boolean config that also records whether it was set at all.
+// Its zero value is TriUnset.
+type Tristate int
+
+const (
+	TriUnset Tristate = iota
+	TriTrue
+	TriFalse
+)
+
+// Bool returns the value and whether it was set.
+func (t Tristate) Bool() (value, ok bool) {
+	return t == TriTrue, t != TriUnset
+}
+
+// Or returns the value if it was set, or def if it wasn't.
+func (t Tristate) Or(def bool) bool {
+	if t == TriUnset {
+		return def
+	}
+	return t == TriTrue
+}
+
+func (t Tristate) String() string {
+	switch t {
+	case TriTrue:
+		return "true"
+	case TriFalse:
+		return "false"
+	}
+	return "unset"
+}
+
+func (t *Tristate) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errParse
+	}
+	return t.setTOML(v)
+}
+
+func (t *Tristate) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			*t = TriTrue
+		} else {
+			*t = TriFalse
+		}
+	case string:
+		return t.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (t *Tristate) Get() interface{} { return *t }
+
+// IsBoolFlag lets a command-line flag for the config be given without a
+// value, as -name, which sets it to TriTrue.
+func (t *Tristate) IsBoolFlag() bool { return true }
+
+func (t *Tristate) snapshot() func() error {
+	v := *t
+	return func() error {
+		*t = v
+		return nil
+	}
+}
+
+// TriBoolVar defines a tri-state bool config with a given name for a
+// ConfigSet. The argument p points to a Tristate variable in which to store
+// the value of the config; it is reset to TriUnset.
+func (c *ConfigSet) TriBoolVar(p *Tristate, name string) {
+	*p = TriUnset
+	c.Var(p, name, "")
+}
+
+// TriBool defines a tri-state bool config variable with a given name for a
+// ConfigSet. Unlike Bool, it distinguishes a config that was never set from
+// one explicitly set to false.
+func (c *ConfigSet) TriBool(name string) *Tristate {
+	p := new(Tristate)
+	c.TriBoolVar(p, name)
+	return p
+}
+
+// TriBoolVar defines a tri-state bool config with a given name. The argument p
+// points to a Tristate variable in which to store the value of the config.
+func TriBoolVar(p *Tristate, name string) {
+	global().TriBoolVar(p, name)
+}
+
+// TriBool defines a tri-state bool config variable with a given name.
+func TriBool(name string) *Tristate {
+	return global().TriBool(name)
+}
+
+// -- Optional
+
+// Optional holds a config value along with whether any source provided it,
+// so a config explicitly set to its zero value can be told apart from one
+// that was never set.
+type Optional[T any] struct {
+	value T
+	ok    bool
+	inner configValue
+}
+
+// Get returns the value and whether it was provided. If it wasn't, the value
+// is the zero value of T.
+func (o *Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// Or returns the value if it was provided, or def if it wasn't.
+func (o *Optional[T]) Or(def T) T {
+	if !o.ok {
+		return def
+	}
+	return o.value
+}
+
+func (o *Optional[T]) Set(s string) error {
+	if err := o.inner.Set(s); err != nil {
+		return err
+	}
+	o.ok = true
+	return nil
+}
+
+func (o *Optional[T]) setTOML(value interface{}) error {
+	if err := o.inner.setTOML(value); err != nil {
+		return err
+	}
+	o.ok = true
+	return nil
+}
+
+// IsBoolFlag reports whether T is bool, so that a command-line flag for an
+// optional bool can be given without a value, as -name.
+func (o *Optional[T]) IsBoolFlag() bool {
+	_, ok := any(o.value).(bool)
+	return ok
+}
+
+func (o *Optional[T]) snapshot() func() error {
+	value, ok := o.value, o.ok
+	return func() error {
+		o.value, o.ok = value, ok
+		return nil
+	}
+}
+
+func (o *Optional[T]) String() string {
+	if o.inner == nil || !o.ok {
+		return ""
+	}
+	return o.inner.String()
+}
+
+// OptionalBool defines an optional bool config variable with a given name for a
+// ConfigSet.
+func (c *ConfigSet) OptionalBool(name string) *Optional[bool] {
+	o := new(Optional[bool])
+	o.inner = (*boolValue)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalInt defines an optional int config variable with a given name for a
+// ConfigSet.
+func (c *ConfigSet) OptionalInt(name string) *Optional[int] {
+	o := new(Optional[int])
+	o.inner = (*intValue)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalInt64 defines an optional int64 config variable with a given name for
+// a ConfigSet.
+func (c *ConfigSet) OptionalInt64(name string) *Optional[int64] {
+	o := new(Optional[int64])
+	o.inner = (*int64Value)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalString defines an optional string config variable with a given name
+// for a ConfigSet.
+func (c *ConfigSet) OptionalString(name string) *Optional[string] {
+	o := new(Optional[string])
+	o.inner = (*stringValue)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalFloat64 defines an optional float64 config variable with a given name
+// for a ConfigSet.
+func (c *ConfigSet) OptionalFloat64(name string) *Optional[float64] {
+	o := new(Optional[float64])
+	o.inner = (*float64Value)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalDuration defines an optional time.Duration config variable with a
+// given name for a ConfigSet.
+func (c *ConfigSet) OptionalDuration(name string) *Optional[time.Duration] {
+	o := new(Optional[time.Duration])
+	o.inner = (*durationValue)(&o.value)
+	c.Var(o, name, "")
+	return o
+}
+
+// OptionalBool defines an optional bool config variable with a given name.
+func OptionalBool(name string) *Optional[bool] {
+	return global().OptionalBool(name)
+}
+
+// OptionalInt defines an optional int config variable with a given name.
+func OptionalInt(name string) *Optional[int] {
+	return global().OptionalInt(name)
+}
+
+// OptionalInt64 defines an optional int64 config variable with a given name.
+func OptionalInt64(name string) *Optional[int64] {
+	return global().OptionalInt64(name)
+}
+
+// OptionalString defines an optional string config variable with a given name.
+func OptionalString(name string) *Optional[string] {
+	return global().OptionalString(name)
+}
+
+// OptionalFloat64 defines an optional float64 config variable with a given
+// name.
+func OptionalFloat64(name string) *Optional[float64] {
+	return global().OptionalFloat64(name)
+}
+
+// OptionalDuration defines an optional time.Duration config variable with a
+// given name.
+func OptionalDuration(name string) *Optional[time.Duration] {
+	return global().OptionalDuration(name)
+}
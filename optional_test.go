@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTriBool(t *testing.T) {
+	c := NewConfigSet("optional", ContinueOnError)
+	ipv6 := c.TriBool("net.ipv6")
+	tls := c.TriBool("net.tls")
+	compress := c.TriBool("net.compress")
+
+	err := c.parseBytes("optional", []byte("[net]\nipv6 = false\ntls = \"true\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := ipv6.Bool(); v || !ok {
+		t.Error("net.ipv6 should be set to false, is", ipv6)
+	}
+	if *tls != TriTrue {
+		t.Error("net.tls should be true, is", tls)
+	}
+	if *compress != TriUnset || !compress.Or(true) || compress.Or(false) {
+		t.Error("net.compress should be unset, is", compress)
+	}
+}
+
+func TestOptional(t *testing.T) {
+	c := NewConfigSet("optional", ContinueOnError)
+	workers := c.OptionalInt("workers")
+	name := c.OptionalString("name")
+	timeout := c.OptionalDuration("timeout")
+
+	if _, ok := workers.Get(); ok {
+		t.Fatal("workers should not be provided before loading")
+	}
+
+	err := c.parseBytes("optional", []byte("workers = 0\ntimeout = \"2s\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := workers.Get(); v != 0 || !ok {
+		t.Errorf("workers should be provided as 0, is %v (%v)", v, ok)
+	}
+	if v, ok := name.Get(); v != "" || ok || name.Or("anonymous") != "anonymous" {
+		t.Errorf("name should not be provided, is %q (%v)", v, ok)
+	}
+	if timeout.Or(time.Second) != 2*time.Second {
+		t.Error("timeout should be 2s, is", timeout)
+	}
+}
+
+func TestOptionalRemovedOnReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	os.WriteFile(path, []byte("timeout = 0\n"), 0644)
+
+	c := NewConfigSet("optional", ContinueOnError)
+	timeout := c.OptionalInt("timeout")
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := timeout.Get(); v != 0 || !ok {
+		t.Fatal("timeout should be set to 0, is", v, ok)
+	}
+
+	os.WriteFile(path, []byte("# timeout removed\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := timeout.Get(); ok {
+		t.Error("timeout shouldn't be set after it's removed")
+	}
+}
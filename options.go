@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/pelletier/go-toml"
+)
+
+// ParseOption changes how Parse interprets a config file.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	profiles bool
+	profile  string
+
+	conditions bool
+	hostname   string
+	env        string
+}
+
+// WithProfile selects a named profile from the config file. Profiles are
+// tables under [profile], such as [profile.canary], whose keys override those
+// in the rest of the file, so that one file can drive several deployments:
+//
+//	config.Parse("/etc/myapp.conf", config.WithProfile(os.Getenv("MYAPP_PROFILE")))
+//
+// With an empty name, no profile is applied, but the [profile] table is still
+// ignored. Naming a profile the file doesn't define is an error.
+func WithProfile(name string) ParseOption {
+	return func(o *parseOptions) {
+		o.profiles = true
+		o.profile = name
+	}
+}
+
+// WithConditions enables conditional tables, whose contents are merged into
+// the rest of the file only on matching hosts or in matching environments:
+//
+//	[when.hostname."db-*"]
+//	pool_size = 50
+//
+//	[when.env.production]
+//	log_level = "warn"
+//
+// Hostname conditions are glob patterns, as for path.Match, matched against
+// os.Hostname. Environment conditions match env exactly. Tables for the
+// environment are merged first, then tables for the host in order of their
+// patterns, so host-specific settings win. A profile selected by WithProfile
+// overrides them all.
+func WithConditions(env string) ParseOption {
+	hostname, _ := os.Hostname()
+	return func(o *parseOptions) {
+		o.conditions = true
+		o.hostname = hostname
+		o.env = env
+	}
+}
+
+// apply rewrites a decoded document according to the options. The name is
+// used in error messages.
+func (o *parseOptions) apply(name string, tree *toml.Tree) error {
+	if o.conditions {
+		if err := o.applyConditions(tree); err != nil {
+			return err
+		}
+	}
+	if o.profiles {
+		return o.applyProfile(name, tree)
+	}
+	return nil
+}
+
+func (o *parseOptions) applyConditions(tree *toml.Tree) error {
+	conditions, _ := tree.Get("when").(*toml.Tree)
+	tree.Delete("when")
+	if conditions == nil {
+		return nil
+	}
+
+	for _, kind := range conditions.Keys() {
+		if kind != "env" && kind != "hostname" {
+			return fmt.Errorf("when.%s is not a valid condition", kind)
+		}
+	}
+
+	if envs, ok := conditions.Get("env").(*toml.Tree); ok {
+		if table, ok := envs.Get(o.env).(*toml.Tree); ok && o.env != "" {
+			mergeTree(tree, table, nil)
+		}
+	}
+
+	if hosts, ok := conditions.Get("hostname").(*toml.Tree); ok {
+		patterns := hosts.Keys()
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			matched, err := path.Match(pattern, o.hostname)
+			if err != nil {
+				return fmt.Errorf("when.hostname.%q is not a valid pattern", pattern)
+			}
+			if table, ok := hosts.Get(pattern).(*toml.Tree); ok && matched {
+				mergeTree(tree, table, nil)
+			}
+		}
+	}
+	return nil
+}
+
+func (o *parseOptions) applyProfile(name string, tree *toml.Tree) error {
+	profiles, _ := tree.Get("profile").(*toml.Tree)
+	tree.Delete("profile")
+	if o.profile == "" {
+		return nil
+	}
+	var profile *toml.Tree
+	if profiles != nil {
+		profile, _ = profiles.Get(o.profile).(*toml.Tree)
+	}
+	if profile == nil {
+		return fmt.Errorf("%s does not define the profile %s", name, o.profile)
+	}
+	mergeTree(tree, profile, nil)
+	return nil
+}
+
+// mergeTree copies every value in src into dst, replacing any value with the
+// same key. Tables in both are merged recursively.
+func mergeTree(dst, src *toml.Tree, path []string) {
+	for _, key := range src.Keys() {
+		fullPath := append(path[:len(path):len(path)], key)
+		value := src.Get(key)
+		if subtree, ok := value.(*toml.Tree); ok {
+			if _, ok := dst.GetPath(fullPath).(*toml.Tree); ok {
+				mergeTree(dst, subtree, fullPath)
+				continue
+			}
+		}
+		dst.SetPath(fullPath, value)
+		dst.SetPositionPath(fullPath, src.GetPosition(key))
+	}
+}
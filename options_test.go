@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte(`
+[server]
+host = "0.0.0.0"
+port = 8080
+
+[profile.canary.server]
+port = 9090
+
+[profile.failover]
+region = "us-west"
+`), 0644)
+
+	c := NewConfigSet("options", ContinueOnError)
+	host := c.String("server.host", "")
+	port := c.Int("server.port", 0)
+	region := c.String("region", "us-east")
+
+	if err := c.Parse(path, WithProfile("canary")); err != nil {
+		t.Fatal(err)
+	}
+	if *host != "0.0.0.0" || *port != 9090 || *region != "us-east" {
+		t.Error("Unexpected canary settings:", *host, *port, *region)
+	}
+	if origin := c.Origin("server.port"); origin != path+":7" {
+		t.Error("server.port should come from the profile, is", origin)
+	}
+
+	if err := c.Parse(path, WithProfile("failover")); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8080 || *region != "us-west" {
+		t.Error("Unexpected failover settings:", *port, *region)
+	}
+
+	if err := c.Parse(path, WithProfile("")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Parse(path); err == nil || err.Error() != "profile.canary.server.port is not a valid config setting" && err.Error() != "profile.failover.region is not a valid config setting" {
+		t.Error("Expected profiles to be settings without WithProfile, got", err)
+	}
+
+	err := c.Parse(path, WithProfile("blue"))
+	if err == nil || err.Error() != path+" does not define the profile blue" {
+		t.Error("Expected an undefined profile error, got", err)
+	}
+}
+
+func TestWithConditions(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("Can't get the hostname:", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte(`
+pool_size = 10
+log_level = "debug"
+
+[when.env.production]
+log_level = "warn"
+pool_size = 20
+
+[when.hostname."`+hostname[:1]+`*"]
+pool_size = 50
+
+[when.hostname."no-such-host-*"]
+pool_size = 99
+`), 0644)
+
+	c := NewConfigSet("options", ContinueOnError)
+	poolSize := c.Int("pool_size", 0)
+	logLevel := c.String("log_level", "")
+
+	if err := c.Parse(path, WithConditions("production")); err != nil {
+		t.Fatal(err)
+	}
+	if *poolSize != 50 || *logLevel != "warn" {
+		t.Error("Unexpected production settings:", *poolSize, *logLevel)
+	}
+
+	if err := c.Parse(path, WithConditions("staging")); err != nil {
+		t.Fatal(err)
+	}
+	if *poolSize != 50 || *logLevel != "debug" {
+		t.Error("Unexpected staging settings:", *poolSize, *logLevel)
+	}
+
+	os.WriteFile(path, []byte("[when.weekday.monday]\npool_size = 1\n"), 0644)
+	err = c.Parse(path, WithConditions("production"))
+	if err == nil || err.Error() != "when.weekday is not a valid condition" {
+		t.Error("Expected an invalid condition error, got", err)
+	}
+}
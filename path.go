@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathCheck is a check that a Path config makes on the file its value names
+// when it's set.
+type PathCheck int
+
+const (
+	// PathExists requires the file to exist.
+	PathExists PathCheck = iota
+	// PathIsDir requires the file to be a directory.
+	PathIsDir
+	// PathIsFile requires the file to be a regular file.
+	PathIsFile
+	// PathReadable requires the file to be readable by the process, which is
+	// checked by opening it.
+	PathReadable
+	// PathWritable requires the file to be writable by the process. A regular
+	// file is opened for writing without being changed, and a directory is
+	// checked by creating and removing a temporary file in it.
+	PathWritable
+)
+
+// expandPath expands a leading "~" to the user's home directory, and $VAR and
+// ${VAR} to the values of environment variables, which must be set.
+func expandPath(path string) (string, error) {
+	var missing []string
+	path = os.Expand(path, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("$%s isn't set", missing[0])
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = home + path[1:]
+	}
+	return path, nil
+}
+
+// check makes a check on the file at path.
+func (check PathCheck) check(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%q does not exist", path)
+	} else if err != nil {
+		return err
+	}
+	switch check {
+	case PathIsDir:
+		if !fi.IsDir() {
+			return fmt.Errorf("%q is not a directory", path)
+		}
+	case PathIsFile:
+		if !fi.Mode().IsRegular() {
+			return fmt.Errorf("%q is not a regular file", path)
+		}
+	case PathReadable:
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%q is not readable", path)
+		}
+		f.Close()
+	case PathWritable:
+		if fi.IsDir() {
+			f, err := os.CreateTemp(path, ".config-check-*")
+			if err != nil {
+				return fmt.Errorf("%q is not writable", path)
+			}
+			f.Close()
+			os.Remove(f.Name())
+			break
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("%q is not writable", path)
+		}
+		f.Close()
+	}
+	return nil
+}
+
+// -- path Value
+
+type pathValue struct {
+	p      *string
+	checks []PathCheck
+}
+
+func (v *pathValue) Set(s string) error {
+	path, err := expandPath(s)
+	if err != nil {
+		return fmt.Errorf("%q can't be expanded: %s", s, err)
+	}
+	for _, check := range v.checks {
+		if err := check.check(path); err != nil {
+			return err
+		}
+	}
+	*v.p = path
+	return nil
+}
+
+func (v *pathValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return v.Set(s)
+	}
+	return errParse
+}
+
+func (v *pathValue) Get() interface{} { return *v.p }
+
+func (v *pathValue) snapshot() func() error {
+	s := *v.p
+	return func() error {
+		*v.p = s
+		return nil
+	}
+}
+
+func (v *pathValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return *v.p
+}
+
+// PathVar defines a file path config with a given name and default value for
+// a ConfigSet. The argument p points to a string variable in which to store
+// the value of the config. A leading "~" is expanded to the user's home
+// directory, and $VAR and ${VAR} to the values of environment variables, so
+// data_dir = "~/app/data" works as it would in a shell. A variable that isn't
+// set fails the load. The checks, if any, are made on the expanded path each
+// time the config is set, but not on the default, whose file may not exist
+// until later. It panics if the default can't be expanded.
+func (c *ConfigSet) PathVar(p *string, name string, value string, checks ...PathCheck) {
+	if err := (&pathValue{p: p}).Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(&pathValue{p: p, checks: checks}, name, "")
+}
+
+// Path defines a file path config variable with a given name and default
+// value for a ConfigSet. Paths are expanded and checked as described for
+// PathVar. Relative paths are relative to the working directory unless the
+// config is passed to ResolveRelative.
+func (c *ConfigSet) Path(name string, value string, checks ...PathCheck) *string {
+	p := new(string)
+	c.PathVar(p, name, value, checks...)
+	return p
+}
+
+// PathVar defines a file path config with a given name and default value. The
+// argument p points to a string variable in which to store the value of the
+// config.
+func PathVar(p *string, name string, value string, checks ...PathCheck) {
+	global().PathVar(p, name, value, checks...)
+}
+
+// Path defines a file path config variable with a given name and default
+// value.
+func Path(name string, value string, checks ...PathCheck) *string {
+	return global().Path(name, value, checks...)
+}
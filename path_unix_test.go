@@ -0,0 +1,56 @@
+//go:build unix
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "data"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "app.pem"), []byte("cert"), 0644)
+
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", dir)
+	os.Setenv("PATH_TEST_DIR", dir)
+	defer os.Unsetenv("PATH_TEST_DIR")
+
+	c := NewConfigSet("path", ContinueOnError)
+	data := c.Path("data_dir", "~/missing", PathIsDir, PathWritable)
+	cert := c.Path("cert", "", PathIsFile, PathReadable)
+	logs := c.Path("logs", "/var/log")
+
+	if *data != filepath.Join(dir, "missing") {
+		t.Fatal("data_dir should default to ~/missing, is", *data)
+	}
+	err = c.parseBytes("path", []byte("data_dir = \"~/data\"\ncert = \"${PATH_TEST_DIR}/app.pem\"\nlogs = \"$HOME/logs\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *data != filepath.Join(dir, "data") || *cert != filepath.Join(dir, "app.pem") || *logs != dir+"/logs" {
+		t.Error("Unexpected paths:", *data, *cert, *logs)
+	}
+	if files, _ := ioutil.ReadDir(*data); len(files) != 0 {
+		t.Error("The writable check should clean up after itself, left", files)
+	}
+
+	for doc, expected := range map[string]string{
+		`data_dir = "~/app.pem"`:        `The value for data_dir is invalid: "` + filepath.Join(dir, "app.pem") + `" is not a directory`,
+		`cert = "~/nothing"`:            `The value for cert is invalid: "` + filepath.Join(dir, "nothing") + `" does not exist`,
+		`logs = "${PATH_TEST_UNSET}/x"`: `The value for logs is invalid: "${PATH_TEST_UNSET}/x" can't be expanded: $PATH_TEST_UNSET isn't set`,
+	} {
+		err := c.parseBytes("path", []byte(doc))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s, got %v", expected, doc, err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Persister saves config variables changed at runtime back to the source they
+// were loaded from, so changes made by operators survive a restart.
+type Persister interface {
+	// Persist saves the new value of the named config.
+	Persist(name string, value flag.Value) error
+}
+
+// SetPersister sets the Persister that Set uses to save each change. If
+// saving a change fails, Set restores the config's previous value and returns
+// the error. Changes to configs marked Sensitive or decrypted when they were
+// loaded aren't saved, since that would write their plaintext; Set fails for
+// them instead. Changes aren't saved if p is nil, which is the default.
+func (c *ConfigSet) SetPersister(p Persister) {
+	c.persister = p
+}
+
+// persist saves a change made by Set, putting the previous value back with
+// restore if it can't be saved.
+func (c *ConfigSet) persist(name string, restore func() error) error {
+	if c.persister == nil {
+		return nil
+	}
+	if err := c.persistValue(c.Lookup(name)); err != nil {
+		if restoreErr := restore(); restoreErr != nil {
+			return fmt.Errorf("%s, and the previous value can't be restored: %s", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// persistValue saves a config's value with the ConfigSet's Persister.
+func (c *ConfigSet) persistValue(f *flag.Flag) error {
+	if c.secrets[f.Name] {
+		return fmt.Errorf("can't persist %s: it holds a secret", f.Name)
+	}
+	if err := c.persister.Persist(f.Name, f.Value); err != nil {
+		return fmt.Errorf("can't persist %s: %s", f.Name, err)
+	}
+	return nil
+}
+
+// -- TOML file Persister
+
+type filePersister string
+
+// PersistFile returns a Persister that rewrites the TOML file at path. Only
+// the line setting the config is changed, so comments and formatting are
+// preserved; a config the file doesn't set is added to its table. Values that
+// span several lines can't be rewritten.
+func PersistFile(path string) Persister {
+	return filePersister(path)
+}
+
+func (p filePersister) Persist(name string, value flag.Value) error {
+	path := string(p)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	tree, err := loadTOML(data)
+	if err != nil {
+		return err
+	}
+	data, err = rewriteKey(data, tree, name, tomlValueString(value))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// tomlValueString formats a config's value as a TOML value. Slices are
+// written as arrays, so that elements containing commas survive.
+func tomlValueString(value flag.Value) string {
+	if getter, ok := value.(flag.Getter); ok {
+		switch v := getter.Get().(type) {
+		case []string:
+			return tomlArray(len(v), func(i int) string { return strconv.Quote(v[i]) })
+		case Globs:
+			return tomlArray(len(v), func(i int) string { return strconv.Quote(v[i]) })
+		case []KeyValue:
+			return tomlArray(len(v), func(i int) string { return strconv.Quote(v[i].String()) })
+		case []time.Duration:
+			return tomlArray(len(v), func(i int) string { return strconv.Quote(v[i].String()) })
+		case []int:
+			return tomlArray(len(v), func(i int) string { return strconv.Itoa(v[i]) })
+		case []int64:
+			return tomlArray(len(v), func(i int) string { return strconv.FormatInt(v[i], 10) })
+		case []float64:
+			return tomlArray(len(v), func(i int) string { return strconv.FormatFloat(v[i], 'g', -1, 64) })
+		}
+	}
+	if isBareValue(value) {
+		return value.String()
+	}
+	return strconv.Quote(value.String())
+}
+
+// tomlArray formats a TOML array of n elements, formatted by elem.
+func tomlArray(n int, elem func(i int) string) string {
+	elems := make([]string, n)
+	for i := range elems {
+		elems[i] = elem(i)
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// rewriteKey returns the TOML document data, which decodes as tree, with the
+// dotted key name set to value.
+func rewriteKey(data []byte, tree *toml.Tree, name, value string) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	keys := strings.Split(name, ".")
+	table, key := keys[:len(keys)-1], keys[len(keys)-1]
+
+	if tree.HasPath(keys) {
+		line := tree.GetPositionPath(keys).Line - 1
+		if line < 0 || line >= len(lines) {
+			return nil, fmt.Errorf("can't find %s", name)
+		}
+		rewritten, ok := rewriteLine(lines[line], value)
+		if !ok {
+			return nil, fmt.Errorf("can't rewrite the value of %s", name)
+		}
+		lines[line] = rewritten
+		return []byte(strings.Join(lines, "")), nil
+	}
+
+	entry := key + " = " + value + "\n"
+	if len(table) == 0 {
+		// Root keys must precede the first table header, and go before the
+		// blank lines separating it from them.
+		at := len(lines)
+		for i, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "[") {
+				at = i
+				break
+			}
+		}
+		for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		return insertLine(lines, at, entry), nil
+	}
+
+	if _, ok := tree.GetPath(table).(*toml.Tree); ok {
+		header := "[" + strings.Join(table, ".") + "]"
+		if line := tree.GetPositionPath(table).Line - 1; line >= 0 && line < len(lines) {
+			text := strings.TrimSpace(lines[line])
+			if strings.HasPrefix(strings.Replace(text, " ", "", -1), header) {
+				return insertLine(lines, line+1, entry), nil
+			}
+			if !strings.HasPrefix(text, "[") {
+				return nil, fmt.Errorf("can't add %s to an inline table", name)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(lines, ""))
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "\n[%s]\n%s", strings.Join(table, "."), entry)
+	return buf.Bytes(), nil
+}
+
+// rewriteLine replaces the value in a line of the form "key = value", keeping
+// any comment after it. It returns false if the value doesn't end on the line.
+func rewriteLine(line, value string) (string, bool) {
+	eq := strings.Index(line, "=")
+	if eq < 0 {
+		return "", false
+	}
+	rest := strings.TrimRight(line[eq+1:], "\r\n")
+	newline := line[eq+1+len(rest):]
+
+	// The value ends at the first "#" that leaves a valid value before it,
+	// since a "#" inside a string doesn't start a comment.
+	end := len(rest)
+	for i := 0; i < len(rest); i++ {
+		if rest[i] != '#' {
+			continue
+		}
+		if _, err := loadTOML([]byte("v =" + rest[:i])); err == nil {
+			end = i
+			break
+		}
+	}
+	if end == len(rest) {
+		if _, err := loadTOML([]byte("v =" + rest)); err != nil {
+			return "", false
+		}
+	}
+
+	comment := rest[end:]
+	if comment != "" {
+		comment = " " + comment
+	}
+	return line[:eq+1] + " " + value + comment + newline, true
+}
+
+func insertLine(lines []string, at int, line string) []byte {
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += "\n"
+	}
+	lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	return []byte(strings.Join(lines, ""))
+}
+
+// writeFileAtomic replaces the file at path with data, keeping its mode, so
+// that a process reading it never sees a partly written file.
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// -- HTTP key/value store Persister
+
+type httpPersister struct {
+	url    string
+	client *http.Client
+}
+
+// PersistHTTP returns a Persister that saves each change with an HTTP PUT to
+// baseURL followed by the config's name with dots replaced by slashes, with
+// the value as the request body. This suits key/value stores such as Consul,
+// whose keys are set with PUT requests to
+// "http://localhost:8500/v1/kv/myapp/". If client is nil,
+// http.DefaultClient is used.
+func PersistHTTP(baseURL string, client *http.Client) Persister {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpPersister{url: strings.TrimSuffix(baseURL, "/") + "/", client: client}
+}
+
+func (p *httpPersister) Persist(name string, value flag.Value) error {
+	key := strings.Replace(name, ".", "/", -1)
+	req, err := http.NewRequest("PUT", p.url+key, strings.NewReader(value.String()))
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("PUT %s: %s", req.URL, resp.Status)
+	}
+	return nil
+}
+
+// SetPersister sets the Persister that Set uses to save each change to the
+// global config.
+func SetPersister(p Persister) {
+	global().SetPersister(p)
+}
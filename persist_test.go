@@ -0,0 +1,161 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPersistFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.toml")
+	ioutil.WriteFile(path, []byte(`# App settings
+name = "app" # the app's name
+
+[server]
+# Listen here.
+port = 8080 # "#" isn't a comment in a string
+host = "a#b"
+
+[db.pool]
+size = 1
+`), 0600)
+
+	c := NewConfigSet("persist", ContinueOnError)
+	c.String("name", "")
+	c.Bool("debug", false)
+	c.Int("server.port", 0)
+	c.String("server.host", "")
+	c.String("server.mode", "")
+	c.Int("db.pool.size", 0)
+	c.String("db.name", "")
+	c.String("log.level", "")
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	c.SetPersister(PersistFile(path))
+
+	for _, set := range [][2]string{
+		{"name", "new app"},
+		{"debug", "true"},
+		{"server.port", "9000"},
+		{"server.host", "c#d"},
+		{"server.mode", "fast"},
+		{"db.name", "main"},
+		{"log.level", "info"},
+	} {
+		if err := c.Set(set[0], set[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	expected := `# App settings
+name = "new app" # the app's name
+debug = true
+
+[server]
+mode = "fast"
+# Listen here.
+port = 9000 # "#" isn't a comment in a string
+host = "c#d"
+
+[db.pool]
+size = 1
+
+[db]
+name = "main"
+
+[log]
+level = "info"
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected file:\n%s", data)
+	}
+	if err := c.Parse(path); err != nil {
+		t.Error("The rewritten file should load:", err)
+	}
+}
+
+func TestPersistFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	ioutil.WriteFile(path, []byte("cutoff = 2024-01-15\ntags = [\"a\"]\npassword = \"x\"\n"), 0600)
+
+	c := NewConfigSet("persist", ContinueOnError)
+	c.Date("cutoff", "")
+	tags := c.StringSlice("tags", nil)
+	password := c.String("password", "")
+	c.Sensitive("password")
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	c.SetPersister(PersistFile(path))
+
+	tx := c.Begin()
+	tx.Set("tags", "b,c")
+	tx.Set("cutoff", "2024-02-01")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); !strings.Contains(string(data), `tags = ["b", "c"]`) {
+		t.Errorf("tags should be written as an array:\n%s", data)
+	}
+	*tags = []string{"a,b", "c"}
+	if err := c.persistValue(c.Lookup("tags")); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Set("password", "y")
+	if err == nil || err.Error() != "can't persist password: it holds a secret" {
+		t.Error("Expected a secret error, got", err)
+	}
+	if *password != "x" {
+		t.Error("password should be restored after the error, is", *password)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	expected := "cutoff = \"2024-02-01\"\ntags = [\"a,b\", \"c\"]\npassword = \"x\"\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected file:\n%s", data)
+	}
+}
+
+func TestPersistHTTP(t *testing.T) {
+	stored := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/kv/app/server/fail" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		stored[r.Method+" "+r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	c := NewConfigSet("persist", ContinueOnError)
+	port := c.Int("server.port", 8080)
+	fail := c.Int("server.fail", 1)
+	c.SetPersister(PersistHTTP(server.URL+"/v1/kv/app/", nil))
+
+	if err := c.Set("server.port", "9000"); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || stored["PUT /v1/kv/app/server/port"] != "9000" {
+		t.Error("Unexpected result:", *port, stored)
+	}
+
+	err := c.Set("server.fail", "2")
+	if err == nil || err.Error() != "can't persist server.fail: PUT "+server.URL+"/v1/kv/app/server/fail: 403 Forbidden" {
+		t.Error("Unexpected error:", err)
+	}
+	if *fail != 1 {
+		t.Error("server.fail should be restored after the error, is", *fail)
+	}
+}
@@ -0,0 +1,359 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+type plistProvider struct {
+	path string
+}
+
+// Plist returns a Provider that reads settings from a property list, such as
+// "~/Library/Preferences/com.example.myapp.plist", so that macOS agents can
+// share setting definitions with builds that use TOML files. Both the XML
+// format and the binary format that macOS writes preferences in are read. A
+// leading "~" in path is expanded to the user's home directory, and $VAR and
+// ${VAR} to the values of environment variables. Dictionaries are tables, and
+// strings, integers, reals, booleans, dates, and arrays are loaded as the
+// corresponding TOML values. Data values are loaded as strings. A missing file
+// provides no settings.
+func Plist(path string) Provider {
+	return &plistProvider{path}
+}
+
+func (p *plistProvider) Name() string { return "plist:" + p.path }
+
+func (p *plistProvider) Load() (map[string]interface{}, error) {
+	path, err := expandPath(p.path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if bytes.HasPrefix(data, bplistMagic) {
+		values, err = decodeBinaryPlist(data)
+	} else {
+		values, err = decodePlist(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid property list: %s", p.path, err)
+	}
+	return values, nil
+}
+
+// decodePlist decodes an XML property list whose root is a dictionary.
+func decodePlist(r io.Reader) (map[string]interface{}, error) {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+		value, err := decodePlistValue(d, start)
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the root element is <%s>, not <dict>", start.Name.Local)
+		}
+		return dict, nil
+	}
+}
+
+func decodePlistValue(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodePlistDict(d)
+	case "array":
+		array := []interface{}{}
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tok := tok.(type) {
+			case xml.StartElement:
+				value, err := decodePlistValue(d, tok)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		return start.Name.Local == "true", d.Skip()
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	case "date":
+		return time.Parse(time.RFC3339, text)
+	case "data":
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		return string(data), err
+	}
+	return nil, fmt.Errorf("<%s> is not a property list element", start.Name.Local)
+}
+
+func decodePlistDict(d *xml.Decoder) (map[string]interface{}, error) {
+	dict := make(map[string]interface{})
+	var key string
+	haveKey := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if !haveKey {
+				if tok.Name.Local != "key" {
+					return nil, fmt.Errorf("expected <key> in <dict>, found <%s>", tok.Name.Local)
+				}
+				if err := d.DecodeElement(&key, &tok); err != nil {
+					return nil, err
+				}
+				haveKey = true
+				continue
+			}
+			value, err := decodePlistValue(d, tok)
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+			haveKey = false
+		case xml.EndElement:
+			if haveKey {
+				return nil, fmt.Errorf("<key>%s</key> has no value", key)
+			}
+			return dict, nil
+		}
+	}
+}
+
+// -- binary property lists
+
+var bplistMagic = []byte("bplist00")
+
+// bplistEpoch is the time that binary property list dates count from.
+var bplistEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// bplist is a binary property list being decoded. Its objects are stored
+// once and referred to by their index in the offset table.
+type bplist struct {
+	data     []byte
+	offsets  []byte
+	objects  uint64
+	offSize  int
+	refSize  int
+	visiting map[uint64]bool
+}
+
+// decodeBinaryPlist decodes a binary property list whose root is a
+// dictionary.
+func decodeBinaryPlist(data []byte) (map[string]interface{}, error) {
+	if len(data) < len(bplistMagic)+32 {
+		return nil, errors.New("it is truncated")
+	}
+	trailer := data[len(data)-32:]
+	b := &bplist{
+		data:     data,
+		offSize:  int(trailer[6]),
+		refSize:  int(trailer[7]),
+		objects:  binary.BigEndian.Uint64(trailer[8:]),
+		visiting: make(map[uint64]bool),
+	}
+	top := binary.BigEndian.Uint64(trailer[16:])
+	table := binary.BigEndian.Uint64(trailer[24:])
+	if b.offSize < 1 || b.offSize > 8 || b.refSize < 1 || b.refSize > 8 ||
+		table > uint64(len(data)) || b.objects > (uint64(len(data))-table)/uint64(b.offSize) {
+		return nil, errors.New("its trailer is invalid")
+	}
+	b.offsets = data[table : table+b.objects*uint64(b.offSize)]
+
+	value, err := b.object(top)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the root object is not a dictionary")
+	}
+	return dict, nil
+}
+
+// object decodes the object with the given index.
+func (b *bplist) object(ref uint64) (interface{}, error) {
+	if ref >= b.objects {
+		return nil, fmt.Errorf("object %d doesn't exist", ref)
+	}
+	if b.visiting[ref] {
+		return nil, fmt.Errorf("object %d contains itself", ref)
+	}
+	b.visiting[ref] = true
+	defer delete(b.visiting, ref)
+
+	off := bplistUint(b.offsets[ref*uint64(b.offSize):][:b.offSize])
+	if off >= uint64(len(b.data)) {
+		return nil, fmt.Errorf("object %d is out of range", ref)
+	}
+	marker := b.data[off]
+	kind, info := marker>>4, marker&0x0f
+	pos := off + 1
+
+	switch {
+	case marker == 0x08 || marker == 0x09:
+		return marker == 0x09, nil
+	case kind == 0x1 && info <= 3:
+		v, err := b.bytes(pos, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		return int64(bplistUint(v)), nil
+	case kind == 0x2 && (info == 2 || info == 3):
+		v, err := b.bytes(pos, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		if info == 2 {
+			return float64(math.Float32frombits(uint32(bplistUint(v)))), nil
+		}
+		return math.Float64frombits(bplistUint(v)), nil
+	case marker == 0x33:
+		v, err := b.bytes(pos, 8)
+		if err != nil {
+			return nil, err
+		}
+		secs, frac := math.Modf(math.Float64frombits(bplistUint(v)))
+		return bplistEpoch.Add(time.Duration(secs)*time.Second + time.Duration(frac*float64(time.Second))), nil
+	case kind == 0x4 || kind == 0x5 || kind == 0x6 || kind == 0xa || kind == 0xd:
+		return b.collection(ref, kind, info, pos)
+	}
+	return nil, fmt.Errorf("object %d has an unsupported type (0x%02x)", ref, marker)
+}
+
+// collection decodes a data, string, array, or dictionary object, whose
+// marker is followed by its length.
+func (b *bplist) collection(ref uint64, kind, info byte, pos uint64) (interface{}, error) {
+	n := uint64(info)
+	if info == 0x0f {
+		v, err := b.bytes(pos, 1)
+		if err != nil {
+			return nil, err
+		}
+		if v[0]>>4 != 0x1 || v[0]&0x0f > 3 {
+			return nil, fmt.Errorf("object %d has an invalid length", ref)
+		}
+		size := uint64(1) << (v[0] & 0x0f)
+		if v, err = b.bytes(pos+1, size); err != nil {
+			return nil, err
+		}
+		n, pos = bplistUint(v), pos+1+size
+	}
+	if n > uint64(len(b.data)) {
+		return nil, fmt.Errorf("object %d is out of range", ref)
+	}
+
+	switch kind {
+	case 0x4, 0x5:
+		v, err := b.bytes(pos, n)
+		return string(v), err
+	case 0x6:
+		v, err := b.bytes(pos, 2*n)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, n)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(v[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	case 0xa:
+		refs, err := b.bytes(pos, n*uint64(b.refSize))
+		if err != nil {
+			return nil, err
+		}
+		array := make([]interface{}, n)
+		for i := range array {
+			if array[i], err = b.object(bplistUint(refs[i*b.refSize:][:b.refSize])); err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	}
+
+	refs, err := b.bytes(pos, 2*n*uint64(b.refSize))
+	if err != nil {
+		return nil, err
+	}
+	dict := make(map[string]interface{}, n)
+	for i := 0; i < int(n); i++ {
+		key, err := b.object(bplistUint(refs[i*b.refSize:][:b.refSize]))
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("object %d has a key that isn't a string", ref)
+		}
+		if dict[name], err = b.object(bplistUint(refs[(int(n)+i)*b.refSize:][:b.refSize])); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+// bytes returns the n bytes at pos.
+func (b *bplist) bytes(pos, n uint64) ([]byte, error) {
+	if pos > uint64(len(b.data)) || n > uint64(len(b.data))-pos {
+		return nil, errors.New("it is truncated")
+	}
+	return b.data[pos : pos+n], nil
+}
+
+// bplistUint decodes a big-endian unsigned integer of up to 8 bytes.
+func bplistUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>name</key>
+	<string>agent</string>
+	<key>enabled</key>
+	<true/>
+	<key>server</key>
+	<dict>
+		<key>port</key>
+		<integer>9000</integer>
+		<key>ratio</key>
+		<real>0.25</real>
+		<key>hosts</key>
+		<array>
+			<string>a.example.com</string>
+			<string>b.example.com</string>
+		</array>
+		<key>since</key>
+		<date>2024-01-15T09:00:00Z</date>
+	</dict>
+</dict>
+</plist>
+`
+
+// testBinaryPlist is testPlist in the binary format, with a server.label of
+// "café", which is stored as UTF-16.
+const testBinaryPlist = "62706c6973743030d301020304050657656e61626c6564546e616d655673657276657209556167656e74d50708090a0b0c0f10111255686f737473556c6162656c54706f727455726174696f5573696e6365a20d0e5d612e6578616d706c652e636f6d5d622e6578616d706c652e636f6d6400630061006600e9112328233fd00000000000003341c5aa95c8000000080f171c23242a353b41464c525563717a7d86000000000000010100000000000000130000000000000000000000000000008f"
+
+func TestPlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "com.example.agent.plist")
+	ioutil.WriteFile(path, []byte(testPlist), 0644)
+
+	c := NewConfigSet("plist", ContinueOnError)
+	name := c.String("name", "")
+	enabled := c.Bool("enabled", false)
+	port := c.Int("server.port", 8080)
+	ratio := c.Float64("server.ratio", 0)
+	hosts := c.StringSet("server.hosts", nil)
+	since := c.Time("server.since", time.Time{})
+
+	if err := c.ParseProvider(Plist(path)); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "agent" || !*enabled || *port != 9000 || *ratio != 0.25 {
+		t.Error("Unexpected settings:", *name, *enabled, *port, *ratio)
+	}
+	if len(*hosts) != 2 || (*hosts)[1] != "b.example.com" {
+		t.Error("Unexpected server.hosts:", *hosts)
+	}
+	if !since.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Error("Unexpected server.since:", *since)
+	}
+
+	if err := c.ParseProvider(Plist(filepath.Join(dir, "missing.plist"))); err != nil {
+		t.Error("A missing plist should provide no settings, got", err)
+	}
+
+	ioutil.WriteFile(path, []byte("<plist><dict><key>name</key></dict></plist>"), 0644)
+	err = c.ParseProvider(Plist(path))
+	if err == nil || err.Error() != path+" is not a valid property list: <key>name</key> has no value" {
+		t.Error("Expected an invalid plist error, got", err)
+	}
+}
+
+func TestBinaryPlist(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	data, _ := hex.DecodeString(testBinaryPlist)
+	ioutil.WriteFile(filepath.Join(dir, "com.example.agent.plist"), data, 0644)
+
+	c := NewConfigSet("plist", ContinueOnError)
+	name := c.String("name", "")
+	enabled := c.Bool("enabled", false)
+	port := c.Int("server.port", 8080)
+	ratio := c.Float64("server.ratio", 0)
+	hosts := c.StringSet("server.hosts", nil)
+	since := c.Time("server.since", time.Time{})
+	label := c.String("server.label", "")
+
+	if err := c.ParseProvider(Plist("~/com.example.agent.plist")); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "agent" || !*enabled || *port != 9000 || *ratio != 0.25 || *label != "café" {
+		t.Error("Unexpected settings:", *name, *enabled, *port, *ratio, *label)
+	}
+	if len(*hosts) != 2 || (*hosts)[1] != "b.example.com" {
+		t.Error("Unexpected server.hosts:", *hosts)
+	}
+	if !since.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Error("Unexpected server.since:", *since)
+	}
+
+	path := filepath.Join(dir, "truncated.plist")
+	ioutil.WriteFile(path, data[:len(data)-40], 0644)
+	err := c.ParseProvider(Plist(path))
+	if err == nil || !strings.HasPrefix(err.Error(), path+" is not a valid property list: ") {
+		t.Error("Expected an invalid plist error, got", err)
+	}
+}
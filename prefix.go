@@ -0,0 +1,21 @@
+package config
+
+// WithPrefix returns a view of the ConfigSet whose definition methods prepend
+// prefix to every name, so that a reusable library can define its settings
+// once and let the host program choose the table they live under:
+//
+//	redis.DefineConfig(c.WithPrefix("cache.redis."))
+//
+// The view defines its configs in the original ConfigSet, which is where they
+// must be loaded and read. Only use the view to define configs.
+func (c *ConfigSet) WithPrefix(prefix string) *ConfigSet {
+	view := *c
+	view.prefix = c.prefix + prefix
+	return &view
+}
+
+// WithPrefix returns a view of the global ConfigSet whose definition methods
+// prepend prefix to every name.
+func WithPrefix(prefix string) *ConfigSet {
+	return global().WithPrefix(prefix)
+}
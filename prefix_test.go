@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestWithPrefix(t *testing.T) {
+	c := NewConfigSet("prefix", ContinueOnError)
+	redis := c.WithPrefix("cache.redis.")
+	addr := redis.String("addr", "localhost:6379")
+	db := redis.Int("db", 0)
+	redis.Sensitive("addr")
+	pool := redis.WithPrefix("pool.").Int("size", 10)
+
+	err := c.parseBytes("prefix", []byte("[cache.redis]\naddr = \"redis:6379\"\ndb = 2\n[cache.redis.pool]\nsize = 50\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *addr != "redis:6379" || *db != 2 || *pool != 50 {
+		t.Error("Unexpected prefixed settings:", *addr, *db, *pool)
+	}
+	if !c.secrets["cache.redis.addr"] {
+		t.Error("cache.redis.addr should be marked sensitive")
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"flag"
+	"sort"
+)
+
+// Settings is a read-only copy of the values of a ConfigSet's config
+// variables at one point in time.
+type Settings struct {
+	info    LoadInfo
+	values  map[string]interface{}
+	strings map[string]string
+}
+
+// Current returns a copy of the current values of every config variable.
+func (c *ConfigSet) Current() *Settings {
+	s := &Settings{
+		info:    c.LoadInfo(),
+		values:  make(map[string]interface{}),
+		strings: make(map[string]string),
+	}
+	c.VisitAll(func(f *flag.Flag) {
+		s.strings[f.Name] = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			s.values[f.Name] = getter.Get()
+		} else {
+			s.values[f.Name] = s.strings[f.Name]
+		}
+	})
+	return s
+}
+
+// Previous returns the settings from before the most recent successful
+// Reload, or nil if the ConfigSet hasn't been reloaded. OnRotate callbacks
+// can use it to compute what a reload changed, and components can use it to
+// compare against the settings they were built with.
+func (c *ConfigSet) Previous() *Settings {
+	return c.previous
+}
+
+// LoadInfo describes the load the settings came from.
+func (s *Settings) LoadInfo() LoadInfo {
+	return s.info
+}
+
+// Get returns the value of the named config variable, or nil if it wasn't
+// defined. Values are returned as by ConfigSet.Get.
+func (s *Settings) Get(name string) interface{} {
+	return s.values[name]
+}
+
+// Changed returns the sorted names of the config variables whose values
+// differ between s and other.
+func (s *Settings) Changed(other *Settings) []string {
+	var names []string
+	for name, value := range s.strings {
+		if otherValue, ok := other.strings[name]; !ok || otherValue != value {
+			names = append(names, name)
+		}
+	}
+	for name := range other.strings {
+		if _, ok := s.strings[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Current returns a copy of the current values of the global config's
+// variables.
+func Current() *Settings {
+	return global().Current()
+}
+
+// Previous returns the global config's settings from before the most recent
+// successful Reload, or nil if it hasn't been reloaded.
+func Previous() *Settings {
+	return global().Previous()
+}
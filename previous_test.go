@@ -0,0 +1,54 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPrevious(t *testing.T) {
+	dir, err := ioutil.TempDir("", "previous")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.toml")
+	ioutil.WriteFile(path, []byte("port = 1\nhost = \"a\"\n"), 0644)
+
+	c := NewConfigSet("previous", ContinueOnError)
+	c.Int("port", 0)
+	c.String("host", "")
+	c.Bool("debug", false)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.Previous() != nil {
+		t.Error("Previous should be nil before a reload")
+	}
+
+	var changed []string
+	c.OnRotate("port", func(string) { changed = c.Previous().Changed(c.Current()) })
+	ioutil.WriteFile(path, []byte("port = 2\nhost = \"a\"\ndebug = true\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := c.Previous()
+	if prev.Get("port") != 1 || prev.LoadInfo().Generation != 1 {
+		t.Error("Unexpected previous settings:", prev.Get("port"), prev.LoadInfo())
+	}
+	if !reflect.DeepEqual(changed, []string{"debug", "port"}) {
+		t.Error("Unexpected changes:", changed)
+	}
+
+	// A failed reload leaves the previous settings alone.
+	ioutil.WriteFile(path, []byte("port = \"x\"\n"), 0644)
+	if err := c.Reload(); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if c.Previous() != prev {
+		t.Error("Previous shouldn't change after a failed reload")
+	}
+}
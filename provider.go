@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Provider is a source of config values other than a TOML file, such as the
+// Windows registry.
+type Provider interface {
+	// Name describes the source in errors and in Origin, such as
+	// `registry:HKLM\Software\MyApp`.
+	Name() string
+
+	// Load returns the settings from the source. Tables are represented by
+	// nested map[string]interface{} values, and values by the types the TOML
+	// decoder produces, such as string, int64, and []interface{}.
+	Load() (map[string]interface{}, error)
+}
+
+// ParseProvider loads the settings from a Provider into the ConfigSet. Use
+// Layers.Providers to load providers along with config files.
+func (c *ConfigSet) ParseProvider(p Provider) (err error) {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	c.source = func() error { return c.ParseProvider(p) }
+	err = c.beginLoad()
+	defer c.endLoad(&err)
+	if err != nil {
+		return err
+	}
+	return c.loadProvider(p, 0)
+}
+
+// loadProvider loads the settings from p, failing if they take longer than
+// timeout to load, unless it's zero.
+func (c *ConfigSet) loadProvider(p Provider, timeout time.Duration) error {
+	values, err := loadWithin(p, timeout)
+	if err != nil {
+		return err
+	}
+	tree, err := toml.TreeFromMap(values)
+	if err != nil {
+		return fmt.Errorf("%s: %s", p.Name(), err)
+	}
+	c.trees = append(c.trees, tree)
+	c.startSource(p.Name())
+	return c.loadTomlTree(tree, []string{})
+}
+
+// loadWithin calls p.Load, returning an error if it doesn't return within
+// timeout, unless it's zero. A Load that times out keeps running in the
+// background, and its result is discarded.
+func loadWithin(p Provider, timeout time.Duration) (map[string]interface{}, error) {
+	if timeout == 0 {
+		return p.Load()
+	}
+	type result struct {
+		values map[string]interface{}
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := p.Load()
+		done <- result{values, err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.values, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+type timeoutProvider struct {
+	Provider
+	timeout time.Duration
+}
+
+// WithTimeout returns a Provider that fails if p takes longer than timeout to
+// load, so that a hung source such as a remote secret store can't block
+// startup indefinitely. Use Layers.Deadline to limit the time spent loading
+// every provider.
+func WithTimeout(p Provider, timeout time.Duration) Provider {
+	return &timeoutProvider{p, timeout}
+}
+
+func (p *timeoutProvider) Load() (map[string]interface{}, error) {
+	return loadWithin(p.Provider, p.timeout)
+}
+
+// ParseProvider loads the settings from a Provider.
+func ParseProvider(p Provider) error {
+	return handleGlobalError(global().ParseProvider(p))
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapProvider struct {
+	values map[string]interface{}
+	err    error
+}
+
+func (p mapProvider) Name() string { return "map" }
+
+func (p mapProvider) Load() (map[string]interface{}, error) { return p.values, p.err }
+
+func TestParseProvider(t *testing.T) {
+	c := NewConfigSet("provider", ContinueOnError)
+	port := c.Int("server.port", 8080)
+	host := c.String("server.host", "localhost")
+
+	p := mapProvider{values: map[string]interface{}{
+		"server": map[string]interface{}{"port": int64(9000)},
+	}}
+	if err := c.ParseProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || c.Origin("server.port") != "map" {
+		t.Error("server.port should be loaded from the provider, is", *port, c.Origin("server.port"))
+	}
+
+	err := c.ParseLayers(Layers{
+		Baseline:  []byte("[server]\nport = 80\nhost = \"example.com\"\n"),
+		Providers: []Provider{p},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *port != 9000 || *host != "example.com" {
+		t.Error("The provider should override the baseline, is", *port, *host)
+	}
+
+	err = c.ParseLayers(Layers{Providers: []Provider{mapProvider{err: errors.New("unavailable")}}})
+	if err == nil || err.Error() != "map config: unavailable" {
+		t.Error("Expected a provider error, got", err)
+	}
+}
+
+// slowProvider is a Provider that takes a while to load.
+type slowProvider struct {
+	name  string
+	delay time.Duration
+}
+
+func (p slowProvider) Name() string { return p.name }
+
+func (p slowProvider) Load() (map[string]interface{}, error) {
+	time.Sleep(p.delay)
+	return map[string]interface{}{"port": int64(9000)}, nil
+}
+
+func TestProviderTimeouts(t *testing.T) {
+	c := NewConfigSet("provider", ContinueOnError)
+	port := c.Int("port", 8080)
+
+	err := c.ParseProvider(WithTimeout(slowProvider{"vault", time.Second}, 10*time.Millisecond))
+	if err == nil || err.Error() != "timed out after 10ms" {
+		t.Error("Unexpected error:", err)
+	}
+
+	err = c.ParseProvider(WithTimeout(slowProvider{"vault", 0}, time.Second))
+	if err != nil || *port != 9000 {
+		t.Error("Unexpected result:", err, *port)
+	}
+
+	*port = 8080
+	err = c.ParseLayers(Layers{
+		Providers: []Provider{slowProvider{"vault", 50 * time.Millisecond}, slowProvider{"http", 0}},
+		Deadline:  10 * time.Millisecond,
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "vault config: timed out after ") {
+		t.Error("Unexpected error:", err)
+	}
+
+	var out bytes.Buffer
+	c.SetLogger(log.New(&out, "", 0))
+	err = c.ParseLayers(Layers{
+		Baseline:          []byte("port = 8081\n"),
+		Providers:         []Provider{WithTimeout(slowProvider{"vault", time.Second}, 10*time.Millisecond)},
+		OptionalProviders: true,
+	})
+	if err != nil || *port != 8081 {
+		t.Error("Unexpected result:", err, *port)
+	}
+	if out.String() != "config: vault: timed out after 10ms; using the settings from other layers\n" {
+		t.Error("Unexpected warning:", out.String())
+	}
+}
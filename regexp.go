@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+)
+
+// -- regexp.Regexp Value
+
+type regexpValue struct{ p **regexp.Regexp }
+
+func (r regexpValue) Set(s string) error {
+	v, err := regexp.Compile(s)
+	if err != nil {
+		if se, ok := err.(*syntax.Error); ok {
+			return fmt.Errorf("%q is not a valid regular expression: %s: %s", s, se.Code, se.Expr)
+		}
+		return fmt.Errorf("%q is not a valid regular expression", s)
+	}
+	*r.p = v
+	return nil
+}
+
+func (r regexpValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return r.Set(s)
+	}
+	return errParse
+}
+
+func (r regexpValue) Get() interface{} { return *r.p }
+
+func (r regexpValue) snapshot() func() error {
+	v := *r.p
+	return func() error {
+		*r.p = v
+		return nil
+	}
+}
+
+func (r regexpValue) String() string {
+	if r.p == nil || *r.p == nil {
+		return ""
+	}
+	return (*r.p).String()
+}
+
+// RegexpVar defines a regular expression config with a given name and default
+// value for a ConfigSet. The argument p points to a *regexp.Regexp variable
+// in which to store the value of the config. Patterns use the syntax of the
+// regexp package and are compiled when the config is set, so an invalid
+// pattern fails the load. An empty default leaves p nil. It panics if the
+// default doesn't compile.
+func (c *ConfigSet) RegexpVar(p **regexp.Regexp, name string, value string) {
+	v := regexpValue{p}
+	if value == "" {
+		*p = nil
+	} else if err := v.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(v, name, "")
+}
+
+// Regexp defines a regular expression config variable with a given name and
+// default value for a ConfigSet. Since TOML's literal strings don't process
+// escapes, patterns are easiest to write in single quotes, such as
+// '^/api/v\d+/'.
+func (c *ConfigSet) Regexp(name string, value string) **regexp.Regexp {
+	p := new(*regexp.Regexp)
+	c.RegexpVar(p, name, value)
+	return p
+}
+
+// RegexpVar defines a regular expression config with a given name and default
+// value. The argument p points to a *regexp.Regexp variable in which to store
+// the value of the config.
+func RegexpVar(p **regexp.Regexp, name string, value string) {
+	global().RegexpVar(p, name, value)
+}
+
+// Regexp defines a regular expression config variable with a given name and
+// default value.
+func Regexp(name string, value string) **regexp.Regexp {
+	return global().Regexp(name, value)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRegexp(t *testing.T) {
+	c := NewConfigSet("regexp", ContinueOnError)
+	route := c.Regexp("route", `^/$`)
+	ignore := c.Regexp("ignore", "")
+
+	if *ignore != nil || !(*route).MatchString("/") {
+		t.Fatal("Unexpected defaults:", *route, *ignore)
+	}
+	if err := c.parseBytes("regexp", []byte(`route = '^/api/v\d+/'`)); err != nil {
+		t.Fatal(err)
+	}
+	if !(*route).MatchString("/api/v2/users") || (*route).MatchString("/api/beta/") {
+		t.Error("Unexpected pattern:", *route)
+	}
+	if s := c.Lookup("route").Value.String(); s != `^/api/v\d+/` {
+		t.Error("Unexpected string:", s)
+	}
+
+	err := c.parseBytes("regexp", []byte(`ignore = '(\.tmp'`))
+	if err == nil || err.Error() != `The value for ignore is invalid: "(\\.tmp" is not a valid regular expression: missing closing ): (\.tmp` {
+		t.Error("Expected an invalid pattern error, got", err)
+	}
+}
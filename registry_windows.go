@@ -0,0 +1,100 @@
+//go:build windows
+
+package config
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryRoots names the predefined registry keys for use in Name.
+var registryRoots = map[registry.Key]string{
+	registry.CLASSES_ROOT:   "HKCR",
+	registry.CURRENT_USER:   "HKCU",
+	registry.LOCAL_MACHINE:  "HKLM",
+	registry.USERS:          "HKU",
+	registry.CURRENT_CONFIG: "HKCC",
+}
+
+type registryProvider struct {
+	root registry.Key
+	path string
+}
+
+// Registry returns a Provider that reads settings from the registry key at
+// path under root, such as registry.LOCAL_MACHINE and `Software\MyApp`.
+// Values are config settings and subkeys are tables, so the value port under
+// `Software\MyApp\server` sets "server.port". Names are matched exactly.
+// String values are loaded as
+// strings, DWORD and QWORD values as integers, and multi-string values as
+// arrays of strings. A missing key provides no settings.
+func Registry(root registry.Key, path string) Provider {
+	return &registryProvider{root, path}
+}
+
+func (r *registryProvider) Name() string {
+	return "registry:" + registryRoots[r.root] + `\` + r.path
+}
+
+func (r *registryProvider) Load() (map[string]interface{}, error) {
+	key, err := registry.OpenKey(r.root, r.path, registry.READ)
+	if err == registry.ErrNotExist {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+	return loadRegistryKey(key)
+}
+
+func loadRegistryKey(key registry.Key) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		_, valtype, err := key.GetValue(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch valtype {
+		case registry.SZ, registry.EXPAND_SZ:
+			values[name], _, err = key.GetStringValue(name)
+		case registry.DWORD, registry.QWORD:
+			var v uint64
+			v, _, err = key.GetIntegerValue(name)
+			values[name] = int64(v)
+		case registry.MULTI_SZ:
+			var strs []string
+			strs, _, err = key.GetStringsValue(name)
+			array := make([]interface{}, len(strs))
+			for i, s := range strs {
+				array[i] = s
+			}
+			values[name] = array
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subkeys, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range subkeys {
+		subkey, err := registry.OpenKey(key, name, registry.READ)
+		if err != nil {
+			return nil, err
+		}
+		values[name], err = loadRegistryKey(subkey)
+		subkey.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,45 @@
+//go:build windows
+
+package config
+
+import (
+	"testing"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	const path = `Software\go-toml-config-test`
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, path, registry.ALL_ACCESS)
+	if err != nil {
+		t.Skip("Can't create a registry key:", err)
+	}
+	defer registry.DeleteKey(registry.CURRENT_USER, path)
+	defer key.Close()
+
+	key.SetStringValue("name", "svc")
+	server, _, err := registry.CreateKey(key, "server", registry.ALL_ACCESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registry.DeleteKey(key, "server")
+	defer server.Close()
+	server.SetDWordValue("port", 9000)
+	server.SetStringsValue("hosts", []string{"a", "b"})
+
+	c := NewConfigSet("registry", ContinueOnError)
+	name := c.String("name", "")
+	port := c.Int("server.port", 8080)
+	hosts := c.StringSet("server.hosts", nil)
+
+	p := Registry(registry.CURRENT_USER, path)
+	if err := c.ParseProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "svc" || *port != 9000 || len(*hosts) != 2 {
+		t.Error("Unexpected settings:", *name, *port, *hosts)
+	}
+	if origin := c.Origin("server.port"); origin != `registry:HKCU\Software\go-toml-config-test` {
+		t.Error("Unexpected origin:", origin)
+	}
+}
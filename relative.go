@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveRelative makes the named config variables, which hold file paths,
+// interpret relative paths relative to the directory containing the config
+// file that sets them, rather than the process's working directory, as nginx
+// and systemd do. A config file at "/etc/myapp/app.conf" that sets
+//
+//	tls_cert = "certs/server.pem"
+//
+// sets tls_cert to "/etc/myapp/certs/server.pem". Values that are arrays of
+// strings have each element resolved. Values from other sources, such as
+// environment variables and flags, are left alone. It panics if a config
+// hasn't been defined.
+func (c *ConfigSet) ResolveRelative(names ...string) {
+	for _, name := range names {
+		if c.Lookup(c.prefix+name) == nil {
+			panic(fmt.Sprintf("config: can't resolve undefined config %s", c.prefix+name))
+		}
+		c.relative[c.prefix+name] = true
+	}
+}
+
+// resolvePaths resolves the relative paths in a decoded TOML value against
+// the directory of the file being loaded, if a file is being loaded.
+func (c *ConfigSet) resolvePaths(value interface{}) interface{} {
+	dir, ok := c.loadingDir()
+	if !ok {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return resolvePath(dir, v)
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, elem := range v {
+			if s, ok := elem.(string); ok {
+				resolved[i] = resolvePath(dir, s)
+			} else {
+				resolved[i] = elem
+			}
+		}
+		return resolved
+	}
+	return value
+}
+
+// loadingDir returns the directory of the config file being loaded, or false
+// if the source being loaded isn't a file.
+func (c *ConfigSet) loadingDir() (string, bool) {
+	for _, path := range c.info.Paths {
+		if path == c.loading {
+			return filepath.Dir(path), true
+		}
+	}
+	return "", false
+}
+
+// resolvePath joins a relative path to dir. Empty and absolute paths are
+// returned unchanged, as are paths starting with "~" or "$", which are
+// expanded by Path configs.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~") || strings.HasPrefix(path, "$") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// ResolveRelative makes the named config variables in the global config
+// interpret relative paths relative to the directory containing the config
+// file that sets them.
+func ResolveRelative(names ...string) {
+	global().ResolveRelative(names...)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveRelative(t *testing.T) {
+	dir, err := ioutil.TempDir("", "relative")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+	ioutil.WriteFile(path, []byte(`
+cert = "certs/server.pem"
+key = "/etc/ssl/server.key"
+log = "logs/app.log"
+includes = ["a.conf", "/b.conf"]
+`), 0644)
+
+	c := NewConfigSet("relative", ContinueOnError)
+	cert := c.String("cert", "")
+	key := c.String("key", "")
+	log := c.String("log", "")
+	includes := c.StringSet("includes", nil)
+	c.ResolveRelative("cert", "key", "includes")
+
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if *cert != filepath.Join(dir, "certs/server.pem") || *key != "/etc/ssl/server.key" || *log != "logs/app.log" {
+		t.Error("Unexpected paths:", *cert, *key, *log)
+	}
+	if !reflect.DeepEqual(*includes, []string{filepath.Join(dir, "a.conf"), "/b.conf"}) {
+		t.Error("Unexpected includes:", *includes)
+	}
+
+	os.Setenv("RELATIVE_CERT", "env.pem")
+	defer os.Unsetenv("RELATIVE_CERT")
+	if err := c.ParseLayers(Layers{User: path, EnvPrefix: "relative"}); err != nil {
+		t.Fatal(err)
+	}
+	if *cert != "env.pem" {
+		t.Error("Paths from the environment should be left alone, is", *cert)
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/pelletier/go-toml"
+)
+
+// errNoSource is returned by Reload if nothing has been loaded yet.
+var errNoSource = errors.New("config: nothing has been loaded to reload")
+
+// snapshotter is implemented by config values that can save a copy of their
+// state, so that it can be restored exactly rather than through String and
+// Set, which can't round-trip every value.
+type snapshotter interface {
+	// snapshot returns a function that restores the value's current state.
+	snapshot() func() error
+}
+
+// snapshot holds the state of a ConfigSet before a load, so that it can be
+// restored if the load fails.
+type snapshot struct {
+	restorers []restorer
+	stats     Stats
+	info      LoadInfo
+	applied   map[string]string
+	trees     []*toml.Tree
+}
+
+// restorer restores the named config's saved state.
+type restorer struct {
+	name    string
+	restore func() error
+}
+
+func (c *ConfigSet) takeSnapshot() *snapshot {
+	s := &snapshot{stats: c.stats, info: c.info, applied: c.applied, trees: c.trees}
+	c.VisitAll(func(f *flag.Flag) {
+		s.restorers = append(s.restorers, restorer{f.Name, saveValue(f.Value)})
+	})
+	return s
+}
+
+// saveValue returns a function that restores a config value's current state.
+// Values that don't implement snapshotter are restored by passing their
+// String form to Set.
+func saveValue(value flag.Value) func() error {
+	if v, ok := value.(snapshotter); ok {
+		return v.snapshot()
+	}
+	str := value.String()
+	return func() error { return value.Set(str) }
+}
+
+// restore puts the ConfigSet back into the state it was in when the snapshot
+// was taken. Settings under a Wildcard aren't restored. It returns an error
+// naming each config that couldn't be restored.
+func (c *ConfigSet) restore(s *snapshot) error {
+	var errs []error
+	for _, r := range s.restorers {
+		if err := r.restore(); err != nil {
+			errs = append(errs, fmt.Errorf("config: can't restore %s: %s", r.name, err))
+		}
+	}
+	c.stats, c.info, c.applied, c.trees = s.stats, s.info, s.applied, s.trees
+	return errors.Join(errs...)
+}
+
+// Reload loads the sources given to the most recent Parse, ParseFiles,
+// ParseDir, or ParseLayers call again. Configs whose settings have been
+// removed go back to their defaults. If they fail to load, every config
+// variable is restored to the value it had before the reload and the error is
+// returned, so a bad edit never leaves the ConfigSet half-updated. If a
+// variable can't be restored, that's reported along with the load error. A
+// reload of files that haven't changed is skipped if SetLoadCache enables it.
+// It's safe to call from several goroutines; reloads are done one at a time.
+func (c *ConfigSet) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reload()
+}
+
+func (c *ConfigSet) reload() error {
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	if c.source == nil {
+		return errNoSource
+	}
+	if c.unchanged(c.cache.key) {
+		return nil
+	}
+	s := c.takeSnapshot()
+	previous := c.Current()
+	c.reloading = true
+	err := c.source()
+	c.reloading = false
+	if err != nil {
+		if restoreErr := c.restore(s); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+	c.previous, c.rollback = previous, s
+	c.checkRotations(true)
+	return nil
+}
+
+// ReloadResult is the JSON response written by the handler returned by
+// ReloadHandler.
+type ReloadResult struct {
+	OK          bool   `json:"ok"`
+	Error       string `json:"error,omitempty"`
+	KeysApplied int    `json:"keys_applied"`
+	FileHash    string `json:"file_hash,omitempty"`
+}
+
+// ReloadHandler returns an http.Handler that calls Reload for POST requests
+// and responds with a ReloadResult, so that orchestration tools can push new
+// config and confirm that it took effect. The response status is 200 if the
+// reload succeeded and 422 if it failed. It's usually mounted at "/-/reload":
+//
+//	http.Handle("/-/reload", config.ReloadHandler())
+//
+// Requests are handled one at a time.
+func (c *ConfigSet) ReloadHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		c.mu.Lock()
+		err := c.reload()
+		stats := c.stats
+		c.mu.Unlock()
+
+		result := ReloadResult{OK: err == nil, KeysApplied: stats.KeysApplied, FileHash: stats.FileHash}
+		status := http.StatusOK
+		if err != nil {
+			result.Error = err.Error()
+			status = http.StatusUnprocessableEntity
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// Reload loads the sources given to the most recent Parse call again,
+// restoring the previous values if they fail to load.
+func Reload() error {
+	return handleGlobalError(global().Reload())
+}
+
+// ReloadHandler returns an http.Handler that reloads the config for POST
+// requests and responds with a JSON ReloadResult.
+func ReloadHandler() http.Handler {
+	return global().ReloadHandler()
+}
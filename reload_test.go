@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+
+	c := NewConfigSet("reload", ContinueOnError)
+	if err := c.Reload(); err != errNoSource {
+		t.Error("Expected a nothing to reload error, got", err)
+	}
+
+	port := c.Int("port", 8080)
+	host := c.String("host", "localhost")
+	debug := c.OptionalBool("debug")
+
+	ioutil.WriteFile(path, []byte("port = 80\nhost = \"a.example.com\"\n"), 0644)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(path, []byte("port = 81\ndebug = true\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 81 {
+		t.Error("port should be reloaded, is", *port)
+	}
+	if v, ok := debug.Get(); !v || !ok {
+		t.Error("debug should be reloaded")
+	}
+	if *host != "localhost" || c.Provided("host") || c.Origin("host") != "default" || c.Stats().DefaultsUsed != 1 {
+		t.Error("host should go back to its default when it's removed, is", *host, c.Origin("host"), c.Stats())
+	}
+
+	ioutil.WriteFile(path, []byte("port = 82\ndebug = false\nhost = 1.5\nbogus = 1\n"), 0644)
+	err = c.Reload()
+	if err == nil || err.Error() != "bogus is not a valid config setting" {
+		t.Fatal("Expected an invalid setting error, got", err)
+	}
+	if *port != 81 || *host != "localhost" {
+		t.Error("Settings should be restored after a failed reload, are", *port, *host)
+	}
+	if v, ok := debug.Get(); !v || !ok {
+		t.Error("debug should be restored after a failed reload")
+	}
+	if c.Stats().KeysApplied != 2 {
+		t.Error("Stats should be restored after a failed reload, are", c.Stats())
+	}
+}
+
+func TestReloadRestoresValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	c := NewConfigSet("reload", ContinueOnError)
+	mode := c.TriBool("mode")
+	email := c.Email("email", "")
+	tags := c.StringSlice("tags", nil)
+	feature := c.Flag("feature")
+	var tree interface{}
+	c.TreeFunc("tree", func(value interface{}) error {
+		tree = value
+		return nil
+	})
+
+	ioutil.WriteFile(path, []byte("tags = [\"a,b\", \"c\"]\nfeature = 25\ntree = 1\n"), 0644)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(path, []byte("mode = true\nemail = \"a@example.com\"\ntags = [\"d\"]\nfeature = 50\ntree = 2\nbogus = 1\n"), 0644)
+	if err := c.Reload(); err == nil {
+		t.Fatal("Expected an invalid setting error")
+	}
+	if *mode != TriUnset {
+		t.Error("mode should be restored, is", *mode)
+	}
+	if *email != "" {
+		t.Error("email should be restored, is", *email)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a,b", "c"}) {
+		t.Errorf("tags should be restored, are %q", *tags)
+	}
+	if feature.Percent() != 25 {
+		t.Error("feature should be restored, is", feature.Percent())
+	}
+	if tree != int64(1) {
+		t.Error("tree should be passed its previous value, got", tree)
+	}
+}
+
+func TestReloadHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.conf")
+
+	c := NewConfigSet("reload", ContinueOnError)
+	port := c.Int("port", 8080)
+	ioutil.WriteFile(path, []byte("port = 80\n"), 0644)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	handler := c.ReloadHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/-/reload", nil))
+	if w.Code != 405 {
+		t.Error("Expected a 405 for GET, got", w.Code)
+	}
+
+	ioutil.WriteFile(path, []byte("port = 81\n"), 0644)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/-/reload", nil))
+	var result ReloadResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != 200 || !result.OK || result.KeysApplied != 1 || *port != 81 {
+		t.Error("Unexpected reload response:", w.Code, w.Body.String())
+	}
+
+	ioutil.WriteFile(path, []byte("port = \"eighty\"\n"), 0644)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/-/reload", nil))
+	result = ReloadResult{}
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != 422 || result.OK || result.Error != "The value for port is invalid" || *port != 81 {
+		t.Error("Unexpected reload response:", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"flag"
+)
+
+// Report is a structured summary of a ConfigSet's settings, suitable for
+// attaching to support tickets as JSON.
+type Report struct {
+	// Info identifies the configuration that was loaded.
+	Info LoadInfo `json:"info"`
+	// Settings describes every config variable, sorted by name.
+	Settings []ReportSetting `json:"settings"`
+}
+
+// ReportSetting describes a config variable in a Report. The values of
+// configs marked Sensitive are redacted.
+type ReportSetting struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	// Overridden reports whether a source set the config, even if it was set
+	// to its default value.
+	Overridden bool `json:"overridden"`
+	// Source is where the value came from, as returned by Origin.
+	Source    string `json:"source"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// Report returns a summary of every config variable's value, whether it was
+// overridden or left at its default, and where it came from.
+func (c *ConfigSet) Report() Report {
+	r := Report{Info: c.LoadInfo(), Settings: []ReportSetting{}}
+	c.VisitAll(func(f *flag.Flag) {
+		s := ReportSetting{
+			Name:       f.Name,
+			Value:      f.Value.String(),
+			Default:    f.DefValue,
+			Overridden: c.applied[f.Name] != "",
+			Source:     c.Origin(f.Name),
+			Sensitive:  c.secrets[f.Name],
+		}
+		if s.Sensitive {
+			s.Value, s.Default = redacted, redacted
+		}
+		r.Settings = append(r.Settings, s)
+	})
+	return r
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	c := NewConfigSet("report", ContinueOnError)
+	c.String("db.password", "")
+	c.String("db.host", "localhost")
+	c.Int("server.port", 8080)
+	c.Sensitive("db.password")
+
+	err := c.ParseLayers(Layers{
+		Baseline: []byte("[db]\nhost = \"localhost\"\npassword = \"hunter2\"\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.Report()
+	expected := []ReportSetting{
+		{Name: "db.host", Value: "localhost", Default: "localhost", Overridden: true, Source: "baseline:2"},
+		{Name: "db.password", Value: "[redacted]", Default: "[redacted]", Overridden: true, Source: "baseline:3", Sensitive: true},
+		{Name: "server.port", Value: "8080", Default: "8080", Source: "default"},
+	}
+	if !reflect.DeepEqual(r.Settings, expected) {
+		t.Errorf("Unexpected settings: %+v", r.Settings)
+	}
+	if r.Info.Generation != 1 {
+		t.Error("Unexpected info:", r.Info)
+	}
+
+	data, err := json.Marshal(r.Settings[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"server.port","value":"8080","default":"8080","overridden":false,"source":"default"}` {
+		t.Error("Unexpected JSON:", string(data))
+	}
+}
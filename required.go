@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingKeysError is returned by Parse and the other load methods when
+// configs marked with Required aren't set by any source. It's only returned
+// if the sources were otherwise loaded successfully.
+type MissingKeysError struct {
+	// Keys are the names of the missing configs, sorted.
+	Keys []string
+	// Sources are the sources that were consulted, in the order they were
+	// loaded, such as "app.conf" or "env".
+	Sources []string
+}
+
+func (e *MissingKeysError) Error() string {
+	sources := "no sources were loaded"
+	if len(e.Sources) > 0 {
+		sources = "consulted " + strings.Join(e.Sources, ", ")
+	}
+	return fmt.Sprintf("required config settings are missing: %s (%s)", strings.Join(e.Keys, ", "), sources)
+}
+
+// Required marks the named config variables as required, so that loading
+// returns a *MissingKeysError listing those that no source sets. Values that
+// are set to the default are not missing. It panics if a config hasn't been
+// defined.
+func (c *ConfigSet) Required(names ...string) {
+	for _, name := range names {
+		if c.Lookup(c.prefix+name) == nil {
+			panic(fmt.Sprintf("config: can't require undefined config %s", c.prefix+name))
+		}
+		c.required[c.prefix+name] = true
+	}
+}
+
+// checkRequired returns a *MissingKeysError if any required configs weren't
+// set by the current load.
+func (c *ConfigSet) checkRequired() error {
+	var missing []string
+	for name := range c.required {
+		if c.applied[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &MissingKeysError{Keys: missing, Sources: append([]string(nil), c.sources...)}
+}
+
+// Required marks the named config variables in the global config as required.
+func Required(names ...string) {
+	global().Required(names...)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	c := NewConfigSet("required", ContinueOnError)
+	c.String("db.url", "")
+	c.String("db.user", "")
+	c.String("api.key", "")
+	c.Int("port", 8080)
+	c.Required("db.url", "db.user", "api.key")
+
+	os.Setenv("REQUIRED_API_KEY", "secret")
+	defer os.Unsetenv("REQUIRED_API_KEY")
+
+	err := c.ParseLayers(Layers{
+		Baseline:  []byte("[db]\nuser = \"\"\n"),
+		EnvPrefix: "required",
+	})
+	mke, ok := err.(*MissingKeysError)
+	if !ok {
+		t.Fatal("Expected a *MissingKeysError, got", err)
+	}
+	if !reflect.DeepEqual(mke.Keys, []string{"db.url"}) || !reflect.DeepEqual(mke.Sources, []string{"baseline", "env"}) {
+		t.Error("Unexpected error:", mke.Keys, mke.Sources)
+	}
+	if err.Error() != "required config settings are missing: db.url (consulted baseline, env)" {
+		t.Error("Unexpected message:", err)
+	}
+
+	// Syntax and type errors are reported instead.
+	err = c.ParseLayers(Layers{Baseline: []byte("port = \"x\"\n")})
+	if _, ok := err.(*LayerError); !ok {
+		t.Error("Expected a *LayerError, got", err)
+	}
+
+	c.SetEnvPrefix("required")
+	err = c.ParseEnvOnly()
+	if err == nil || err.Error() != "required config settings are missing: db.url, db.user (consulted env)" {
+		t.Error("Unexpected error:", err)
+	}
+}
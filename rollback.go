@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+)
+
+// errNoRollback is returned by Rollback if there's nothing to roll back.
+var errNoRollback = errors.New("config: there is no previous configuration to roll back to")
+
+// Rollback restores every config variable to the value it had before the
+// most recent successful Reload or committed transaction and calls the OnRotate callbacks for the
+// configs that change, so that an operator or an automated health check can
+// back out a bad configuration without a restart. Afterwards, Previous
+// returns the settings that were rolled back. A change can only be rolled
+// back once; Rollback returns an error if there's nothing to roll back, and a
+// *FrozenError if the ConfigSet has been frozen. If some configs can't be
+// restored, the rest are, and the error names the ones that weren't.
+func (c *ConfigSet) Rollback() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkFrozen(); err != nil {
+		return err
+	}
+	if c.rollback == nil {
+		return errNoRollback
+	}
+	c.previous = c.Current()
+	err := c.restore(c.rollback)
+	c.rollback = nil
+	c.invalidateCache()
+	c.checkRotations(true)
+	return err
+}
+
+// Rollback restores the global config to the values it had before the most
+// recent successful Reload or committed transaction.
+func Rollback() error {
+	return global().Rollback()
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.toml")
+	ioutil.WriteFile(path, []byte("workers = 4\n"), 0644)
+
+	c := NewConfigSet("rollback", ContinueOnError)
+	workers := c.Int("workers", 1)
+	if err := c.Rollback(); err != errNoRollback {
+		t.Error("Expected errNoRollback before loading, got", err)
+	}
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var rotated []string
+	c.OnRotate("workers", func(value string) { rotated = append(rotated, value) })
+	ioutil.WriteFile(path, []byte("workers = 0\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if *workers != 4 || c.Origin("workers") != path+":1" || c.LoadInfo().Generation != 1 {
+		t.Error("Unexpected settings after Rollback:", *workers, c.Origin("workers"), c.LoadInfo())
+	}
+	if len(rotated) != 2 || rotated[0] != "0" || rotated[1] != "4" {
+		t.Error("Unexpected callbacks:", rotated)
+	}
+	if c.Previous().Get("workers") != 0 {
+		t.Error("Previous should return the settings that were rolled back")
+	}
+	if err := c.Rollback(); err != errNoRollback {
+		t.Error("Expected errNoRollback after rolling back, got", err)
+	}
+
+	c.Freeze()
+	if _, ok := c.Rollback().(*FrozenError); !ok {
+		t.Error("Expected a FrozenError")
+	}
+}
+
+func TestRollbackRestoresValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	ioutil.WriteFile(path, []byte("tags = [\"a,b\", \"c\"]\n"), 0644)
+
+	c := NewConfigSet("rollback", ContinueOnError)
+	mode := c.TriBool("mode")
+	email := c.Email("email", "")
+	ip := c.IP("ip", nil)
+	tags := c.StringSlice("tags", nil)
+	if err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(path, []byte("mode = false\nemail = \"a@example.com\"\nip = \"10.0.0.1\"\ntags = [\"d\"]\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if *mode != TriUnset {
+		t.Error("mode should be rolled back, is", *mode)
+	}
+	if *email != "" {
+		t.Error("email should be rolled back, is", *email)
+	}
+	if *ip != nil {
+		t.Error("ip should be rolled back, is", *ip)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a,b", "c"}) {
+		t.Errorf("tags should be rolled back, are %q", *tags)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+)
+
+// rotation holds the callbacks for a config registered with OnRotate.
+type rotation struct {
+	last string
+	fns  []func(newValue string)
+}
+
+// OnRotate registers fn to be called with the new value of the named config,
+// usually a secret such as "db.password" loaded from a Provider, whenever a
+// Reload changes it. This lets connection pools and clients re-authenticate
+// without a restart. Callbacks are called after the reload has completed, in
+// the order they were registered, and aren't called for the initial load.
+// They're called before Reload returns, so they must not call Reload,
+// Rollback, Tx.Commit, or Dump. It panics if the config hasn't been defined.
+func (c *ConfigSet) OnRotate(name string, fn func(newValue string)) {
+	f := c.Lookup(c.prefix + name)
+	if f == nil {
+		panic(fmt.Sprintf("config: can't watch undefined config %s", c.prefix+name))
+	}
+	r := c.rotations[f.Name]
+	if r == nil {
+		r = &rotation{last: f.Value.String()}
+		c.rotations[f.Name] = r
+	}
+	r.fns = append(r.fns, fn)
+}
+
+// checkRotations calls the OnRotate callbacks for the configs whose values
+// have changed since the last check. If notify is false, the new values are
+// recorded without calling the callbacks.
+func (c *ConfigSet) checkRotations(notify bool) {
+	for name, r := range c.rotations {
+		value := c.Lookup(name).Value.String()
+		if value == r.last {
+			continue
+		}
+		r.last = value
+		if notify {
+			for _, fn := range r.fns {
+				fn(value)
+			}
+		}
+	}
+}
+
+// OnRotate registers fn to be called with the new value of the named config
+// whenever a Reload changes it.
+func OnRotate(name string, fn func(newValue string)) {
+	global().OnRotate(name, fn)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestOnRotate(t *testing.T) {
+	c := NewConfigSet("rotate", ContinueOnError)
+	c.String("db.password", "")
+	c.String("db.user", "")
+	c.Sensitive("db.password")
+
+	var rotated []string
+	c.OnRotate("db.password", func(newValue string) {
+		rotated = append(rotated, newValue)
+	})
+
+	secrets := map[string]interface{}{"db": map[string]interface{}{"password": "v1", "user": "app"}}
+	p := mapProvider{values: secrets}
+	if err := c.ParseProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 0 {
+		t.Error("The initial load shouldn't count as a rotation:", rotated)
+	}
+
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 0 {
+		t.Error("An unchanged secret shouldn't count as a rotation:", rotated)
+	}
+
+	secrets["db"].(map[string]interface{})["password"] = "v2"
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 || rotated[0] != "v2" {
+		t.Error("Expected a rotation to v2, got", rotated)
+	}
+
+	secrets["db"].(map[string]interface{})["password"] = "v3"
+	secrets["db"].(map[string]interface{})["port"] = int64(5432)
+	if err := c.Reload(); err == nil {
+		t.Fatal("Expected the reload to fail")
+	}
+	if len(rotated) != 1 {
+		t.Error("A failed reload shouldn't count as a rotation:", rotated)
+	}
+}
@@ -0,0 +1,472 @@
+package config
+
+import (
+	"math"
+	"strconv"
+)
+
+// sizedInt converts a decoded TOML value to an integer that fits in the given
+// number of bits.
+func sizedInt(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		if v < -1<<(bits-1) || v > 1<<(bits-1)-1 {
+			return 0, errRange
+		}
+		return v, nil
+	case string:
+		n, err := strconv.ParseInt(v, 0, bits)
+		if err != nil {
+			return 0, numError(err)
+		}
+		return n, nil
+	}
+	return 0, errParse
+}
+
+// -- int8 Value
+
+type int8Value int8
+
+func (i *int8Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *int8Value) setTOML(value interface{}) error {
+	v, err := sizedInt(value, 8)
+	if err != nil {
+		return err
+	}
+	*i = int8Value(v)
+	return nil
+}
+
+func (i *int8Value) Get() interface{} { return int8(*i) }
+
+func (i *int8Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *int8Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- int16 Value
+
+type int16Value int16
+
+func (i *int16Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *int16Value) setTOML(value interface{}) error {
+	v, err := sizedInt(value, 16)
+	if err != nil {
+		return err
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) Get() interface{} { return int16(*i) }
+
+func (i *int16Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *int16Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- int32 Value
+
+type int32Value int32
+
+func (i *int32Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *int32Value) setTOML(value interface{}) error {
+	v, err := sizedInt(value, 32)
+	if err != nil {
+		return err
+	}
+	*i = int32Value(v)
+	return nil
+}
+
+func (i *int32Value) Get() interface{} { return int32(*i) }
+
+func (i *int32Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *int32Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// sizedUint converts a decoded TOML value to an unsigned integer that fits in
+// the given number of bits.
+func sizedUint(value interface{}, bits int) (uint64, error) {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 || uint64(v) > 1<<bits-1 {
+			return 0, errRange
+		}
+		return uint64(v), nil
+	case uint64:
+		if v > 1<<bits-1 {
+			return 0, errRange
+		}
+		return v, nil
+	case string:
+		n, err := strconv.ParseUint(v, 0, bits)
+		if err != nil {
+			return 0, numError(err)
+		}
+		return n, nil
+	}
+	return 0, errParse
+}
+
+// -- uint8 Value
+
+type uint8Value uint8
+
+func (i *uint8Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *uint8Value) setTOML(value interface{}) error {
+	v, err := sizedUint(value, 8)
+	if err != nil {
+		return err
+	}
+	*i = uint8Value(v)
+	return nil
+}
+
+func (i *uint8Value) Get() interface{} { return uint8(*i) }
+
+func (i *uint8Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *uint8Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint16 Value
+
+type uint16Value uint16
+
+func (i *uint16Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *uint16Value) setTOML(value interface{}) error {
+	v, err := sizedUint(value, 16)
+	if err != nil {
+		return err
+	}
+	*i = uint16Value(v)
+	return nil
+}
+
+func (i *uint16Value) Get() interface{} { return uint16(*i) }
+
+func (i *uint16Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *uint16Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint32 Value
+
+type uint32Value uint32
+
+func (i *uint32Value) Set(s string) error {
+	return i.setTOML(s)
+}
+
+func (i *uint32Value) setTOML(value interface{}) error {
+	v, err := sizedUint(value, 32)
+	if err != nil {
+		return err
+	}
+	*i = uint32Value(v)
+	return nil
+}
+
+func (i *uint32Value) Get() interface{} { return uint32(*i) }
+
+func (i *uint32Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *uint32Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- float32 Value
+
+type float32Value float32
+
+func (f *float32Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return numError(err)
+	}
+	*f = float32Value(v)
+	return nil
+}
+
+// setTOML rejects finite values too large for a float32, which would
+// otherwise become infinities. Values are rounded to the nearest float32.
+func (f *float32Value) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case float64:
+		if math.Abs(v) > math.MaxFloat32 && !math.IsInf(v, 0) {
+			return errRange
+		}
+		*f = float32Value(v)
+	case int64:
+		*f = float32Value(v)
+	case string:
+		return f.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (f *float32Value) Get() interface{} { return float32(*f) }
+
+func (f *float32Value) snapshot() func() error {
+	v := *f
+	return func() error {
+		*f = v
+		return nil
+	}
+}
+
+func (f *float32Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
+
+// Int8Var defines an int8 config with a given name and default value for a
+// ConfigSet. The argument p points to an int8 variable in which to store the
+// value of the config. Values that don't fit in an int8 fail the load rather
+// than wrapping around.
+func (c *ConfigSet) Int8Var(p *int8, name string, value int8) {
+	*p = value
+	c.Var((*int8Value)(p), name, "")
+}
+
+// Int8 defines an int8 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Int8(name string, value int8) *int8 {
+	p := new(int8)
+	c.Int8Var(p, name, value)
+	return p
+}
+
+// Int16Var defines an int16 config with a given name and default value for a
+// ConfigSet. The argument p points to an int16 variable in which to store the
+// value of the config. Values that don't fit in an int16 fail the load.
+func (c *ConfigSet) Int16Var(p *int16, name string, value int16) {
+	*p = value
+	c.Var((*int16Value)(p), name, "")
+}
+
+// Int16 defines an int16 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Int16(name string, value int16) *int16 {
+	p := new(int16)
+	c.Int16Var(p, name, value)
+	return p
+}
+
+// Int32Var defines an int32 config with a given name and default value for a
+// ConfigSet. The argument p points to an int32 variable in which to store the
+// value of the config. Values that don't fit in an int32 fail the load.
+func (c *ConfigSet) Int32Var(p *int32, name string, value int32) {
+	*p = value
+	c.Var((*int32Value)(p), name, "")
+}
+
+// Int32 defines an int32 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Int32(name string, value int32) *int32 {
+	p := new(int32)
+	c.Int32Var(p, name, value)
+	return p
+}
+
+// Uint8Var defines a uint8 config with a given name and default value for a
+// ConfigSet. The argument p points to a uint8 variable in which to store the
+// value of the config. Negative values and values that don't fit in a uint8
+// fail the load rather than wrapping around.
+func (c *ConfigSet) Uint8Var(p *uint8, name string, value uint8) {
+	*p = value
+	c.Var((*uint8Value)(p), name, "")
+}
+
+// Uint8 defines a uint8 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Uint8(name string, value uint8) *uint8 {
+	p := new(uint8)
+	c.Uint8Var(p, name, value)
+	return p
+}
+
+// Uint16Var defines a uint16 config with a given name and default value for a
+// ConfigSet. The argument p points to a uint16 variable in which to store the
+// value of the config. Negative values and values that don't fit in a uint16
+// fail the load.
+func (c *ConfigSet) Uint16Var(p *uint16, name string, value uint16) {
+	*p = value
+	c.Var((*uint16Value)(p), name, "")
+}
+
+// Uint16 defines a uint16 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Uint16(name string, value uint16) *uint16 {
+	p := new(uint16)
+	c.Uint16Var(p, name, value)
+	return p
+}
+
+// Uint32Var defines a uint32 config with a given name and default value for a
+// ConfigSet. The argument p points to a uint32 variable in which to store the
+// value of the config. Negative values and values that don't fit in a uint32
+// fail the load.
+func (c *ConfigSet) Uint32Var(p *uint32, name string, value uint32) {
+	*p = value
+	c.Var((*uint32Value)(p), name, "")
+}
+
+// Uint32 defines a uint32 config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Uint32(name string, value uint32) *uint32 {
+	p := new(uint32)
+	c.Uint32Var(p, name, value)
+	return p
+}
+
+// Float32Var defines a float32 config with a given name and default value
+// for a ConfigSet. The argument p points to a float32 variable in which to
+// store the value of the config. Values too large for a float32 fail the load.
+func (c *ConfigSet) Float32Var(p *float32, name string, value float32) {
+	*p = value
+	c.Var((*float32Value)(p), name, "")
+}
+
+// Float32 defines a float32 config variable with a given name and default
+// value for a ConfigSet.
+func (c *ConfigSet) Float32(name string, value float32) *float32 {
+	p := new(float32)
+	c.Float32Var(p, name, value)
+	return p
+}
+
+// Int8Var defines an int8 config with a given name and default value. The
+// argument p points to an int8 variable in which to store the value of the
+// config.
+func Int8Var(p *int8, name string, value int8) {
+	global().Int8Var(p, name, value)
+}
+
+// Int8 defines an int8 config variable with a given name and default value.
+func Int8(name string, value int8) *int8 {
+	return global().Int8(name, value)
+}
+
+// Int16Var defines an int16 config with a given name and default value. The
+// argument p points to an int16 variable in which to store the value of the
+// config.
+func Int16Var(p *int16, name string, value int16) {
+	global().Int16Var(p, name, value)
+}
+
+// Int16 defines an int16 config variable with a given name and default value.
+func Int16(name string, value int16) *int16 {
+	return global().Int16(name, value)
+}
+
+// Int32Var defines an int32 config with a given name and default value. The
+// argument p points to an int32 variable in which to store the value of the
+// config.
+func Int32Var(p *int32, name string, value int32) {
+	global().Int32Var(p, name, value)
+}
+
+// Int32 defines an int32 config variable with a given name and default value.
+func Int32(name string, value int32) *int32 {
+	return global().Int32(name, value)
+}
+
+// Uint8Var defines a uint8 config with a given name and default value. The
+// argument p points to a uint8 variable in which to store the value of the
+// config.
+func Uint8Var(p *uint8, name string, value uint8) {
+	global().Uint8Var(p, name, value)
+}
+
+// Uint8 defines a uint8 config variable with a given name and default value.
+func Uint8(name string, value uint8) *uint8 {
+	return global().Uint8(name, value)
+}
+
+// Uint16Var defines a uint16 config with a given name and default value. The
+// argument p points to a uint16 variable in which to store the value of the
+// config.
+func Uint16Var(p *uint16, name string, value uint16) {
+	global().Uint16Var(p, name, value)
+}
+
+// Uint16 defines a uint16 config variable with a given name and default
+// value.
+func Uint16(name string, value uint16) *uint16 {
+	return global().Uint16(name, value)
+}
+
+// Uint32Var defines a uint32 config with a given name and default value. The
+// argument p points to a uint32 variable in which to store the value of the
+// config.
+func Uint32Var(p *uint32, name string, value uint32) {
+	global().Uint32Var(p, name, value)
+}
+
+// Uint32 defines a uint32 config variable with a given name and default
+// value.
+func Uint32(name string, value uint32) *uint32 {
+	return global().Uint32(name, value)
+}
+
+// Float32Var defines a float32 config with a given name and default value.
+// The argument p points to a float32 variable in which to store the value of
+// the config.
+func Float32Var(p *float32, name string, value float32) {
+	global().Float32Var(p, name, value)
+}
+
+// Float32 defines a float32 config variable with a given name and default
+// value.
+func Float32(name string, value float32) *float32 {
+	return global().Float32(name, value)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSizedInts(t *testing.T) {
+	c := NewConfigSet("sized", ContinueOnError)
+	priority := c.Int8("max_priority", 10)
+	offset := c.Int16("offset", 0)
+	weight := c.Int32("weight", 1)
+
+	if err := c.parseBytes("sized", []byte("max_priority = -128\noffset = 32767\nweight = \"0x7fffffff\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if *priority != -128 || *offset != 32767 || *weight != 1<<31-1 {
+		t.Errorf("Unexpected values: %d, %d, %d", *priority, *offset, *weight)
+	}
+
+	for doc, expected := range map[string]string{
+		"max_priority = 300":    "The value for max_priority is invalid: value out of range",
+		"offset = -32769":       "The value for offset is invalid: value out of range",
+		`weight = "2147483648"`: "The value for weight is invalid: value out of range",
+		"weight = 1.5":          "The value for weight is invalid",
+	} {
+		err := c.parseBytes("sized", []byte(doc))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s, got %v", expected, doc, err)
+		}
+	}
+	if *priority != -128 {
+		t.Error("max_priority shouldn't wrap around, is", *priority)
+	}
+}
+
+func TestSizedUints(t *testing.T) {
+	c := NewConfigSet("sized", ContinueOnError)
+	hops := c.Uint8("max_hops", 16)
+	port := c.Uint16("port", 80)
+	ttl := c.Uint32("ttl", 300)
+
+	if err := c.parseBytes("sized", []byte("max_hops = 255\nport = 65535\nttl = \"4294967295\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if *hops != 255 || *port != 65535 || *ttl != 1<<32-1 {
+		t.Errorf("Unexpected values: %d, %d, %d", *hops, *port, *ttl)
+	}
+
+	for doc, expected := range map[string]string{
+		"max_hops = 256":   "The value for max_hops is invalid: value out of range",
+		"port = -1":        "The value for port is invalid: value out of range",
+		`ttl = "-5"`:       "The value for ttl is invalid",
+		"ttl = 4294967296": "The value for ttl is invalid: value out of range",
+	} {
+		err := c.parseBytes("sized", []byte(doc))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s, got %v", expected, doc, err)
+		}
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	c := NewConfigSet("sized", ContinueOnError)
+	rate := c.Float32("learning_rate", 0.01)
+	scale := c.Float32("scale", 1)
+
+	if err := c.parseBytes("sized", []byte("learning_rate = 0.0003\nscale = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if *rate != 0.0003 || *scale != 2 {
+		t.Errorf("Unexpected values: %g, %g", *rate, *scale)
+	}
+	if s := c.Lookup("learning_rate").Value.String(); s != "0.0003" {
+		t.Error("Unexpected string:", s)
+	}
+
+	for doc, expected := range map[string]string{
+		"scale = 1e39":   "The value for scale is invalid: value out of range",
+		`scale = "1e39"`: "The value for scale is invalid: value out of range",
+		"scale = true":   "The value for scale is invalid",
+	} {
+		err := c.parseBytes("sized", []byte(doc))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s, got %v", expected, doc, err)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tomlElements returns the elements of a decoded TOML array. A single string
+// is split on commas, which is how values from the environment and the
+// command line are given.
+func tomlElements(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		strs := splitList(v)
+		elems := make([]interface{}, len(strs))
+		for i, str := range strs {
+			elems[i] = str
+		}
+		return elems, nil
+	case []interface{}:
+		return v, nil
+	}
+	return nil, errParse
+}
+
+// invalidElement returns the error for an element of an array that the
+// scalar value for its type rejected with err. What describes a valid
+// element, such as "a valid integer".
+func invalidElement(index int, err error, what string) error {
+	if err == errRange {
+		return elementError{index, "is out of range"}
+	}
+	return elementError{index, "is not " + what}
+}
+
+// -- StringSlice Value
+
+type stringSliceValue []string
+
+func (s *stringSliceValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+// setTOML accepts an array of strings, or a comma-separated string, which is
+// how values from the environment and the command line are given.
+func (s *stringSliceValue) setTOML(value interface{}) error {
+	strs, err := tomlStrings(value)
+	if err != nil {
+		return err
+	}
+	*s = strs
+	return nil
+}
+
+func (s *stringSliceValue) Get() interface{} { return []string(*s) }
+
+func (s *stringSliceValue) snapshot() func() error {
+	v := slices.Clone(*s)
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *stringSliceValue) String() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(*s, ",")
+}
+
+// StringSliceVar defines a string slice config with a given name and default
+// value for a ConfigSet. The argument p points to a []string variable in
+// which to store the value of the config.
+func (c *ConfigSet) StringSliceVar(p *[]string, name string, value []string) {
+	*p = value
+	c.Var((*stringSliceValue)(p), name, "")
+}
+
+// StringSlice defines a string slice config variable with a given name and
+// default value for a ConfigSet. The TOML value must be an array of strings,
+// such as ["a", "b"], which replaces the default rather than adding to it.
+func (c *ConfigSet) StringSlice(name string, value []string) *[]string {
+	p := new([]string)
+	c.StringSliceVar(p, name, value)
+	return p
+}
+
+// -- IntSlice Value
+
+type intSliceValue []int
+
+func (s *intSliceValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+func (s *intSliceValue) setTOML(value interface{}) error {
+	elems, err := tomlElements(value)
+	if err != nil {
+		return err
+	}
+	ints := make([]int, len(elems))
+	for i, elem := range elems {
+		if err := (*intValue)(&ints[i]).setTOML(elem); err != nil {
+			return invalidElement(i, err, "a valid integer")
+		}
+	}
+	*s = ints
+	return nil
+}
+
+func (s *intSliceValue) Get() interface{} { return []int(*s) }
+
+func (s *intSliceValue) snapshot() func() error {
+	v := slices.Clone(*s)
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *intSliceValue) String() string {
+	if s == nil {
+		return ""
+	}
+	strs := make([]string, len(*s))
+	for i, v := range *s {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
+
+// IntSliceVar defines an int slice config with a given name and default value
+// for a ConfigSet. The argument p points to a []int variable in which to
+// store the value of the config.
+func (c *ConfigSet) IntSliceVar(p *[]int, name string, value []int) {
+	*p = value
+	c.Var((*intSliceValue)(p), name, "")
+}
+
+// IntSlice defines an int slice config variable with a given name and default
+// value for a ConfigSet. The TOML value must be an array of integers, such as
+// [8080, 8081].
+func (c *ConfigSet) IntSlice(name string, value []int) *[]int {
+	p := new([]int)
+	c.IntSliceVar(p, name, value)
+	return p
+}
+
+// -- Int64Slice Value
+
+type int64SliceValue []int64
+
+func (s *int64SliceValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+func (s *int64SliceValue) setTOML(value interface{}) error {
+	elems, err := tomlElements(value)
+	if err != nil {
+		return err
+	}
+	ints := make([]int64, len(elems))
+	for i, elem := range elems {
+		if err := (*int64Value)(&ints[i]).setTOML(elem); err != nil {
+			return invalidElement(i, err, "a valid integer")
+		}
+	}
+	*s = ints
+	return nil
+}
+
+func (s *int64SliceValue) Get() interface{} { return []int64(*s) }
+
+func (s *int64SliceValue) snapshot() func() error {
+	v := slices.Clone(*s)
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *int64SliceValue) String() string {
+	if s == nil {
+		return ""
+	}
+	strs := make([]string, len(*s))
+	for i, v := range *s {
+		strs[i] = strconv.FormatInt(v, 10)
+	}
+	return strings.Join(strs, ",")
+}
+
+// Int64SliceVar defines an int64 slice config with a given name and default
+// value for a ConfigSet. The argument p points to a []int64 variable in which
+// to store the value of the config.
+func (c *ConfigSet) Int64SliceVar(p *[]int64, name string, value []int64) {
+	*p = value
+	c.Var((*int64SliceValue)(p), name, "")
+}
+
+// Int64Slice defines an int64 slice config variable with a given name and
+// default value for a ConfigSet. The TOML value must be an array of integers.
+func (c *ConfigSet) Int64Slice(name string, value []int64) *[]int64 {
+	p := new([]int64)
+	c.Int64SliceVar(p, name, value)
+	return p
+}
+
+// -- Float64Slice Value
+
+type float64SliceValue []float64
+
+func (s *float64SliceValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+// setTOML accepts integer elements as well as floats, so that bounds like
+// [1, 2.5, 10] don't need a decimal point on every element.
+func (s *float64SliceValue) setTOML(value interface{}) error {
+	elems, err := tomlElements(value)
+	if err != nil {
+		return err
+	}
+	floats := make([]float64, len(elems))
+	for i, elem := range elems {
+		if err := (*float64Value)(&floats[i]).setTOML(elem); err != nil {
+			return invalidElement(i, err, "a valid number")
+		}
+	}
+	*s = floats
+	return nil
+}
+
+func (s *float64SliceValue) Get() interface{} { return []float64(*s) }
+
+func (s *float64SliceValue) snapshot() func() error {
+	v := slices.Clone(*s)
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *float64SliceValue) String() string {
+	if s == nil {
+		return ""
+	}
+	strs := make([]string, len(*s))
+	for i, v := range *s {
+		strs[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(strs, ",")
+}
+
+// Float64SliceVar defines a float64 slice config with a given name and
+// default value for a ConfigSet. The argument p points to a []float64
+// variable in which to store the value of the config.
+func (c *ConfigSet) Float64SliceVar(p *[]float64, name string, value []float64) {
+	*p = value
+	c.Var((*float64SliceValue)(p), name, "")
+}
+
+// Float64Slice defines a float64 slice config variable with a given name and
+// default value for a ConfigSet. The TOML value must be an array of numbers,
+// such as latency bucket boundaries like [0.005, 0.01, 0.1, 1].
+func (c *ConfigSet) Float64Slice(name string, value []float64) *[]float64 {
+	p := new([]float64)
+	c.Float64SliceVar(p, name, value)
+	return p
+}
+
+// -- DurationSlice Value
+
+type durationSliceValue []time.Duration
+
+func (s *durationSliceValue) Set(val string) error {
+	return s.setTOML(val)
+}
+
+func (s *durationSliceValue) setTOML(value interface{}) error {
+	elems, err := tomlElements(value)
+	if err != nil {
+		return err
+	}
+	durations := make([]time.Duration, len(elems))
+	for i, elem := range elems {
+		if err := (*durationValue)(&durations[i]).setTOML(elem); err != nil {
+			return invalidElement(i, err, "a valid duration")
+		}
+	}
+	*s = durations
+	return nil
+}
+
+func (s *durationSliceValue) Get() interface{} { return []time.Duration(*s) }
+
+func (s *durationSliceValue) snapshot() func() error {
+	v := slices.Clone(*s)
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *durationSliceValue) String() string {
+	if s == nil {
+		return ""
+	}
+	strs := make([]string, len(*s))
+	for i, v := range *s {
+		strs[i] = v.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// DurationSliceVar defines a time.Duration slice config with a given name and
+// default value for a ConfigSet. The argument p points to a []time.Duration
+// variable in which to store the value of the config.
+func (c *ConfigSet) DurationSliceVar(p *[]time.Duration, name string, value []time.Duration) {
+	*p = value
+	c.Var((*durationSliceValue)(p), name, "")
+}
+
+// DurationSlice defines a time.Duration slice config variable with a given
+// name and default value for a ConfigSet. The TOML value must be an array of
+// strings accepted by time.ParseDuration, such as ["1s", "5s", "30s"].
+func (c *ConfigSet) DurationSlice(name string, value []time.Duration) *[]time.Duration {
+	p := new([]time.Duration)
+	c.DurationSliceVar(p, name, value)
+	return p
+}
+
+// StringSliceVar defines a string slice config with a given name and default
+// value. The argument p points to a []string variable in which to store the
+// value of the config.
+func StringSliceVar(p *[]string, name string, value []string) {
+	global().StringSliceVar(p, name, value)
+}
+
+// StringSlice defines a string slice config variable with a given name and
+// default value.
+func StringSlice(name string, value []string) *[]string {
+	return global().StringSlice(name, value)
+}
+
+// IntSliceVar defines an int slice config with a given name and default
+// value. The argument p points to a []int variable in which to store the
+// value of the config.
+func IntSliceVar(p *[]int, name string, value []int) {
+	global().IntSliceVar(p, name, value)
+}
+
+// IntSlice defines an int slice config variable with a given name and default
+// value.
+func IntSlice(name string, value []int) *[]int {
+	return global().IntSlice(name, value)
+}
+
+// Int64SliceVar defines an int64 slice config with a given name and default
+// value. The argument p points to a []int64 variable in which to store the
+// value of the config.
+func Int64SliceVar(p *[]int64, name string, value []int64) {
+	global().Int64SliceVar(p, name, value)
+}
+
+// Int64Slice defines an int64 slice config variable with a given name and
+// default value.
+func Int64Slice(name string, value []int64) *[]int64 {
+	return global().Int64Slice(name, value)
+}
+
+// Float64SliceVar defines a float64 slice config with a given name and
+// default value. The argument p points to a []float64 variable in which to
+// store the value of the config.
+func Float64SliceVar(p *[]float64, name string, value []float64) {
+	global().Float64SliceVar(p, name, value)
+}
+
+// Float64Slice defines a float64 slice config variable with a given name and
+// default value.
+func Float64Slice(name string, value []float64) *[]float64 {
+	return global().Float64Slice(name, value)
+}
+
+// DurationSliceVar defines a time.Duration slice config with a given name and
+// default value. The argument p points to a []time.Duration variable in which
+// to store the value of the config.
+func DurationSliceVar(p *[]time.Duration, name string, value []time.Duration) {
+	global().DurationSliceVar(p, name, value)
+}
+
+// DurationSlice defines a time.Duration slice config variable with a given
+// name and default value.
+func DurationSlice(name string, value []time.Duration) *[]time.Duration {
+	return global().DurationSlice(name, value)
+}
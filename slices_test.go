@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStringSlice(t *testing.T) {
+	c := NewConfigSet("slices", ContinueOnError)
+	hosts := c.StringSlice("hosts", []string{"localhost"})
+	empty := c.StringSlice("empty", []string{"x"})
+
+	if err := c.parseBytes("slices", []byte("hosts = [\"a\", \"b\", \"a\"]\nempty = []\n")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a", "b", "a"}; !reflect.DeepEqual(*hosts, expected) {
+		t.Errorf("hosts should be %v, is %v", expected, *hosts)
+	}
+	if len(*empty) != 0 {
+		t.Errorf("empty should be empty, is %v", *empty)
+	}
+
+	if err := c.Set("hosts", "c, d"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"c", "d"}; !reflect.DeepEqual(*hosts, expected) {
+		t.Errorf("hosts should be %v, is %v", expected, *hosts)
+	}
+
+	err := c.parseBytes("slices", []byte(`hosts = ["a", 2]`))
+	if err == nil || err.Error() != "hosts[1] is not a string at slices:1" {
+		t.Error("Expected an invalid element error, got", err)
+	}
+}
+
+func TestIntSlices(t *testing.T) {
+	c := NewConfigSet("slices", ContinueOnError)
+	ports := c.IntSlice("ports", []int{80})
+	sizes := c.Int64Slice("sizes", nil)
+
+	if err := c.parseBytes("slices", []byte("ports = [8080, 8081, 8082]\nsizes = [1, 9007199254740993]\n")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{8080, 8081, 8082}; !reflect.DeepEqual(*ports, expected) {
+		t.Errorf("ports should be %v, is %v", expected, *ports)
+	}
+	if expected := []int64{1, 9007199254740993}; !reflect.DeepEqual(*sizes, expected) {
+		t.Errorf("sizes should be %v, is %v", expected, *sizes)
+	}
+	if s := c.Lookup("ports").Value.String(); s != "8080,8081,8082" {
+		t.Error("Unexpected string:", s)
+	}
+
+	if err := c.Set("ports", "1, 0x10"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{1, 16}; !reflect.DeepEqual(*ports, expected) {
+		t.Errorf("ports should be %v, is %v", expected, *ports)
+	}
+
+	err := c.parseBytes("slices", []byte(`ports = [80, "http"]`))
+	if err == nil || err.Error() != "ports[1] is not a valid integer at slices:1" {
+		t.Error("Expected an invalid element error, got", err)
+	}
+	err = c.parseBytes("slices", []byte(`ports = 80`))
+	if err == nil || err.Error() != "The value for ports is invalid" {
+		t.Error("Expected an invalid value error, got", err)
+	}
+}
+
+func TestFloat64Slice(t *testing.T) {
+	c := NewConfigSet("slices", ContinueOnError)
+	buckets := c.Float64Slice("buckets", []float64{1})
+
+	if err := c.parseBytes("slices", []byte("buckets = [0.005, 0.25, 1, 2.5]\n")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []float64{0.005, 0.25, 1, 2.5}; !reflect.DeepEqual(*buckets, expected) {
+		t.Errorf("buckets should be %v, is %v", expected, *buckets)
+	}
+	if s := c.Lookup("buckets").Value.String(); s != "0.005,0.25,1,2.5" {
+		t.Error("Unexpected string:", s)
+	}
+
+	err := c.parseBytes("slices", []byte(`buckets = [1.5, true]`))
+	if err == nil || err.Error() != "buckets[1] is not a valid number at slices:1" {
+		t.Error("Expected an invalid element error, got", err)
+	}
+}
+
+func TestDurationSlice(t *testing.T) {
+	c := NewConfigSet("slices", ContinueOnError)
+	backoffs := c.DurationSlice("retry_backoffs", nil)
+
+	if err := c.parseBytes("slices", []byte(`retry_backoffs = ["1s", "5s", "1m30s"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []time.Duration{time.Second, 5 * time.Second, 90 * time.Second}; !reflect.DeepEqual(*backoffs, expected) {
+		t.Errorf("retry_backoffs should be %v, is %v", expected, *backoffs)
+	}
+	if s := c.Lookup("retry_backoffs").Value.String(); s != "1s,5s,1m30s" {
+		t.Error("Unexpected string:", s)
+	}
+
+	err := c.parseBytes("slices", []byte(`retry_backoffs = ["1s", "soon"]`))
+	if err == nil || err.Error() != "retry_backoffs[1] is not a valid duration at slices:1" {
+		t.Error("Expected an invalid element error, got", err)
+	}
+}
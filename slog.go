@@ -0,0 +1,162 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+var _ slog.LogValuer = (*ConfigSet)(nil)
+
+// LogValue implements slog.LogValuer, so that a ConfigSet can be logged as a
+// structured record of its effective settings:
+//
+//	slog.Info("starting", "config", c)
+//
+// Tables become groups, so "db.port" is logged as the attribute "port" in the
+// group "db". Strings, numbers, booleans, durations, and times keep their
+// types; other values are logged as strings. The values of configs marked
+// Sensitive are redacted.
+func (c *ConfigSet) LogValue() slog.Value {
+	root := &logNode{}
+	c.VisitAll(func(f *flag.Flag) {
+		n := root
+		keys := strings.Split(f.Name, ".")
+		for _, key := range keys[:len(keys)-1] {
+			n = n.group(key)
+		}
+		n.children = append(n.children, &logNode{key: keys[len(keys)-1], value: c.logValue(f)})
+	})
+	return slog.GroupValue(root.attrs()...)
+}
+
+// logValue returns the value of a config as it's logged.
+func (c *ConfigSet) logValue(f *flag.Flag) slog.Value {
+	if c.secrets[f.Name] {
+		return slog.StringValue(redacted)
+	}
+	if g, ok := f.Value.(flag.Getter); ok {
+		if v := slog.AnyValue(g.Get()); v.Kind() != slog.KindAny {
+			return v
+		}
+	}
+	return slog.StringValue(f.Value.String())
+}
+
+// logNode is a setting or a group of settings in a LogValue.
+type logNode struct {
+	key      string
+	value    slog.Value
+	children []*logNode
+	isGroup  bool
+}
+
+// group returns the group with the given key in n, adding it if necessary.
+func (n *logNode) group(key string) *logNode {
+	for _, child := range n.children {
+		if child.key == key && child.isGroup {
+			return child
+		}
+	}
+	child := &logNode{key: key, isGroup: true}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *logNode) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, len(n.children))
+	for i, child := range n.children {
+		attrs[i] = slog.Attr{Key: child.key, Value: child.value}
+		if child.isGroup {
+			attrs[i].Value = slog.GroupValue(child.attrs()...)
+		}
+	}
+	return attrs
+}
+
+// LogValue returns the effective settings of the global config as a
+// structured log value.
+func LogValue() slog.Value {
+	return global().LogValue()
+}
+
+// -- slog.Level Value
+
+type logLevelValue slog.Level
+
+// Set parses a level name such as "debug" or "WARN", optionally with an
+// offset such as "info+2", or a number such as -4.
+func (l *logLevelValue) Set(s string) error {
+	var v slog.Level
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		v = slog.Level(n)
+	} else if err := v.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return fmt.Errorf("%q is not a valid log level", s)
+	}
+	*l = logLevelValue(v)
+	return nil
+}
+
+func (l *logLevelValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		if int64(int(v)) != v {
+			return errRange
+		}
+		*l = logLevelValue(v)
+	case string:
+		return l.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (l *logLevelValue) Get() interface{} { return slog.Level(*l) }
+
+func (l *logLevelValue) snapshot() func() error {
+	v := *l
+	return func() error {
+		*l = v
+		return nil
+	}
+}
+
+func (l *logLevelValue) String() string { return slog.Level(*l).String() }
+
+// LogLevelVar defines a log level config with a given name and default value
+// for a ConfigSet. The argument p points to a slog.Level variable in which to
+// store the value of the config. Values are level names, "debug", "info",
+// "warn", or "error" in any case, optionally with an offset such as
+// "info+2", or numbers such as -4.
+func (c *ConfigSet) LogLevelVar(p *slog.Level, name string, value slog.Level) {
+	*p = value
+	c.Var((*logLevelValue)(p), name, "")
+}
+
+// LogLevel defines a log level config variable with a given name and default
+// value for a ConfigSet. The level can be given to a handler directly:
+//
+//	level := config.LogLevel("log.level", slog.LevelInfo)
+//	...
+//	slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: *level}))
+func (c *ConfigSet) LogLevel(name string, value slog.Level) *slog.Level {
+	p := new(slog.Level)
+	c.LogLevelVar(p, name, value)
+	return p
+}
+
+// LogLevelVar defines a log level config with a given name and default value.
+// The argument p points to a slog.Level variable in which to store the value
+// of the config.
+func LogLevelVar(p *slog.Level, name string, value slog.Level) {
+	global().LogLevelVar(p, name, value)
+}
+
+// LogLevel defines a log level config variable with a given name and default
+// value.
+func LogLevel(name string, value slog.Level) *slog.Level {
+	return global().LogLevel(name, value)
+}
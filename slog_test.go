@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLogValue(t *testing.T) {
+	c := NewConfigSet("slog", ContinueOnError)
+	c.String("name", "app")
+	c.Int("db.port", 5432)
+	c.String("db.password", "")
+	c.Sensitive("db.password")
+	c.Duration("db.pool.idle", time.Minute)
+	c.StringSet("tags", []string{"a", "b"})
+	if err := c.parseBytes("slog", []byte("[db]\npassword = \"hunter2\"\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("starting", "config", c)
+	expected := `{"level":"INFO","msg":"starting","config":{"db":{"password":"[redacted]","pool":{"idle":60000000000},"port":5432},"name":"app","tags":"a,b"}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected log record:\n%s", buf.String())
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	c := NewConfigSet("slog", ContinueOnError)
+	level := c.LogLevel("log.level", slog.LevelInfo)
+	audit := c.LogLevel("log.audit", slog.LevelInfo)
+
+	if err := c.parseBytes("slog", []byte("[log]\nlevel = \"DEBUG\"\naudit = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if *level != slog.LevelDebug || *audit != slog.LevelInfo+2 {
+		t.Errorf("Unexpected levels: %s, %s", *level, *audit)
+	}
+	if err := c.Set("log.level", "warn+1"); err != nil || *level != slog.LevelWarn+1 {
+		t.Error("log.level should be WARN+1, is", *level, err)
+	}
+	if s := c.Lookup("log.level").Value.String(); s != "WARN+1" {
+		t.Error("Unexpected string:", s)
+	}
+
+	err := c.parseBytes("slog", []byte("[log]\nlevel = \"verbose\"\n"))
+	if err == nil || err.Error() != `The value for log.level is invalid: "verbose" is not a valid log level` {
+		t.Error("Expected an invalid level error, got", err)
+	}
+}
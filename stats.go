@@ -0,0 +1,153 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Stats describes the most recent load of a ConfigSet.
+type Stats struct {
+	// DecodeTime is the time spent decoding TOML documents.
+	DecodeTime time.Duration
+	// KeysApplied is the number of config values set by the load.
+	KeysApplied int
+	// DefaultsUsed is the number of config variables left at their defaults.
+	DefaultsUsed int
+	// FileSize is the size in bytes of the last document loaded.
+	FileSize int64
+	// FileHash is the hex-encoded SHA-256 of the last document loaded.
+	FileHash string
+}
+
+// Stats returns statistics about the most recent Parse or ParseLayers call.
+func (c *ConfigSet) Stats() Stats {
+	return c.stats
+}
+
+// LoadInfo identifies the configuration loaded by a ConfigSet, so that logs
+// and metrics can tie changes in behavior to a particular config version.
+type LoadInfo struct {
+	// Paths are the files loaded, in the order they were applied.
+	Paths []string
+	// Hash is the hex-encoded SHA-256 of the contents of every document
+	// loaded, in order.
+	Hash string
+	// ModTime is the latest modification time of the files loaded.
+	ModTime time.Time
+	// Generation counts the loads of the ConfigSet, starting at 1 for the
+	// first. It increases with every load, including failed ones, so it
+	// never repeats.
+	Generation uint64
+}
+
+// LoadInfo describes the configuration currently loaded. After a failed
+// Reload, it describes the configuration that was restored.
+func (c *ConfigSet) LoadInfo() LoadInfo {
+	info := c.info
+	info.Paths = append([]string(nil), c.info.Paths...)
+	return info
+}
+
+// beginLoad resets the load statistics, the loaded documents, and every
+// config's value to its default, so that a setting removed from a source
+// doesn't keep its previous value. It's called at the start of every exported
+// Parse method.
+func (c *ConfigSet) beginLoad() error {
+	c.generation++
+	c.info = LoadInfo{Generation: c.generation}
+	c.hash = sha256.New()
+	c.options = parseOptions{}
+	c.stats = Stats{}
+	c.applied = make(map[string]string)
+	c.trees = nil
+	c.sources = nil
+	c.keysLoaded = 0
+	c.cache.key = ""
+	c.cache.files = make(map[string]fileState)
+
+	var errs []error
+	c.VisitAll(func(f *flag.Flag) {
+		if reset := c.defaults[f.Name]; reset != nil {
+			if err := reset(); err != nil {
+				errs = append(errs, fmt.Errorf("config: can't reset %s to its default: %s", f.Name, err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// endLoad applies any command-line flags over the loaded sources and
+// finishes the load statistics. If the load succeeded, it sets *err to a
+// *MissingKeysError if any required configs weren't set.
+func (c *ConfigSet) endLoad(err *error) {
+	c.applyFlags()
+	c.derive()
+	if *err == nil {
+		*err = c.checkRequired()
+	}
+	if *err == nil {
+		c.cache.flags = c.numDefined()
+	} else {
+		c.cache.key = ""
+	}
+	if c.hash != nil {
+		c.info.Hash = hex.EncodeToString(c.hash.Sum(nil))
+	}
+	if !c.reloading {
+		c.checkRotations(false)
+	}
+	c.stats.DefaultsUsed = 0
+	c.VisitAll(func(f *flag.Flag) {
+		if c.applied[f.Name] == "" {
+			c.stats.DefaultsUsed++
+		}
+	})
+}
+
+func (c *ConfigSet) recordDocument(configBytes []byte) {
+	if c.hash != nil {
+		c.hash.Write(configBytes)
+	}
+	sum := sha256.Sum256(configBytes)
+	c.stats.FileSize = int64(len(configBytes))
+	c.stats.FileHash = hex.EncodeToString(sum[:])
+}
+
+// recordFile adds a file to the LoadInfo.
+func (c *ConfigSet) recordFile(path string) {
+	c.info.Paths = append(c.info.Paths, path)
+	if fi, err := os.Stat(path); err == nil && fi.ModTime().After(c.info.ModTime) {
+		c.info.ModTime = fi.ModTime()
+	}
+}
+
+// startSource sets the source being loaded, such as "app.conf" or "env".
+func (c *ConfigSet) startSource(name string) {
+	c.loading = name
+	c.sources = append(c.sources, name)
+}
+
+// recordSet records that configs were changed by Set or a transaction. The
+// map is copied rather than changed, since a snapshot may share it.
+func (c *ConfigSet) recordSet(names ...string) {
+	applied := make(map[string]string, len(c.applied)+len(names))
+	for name, origin := range c.applied {
+		applied[name] = origin
+	}
+	for _, name := range names {
+		applied[name] = "set"
+	}
+	c.applied = applied
+}
+
+// recordApplied counts a config value set by a load and records where it came
+// from, such as "app.conf:9" or "env".
+func (c *ConfigSet) recordApplied(name string, origin string) {
+	c.stats.KeysApplied++
+	c.applied[name] = origin
+}
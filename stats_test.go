@@ -0,0 +1,100 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	c := NewConfigSet("stats", ContinueOnError)
+	c.Bool("my_bool", false)
+	c.Int("my_int", 0)
+	c.Int64("my_bigint", 0)
+	c.Uint("my_uint", 0)
+	c.Uint64("my_biguint", 0)
+	c.String("my_string", "")
+	c.Float64("my_bigfloat", 0)
+	c.String("section.name", "")
+	c.String("places.california.name", "")
+	c.String("unused", "")
+
+	if err := c.Parse(GOOD_CONFIG_PATH); err != nil {
+		t.Fatal(err)
+	}
+
+	configBytes, err := ioutil.ReadFile(GOOD_CONFIG_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(configBytes)
+
+	stats := c.Stats()
+	if stats.KeysApplied != 9 {
+		t.Error("KeysApplied should be 9, is", stats.KeysApplied)
+	}
+	if stats.DefaultsUsed != 1 {
+		t.Error("DefaultsUsed should be 1, is", stats.DefaultsUsed)
+	}
+	if stats.FileSize != int64(len(configBytes)) {
+		t.Error("FileSize should be", len(configBytes), "is", stats.FileSize)
+	}
+	if stats.FileHash != hex.EncodeToString(sum[:]) {
+		t.Error("FileHash is wrong:", stats.FileHash)
+	}
+	if stats.DecodeTime <= 0 {
+		t.Error("DecodeTime should be positive, is", stats.DecodeTime)
+	}
+}
+
+func TestLoadInfo(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.conf")
+	second := filepath.Join(dir, "b.conf")
+	os.WriteFile(first, []byte("port = 80\n"), 0644)
+	os.WriteFile(second, []byte("port = 81\n"), 0644)
+	modTime := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	os.Chtimes(second, modTime, modTime)
+	os.Chtimes(first, modTime.Add(-time.Hour), modTime.Add(-time.Hour))
+
+	c := NewConfigSet("info", ContinueOnError)
+	c.Int("port", 8080)
+	if err := c.ParseFiles(first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	info := c.LoadInfo()
+	if len(info.Paths) != 2 || info.Paths[0] != first || info.Paths[1] != second {
+		t.Error("Unexpected paths:", info.Paths)
+	}
+	sum := sha256.Sum256([]byte("port = 80\nport = 81\n"))
+	if info.Hash != hex.EncodeToString(sum[:]) {
+		t.Error("Unexpected hash:", info.Hash)
+	}
+	if !info.ModTime.Equal(modTime) {
+		t.Error("ModTime should be the latest file's, is", info.ModTime)
+	}
+	if info.Generation != 1 {
+		t.Error("Generation should be 1, is", info.Generation)
+	}
+
+	os.WriteFile(second, []byte("port = \"http\"\n"), 0644)
+	if err := c.Reload(); err == nil {
+		t.Fatal("Expected the reload to fail")
+	}
+	if restored := c.LoadInfo(); restored.Generation != 1 || restored.Hash != info.Hash {
+		t.Error("A failed reload should restore the load info, is", restored)
+	}
+
+	os.WriteFile(second, []byte("port = 82\n"), 0644)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if c.LoadInfo().Generation != 3 {
+		t.Error("Generation should be 3, is", c.LoadInfo().Generation)
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// StreamProvider is a Provider whose settings are pushed by a config service
+// over a stream of server-sent events, for changes that must propagate in
+// well under a second, such as operational toggles. The data of each event is
+// a TOML document that replaces the settings from the previous one.
+type StreamProvider struct {
+	url    string
+	client *http.Client
+
+	// Retry is how long Run waits before reconnecting after the stream ends
+	// or fails. The service may change it with a "retry:" field. It defaults
+	// to one second.
+	Retry time.Duration
+
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// Stream returns a StreamProvider that receives events from url. If client is
+// nil, http.DefaultClient is used. It provides no settings until Run has
+// received an event:
+//
+//	stream := config.Stream("https://config.internal/v1/stream/myapp", nil)
+//	err := c.ParseLayers(config.Layers{User: path, Providers: []config.Provider{stream}})
+//	...
+//	go stream.Run(ctx, c, log.Print)
+func Stream(url string, client *http.Client) *StreamProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &StreamProvider{url: url, client: client, Retry: time.Second}
+}
+
+func (p *StreamProvider) Name() string { return "stream:" + p.url }
+
+// Load returns the settings from the most recent event.
+func (p *StreamProvider) Load() (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.values == nil {
+		return map[string]interface{}{}, nil
+	}
+	return p.values, nil
+}
+
+// Run connects to the stream and calls c.Reload for every event, so that each
+// update is applied atomically along with c's other sources, until ctx is
+// done. An update that fails to load is discarded, and c keeps its previous
+// settings. Errors reading the stream and loading updates are passed to
+// onError, if it isn't nil, and Run reconnects after reading errors. It
+// returns ctx's error.
+func (p *StreamProvider) Run(ctx context.Context, c *ConfigSet, onError func(error)) error {
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+	for {
+		err := p.read(ctx, func(data string) {
+			if err := p.apply(c, data); err != nil {
+				report(err)
+			}
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report(fmt.Errorf("%s: %s", p.Name(), err))
+		if err := sleep(ctx, p.Retry); err != nil {
+			return err
+		}
+	}
+}
+
+// apply reloads c with the settings in an event, putting back the previous
+// settings if they fail to load.
+func (p *StreamProvider) apply(c *ConfigSet, data string) error {
+	tree, err := toml.Load(data)
+	if err != nil {
+		return fmt.Errorf("%s: the update is not valid TOML: %s", p.Name(), err)
+	}
+
+	p.mu.Lock()
+	previous := p.values
+	p.values = tree.ToMap()
+	p.mu.Unlock()
+
+	if err := c.Reload(); err != nil {
+		p.mu.Lock()
+		p.values = previous
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// read connects to the stream and calls fn with the data of each event until
+// the stream ends.
+func (p *StreamProvider) read(ctx context.Context, fn func(data string)) error {
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", p.url, resp.Status)
+	}
+
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data != nil {
+				fn(strings.Join(data, "\n"))
+				data = nil
+			}
+			continue
+		}
+		field, value := line, ""
+		if colon := strings.Index(line, ":"); colon >= 0 {
+			field, value = line[:colon], strings.TrimPrefix(line[colon+1:], " ")
+		}
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				p.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the stream ended")
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprint(w, event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	c := NewConfigSet("stream", ContinueOnError)
+	enabled := c.Bool("checkout.enabled", true)
+	port := c.Int("server.port", 8080)
+	stream := Stream(server.URL, nil)
+	err := c.ParseLayers(Layers{
+		Baseline:  []byte("[server]\nport = 9000\n"),
+		Providers: []Provider{stream},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := make(chan string, 1)
+	c.OnRotate("checkout.enabled", func(value string) { rotated <- value })
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- stream.Run(ctx, c, func(err error) { errs <- err }) }()
+
+	events <- ": hello\n\ndata: [checkout]\ndata: enabled = false\n\n"
+	select {
+	case value := <-rotated:
+		if value != "false" || *enabled || *port != 9000 {
+			t.Error("Unexpected settings:", *enabled, *port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The update wasn't applied")
+	}
+
+	events <- "data: [checkout]\ndata: enabled = 3\n\n"
+	select {
+	case err := <-errs:
+		if err.Error() != "stream:"+server.URL+" config: The value for checkout.enabled is invalid" {
+			t.Error("Unexpected error:", err)
+		}
+		if *enabled {
+			t.Error("checkout.enabled should keep its value")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The update wasn't rejected")
+	}
+
+	// An update that drops a key puts the setting back to its default.
+	events <- "data: [server]\ndata: port = 9100\n\n"
+	select {
+	case value := <-rotated:
+		if value != "true" || !*enabled || *port != 9100 {
+			t.Error("Unexpected settings after a key was dropped:", *enabled, *port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The dropped key wasn't reverted")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
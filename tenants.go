@@ -0,0 +1,65 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// Tenants defines the settings for each tenant of a multi-tenant daemon,
+// configured in tables such as [tenants.acme] under the named table. The
+// define function is called once with the ConfigSet itself, to define the
+// top-level defaults shared by every tenant, and again for each tenant table
+// with a new ConfigSet, just as for Wildcard. A tenant's settings start at the
+// top-level values from the loaded documents, and are then overridden by the
+// tenant's table:
+//
+//	limits := make(map[*config.ConfigSet]*int)
+//	tenants := c.Tenants("tenants", func(sub *config.ConfigSet) {
+//		limits[sub] = sub.Int("rate_limit", 100)
+//	})
+//
+// The returned map holds the ConfigSet for each tenant, keyed by name, and is
+// refilled every time a document containing the tenant table is loaded.
+func (c *ConfigSet) Tenants(table string, define func(sub *ConfigSet)) map[string]*ConfigSet {
+	define(c)
+
+	tenants := make(map[string]*ConfigSet)
+	w := &wildcardValue{c: c, prefix: c.prefix + table}
+	w.reset = func() {
+		for name := range tenants {
+			delete(tenants, name)
+		}
+	}
+	w.define = func(name string, sub *ConfigSet) error {
+		define(sub)
+		tenants[name] = sub
+		return c.applyDefaults(sub)
+	}
+	c.Var(w, table, "")
+	return tenants
+}
+
+// applyDefaults sets each of sub's config variables to the value given at the
+// top level of the documents loaded into c, if any.
+func (c *ConfigSet) applyDefaults(sub *ConfigSet) (err error) {
+	sub.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		keys := strings.Split(c.prefix+strings.TrimPrefix(f.Name, sub.prefix), ".")
+		for _, tree := range c.trees {
+			if tree.HasPath(keys) {
+				if err = sub.applyValue(f.Name, tree.GetPath(keys), ""); err != nil {
+					return
+				}
+			}
+		}
+	})
+	return err
+}
+
+// Tenants defines the settings for each tenant configured in tables under
+// the named table, with top-level defaults shared by every tenant.
+func Tenants(table string, define func(sub *ConfigSet)) map[string]*ConfigSet {
+	return global().Tenants(table, define)
+}
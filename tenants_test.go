@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestTenants(t *testing.T) {
+	c := NewConfigSet("tenants", ContinueOnError)
+	limits := make(map[*ConfigSet]*int)
+	tenants := c.Tenants("tenants", func(sub *ConfigSet) {
+		limits[sub] = sub.Int("rate_limit", 100)
+		sub.String("plan", "free")
+	})
+
+	err := c.parseBytes("tenants", []byte(`
+rate_limit = 200
+
+[tenants.acme]
+rate_limit = 500
+plan = "pro"
+
+[tenants.globex]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tenants) != 2 {
+		t.Fatal("Expected two tenants, got", tenants)
+	}
+	if *limits[c] != 200 {
+		t.Error("The top-level rate_limit should be 200, is", *limits[c])
+	}
+	if limit := *limits[tenants["acme"]]; limit != 500 {
+		t.Error("acme's rate_limit should be 500, is", limit)
+	}
+	if limit := *limits[tenants["globex"]]; limit != 200 {
+		t.Error("globex's rate_limit should default to the top-level value, is", limit)
+	}
+	if plan := tenants["globex"].Lookup("tenants.globex.plan").Value.String(); plan != "free" {
+		t.Error("globex's plan should be free, is", plan)
+	}
+
+	err = c.parseBytes("tenants", []byte("[tenants.initech]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 1 || tenants["initech"] == nil {
+		t.Error("The tenants should be replaced by the latest load, are", tenants)
+	}
+}
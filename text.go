@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// EnableLenientText makes the ConfigSet accept config files saved by Windows
+// editors that TOML doesn't allow: files that begin with a byte order mark,
+// files in UTF-16, and files with carriage returns alone or inside strings.
+// Before decoding, byte order marks are removed, UTF-16 is converted to
+// UTF-8, and every line ending is converted to a newline. A warning naming
+// the file is logged through the ConfigSet's logger when a file is changed,
+// so that it can be fixed.
+func (c *ConfigSet) EnableLenientText() {
+	c.lenientText = true
+}
+
+// normalizeText returns the document data converted as described by
+// EnableLenientText, if it's enabled.
+func (c *ConfigSet) normalizeText(name string, data []byte) []byte {
+	if !c.lenientText {
+		return data
+	}
+	var fixes []string
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		data = data[len(utf8BOM):]
+		fixes = append(fixes, "removed a byte order mark")
+	case bytes.HasPrefix(data, utf16LEBOM), bytes.HasPrefix(data, utf16BEBOM):
+		data = decodeUTF16(data)
+		fixes = append(fixes, "converted UTF-16 to UTF-8")
+	}
+	if bytes.IndexByte(data, '\r') >= 0 {
+		data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+		data = bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+		fixes = append(fixes, "converted line endings to newlines")
+	}
+	for _, fix := range fixes {
+		c.warnf("%s: %s", name, fix)
+	}
+	return data
+}
+
+// decodeUTF16 converts UTF-16 text that begins with a byte order mark into
+// UTF-8, without the byte order mark.
+func decodeUTF16(data []byte) []byte {
+	bigEndian := data[0] == 0xfe
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	var buf bytes.Buffer
+	var b [utf8.UTFMax]byte
+	for _, r := range utf16.Decode(units) {
+		n := utf8.EncodeRune(b[:], r)
+		buf.Write(b[:n])
+	}
+	return buf.Bytes()
+}
+
+// EnableLenientText makes the global config accept config files with byte
+// order marks, in UTF-16, or with carriage returns.
+func EnableLenientText() {
+	global().EnableLenientText()
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestEnableLenientText(t *testing.T) {
+	c := NewConfigSet("text", ContinueOnError)
+	name := c.String("name", "")
+	motd := c.String("motd", "")
+
+	utf16le := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune("name = \"Zoë\"\r\n")) {
+		utf16le = append(utf16le, byte(u), byte(u>>8))
+	}
+	docs := map[string][]byte{
+		"bom.conf":   []byte("\xef\xbb\xbfname = \"bom\"\n"),
+		"utf16.conf": utf16le,
+		"mac.conf":   []byte("name = \"mac\"\rmotd = \"\"\"a\r\nb\"\"\"\r"),
+	}
+	if err := c.parseBytes("utf16.conf", docs["utf16.conf"]); err == nil {
+		t.Error("UTF-16 should be rejected unless enabled")
+	}
+
+	var out bytes.Buffer
+	c.SetLogger(log.New(&out, "", 0))
+	c.EnableLenientText()
+	for _, file := range []string{"bom.conf", "utf16.conf", "mac.conf"} {
+		if err := c.parseBytes(file, docs[file]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *name != "mac" || *motd != "a\nb" {
+		t.Errorf("Unexpected settings: %q %q", *name, *motd)
+	}
+	expected := `config: bom.conf: removed a byte order mark
+config: utf16.conf: converted UTF-16 to UTF-8
+config: utf16.conf: converted line endings to newlines
+config: mac.conf: converted line endings to newlines
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected warnings:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := c.parseBytes("clean.conf", []byte("name = \"clean\"\n")); err != nil || out.Len() != 0 {
+		t.Error("Clean documents shouldn't be changed:", err, out.String())
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// -- encoding.TextUnmarshaler Value
+
+type textValue struct{ p encoding.TextUnmarshaler }
+
+func (t textValue) Set(s string) error {
+	return t.p.UnmarshalText([]byte(s))
+}
+
+// setTOML passes strings to UnmarshalText as they are. Other values are passed
+// in their text form, so that a TOML datetime can set a time.Time and an
+// integer can set a type that parses numbers.
+func (t textValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return t.Set(v)
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return err
+		}
+		return t.p.UnmarshalText(text)
+	case int64, float64, bool:
+		return t.Set(fmt.Sprint(v))
+	}
+	return errParse
+}
+
+func (t textValue) Get() interface{} { return t.p }
+
+// snapshot saves the value in its text form, since it may refer to memory that
+// UnmarshalText reuses.
+func (t textValue) snapshot() func() error {
+	text, err := t.p.(encoding.TextMarshaler).MarshalText()
+	return func() error {
+		if err != nil {
+			return err
+		}
+		return t.p.UnmarshalText(text)
+	}
+}
+
+func (t textValue) String() string {
+	if m, ok := t.p.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return ""
+}
+
+// TextVar defines a config with a given name and default value for a
+// ConfigSet, like flag.TextVar. The argument p must be a pointer to a
+// variable that will hold the value of the config, and p must implement
+// encoding.TextUnmarshaler. The default value, which must be of the same type
+// as the variable p points to, or a pointer to one, is copied into it, and
+// the value in the config file is passed to p's UnmarshalText method. It
+// panics if p or value don't meet these requirements.
+func (c *ConfigSet) TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler) {
+	ptrVal := reflect.ValueOf(p)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() {
+		panic(fmt.Sprintf("config: the variable for %s must be a non-nil pointer", name))
+	}
+	defVal := reflect.ValueOf(value)
+	if defVal.Kind() == reflect.Ptr {
+		defVal = defVal.Elem()
+	}
+	if defVal.Type() != ptrVal.Type().Elem() {
+		panic(fmt.Sprintf("config: invalid default for %s: %s is not %s", name, defVal.Type(), ptrVal.Type().Elem()))
+	}
+	ptrVal.Elem().Set(defVal)
+	c.Var(textValue{p}, name, "")
+}
+
+// TextVar defines a config with a given name and default value whose variable
+// implements encoding.TextUnmarshaler. The argument p must be a pointer to a
+// variable that will hold the value of the config.
+func TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler) {
+	global().TextVar(p, name, value)
+}
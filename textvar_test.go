@@ -0,0 +1,49 @@
+package config
+
+import (
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestTextVar(t *testing.T) {
+	c := NewConfigSet("textvar", ContinueOnError)
+	var addr netip.Addr
+	c.TextVar(&addr, "addr", netip.MustParseAddr("127.0.0.1"))
+	var launch time.Time
+	c.TextVar(&launch, "launch", time.Time{})
+	var supply big.Int
+	c.TextVar(&supply, "supply", big.NewInt(1))
+
+	if addr.String() != "127.0.0.1" || supply.Int64() != 1 {
+		t.Fatal("Unexpected defaults:", addr, supply.String())
+	}
+	err := c.parseBytes("textvar", []byte("addr = \"::1\"\nlaunch = 2024-01-15T09:00:00Z\nsupply = 42\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != netip.IPv6Loopback() || !launch.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)) || supply.Int64() != 42 {
+		t.Error("Unexpected values:", addr, launch, supply.String())
+	}
+	if s := c.Lookup("addr").Value.String(); s != "::1" {
+		t.Error("Unexpected string:", s)
+	}
+
+	err = c.parseBytes("textvar", []byte(`addr = "localhost"`))
+	if err == nil || err.Error() != `The value for addr is invalid: ParseAddr("localhost"): unable to parse IP` {
+		t.Error("Expected an invalid address error, got", err)
+	}
+
+	defer func() {
+		if r := recover(); r != "config: invalid default for port: config.intText is not netip.Addr" {
+			t.Error("Expected a type mismatch panic, got", r)
+		}
+	}()
+	var other netip.Addr
+	c.TextVar(&other, "port", intText(80))
+}
+
+type intText int
+
+func (i intText) MarshalText() ([]byte, error) { return []byte("80"), nil }
@@ -0,0 +1,559 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// -- Clock
+
+// Clock is a time of day without a date, such as "14:30". Clock values are
+// comparable with ==, Before, and After.
+type Clock struct {
+	Hour, Minute, Second int
+}
+
+var clockLayouts = []string{"15:04", "15:04:05", "15:04:05.999999999"}
+
+// ParseClock parses a time of day in "HH:MM" or "HH:MM:SS" form. Fractional
+// seconds are accepted and discarded.
+func ParseClock(s string) (Clock, error) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Clock{t.Hour(), t.Minute(), t.Second()}, nil
+		}
+	}
+	return Clock{}, fmt.Errorf("%q is not a valid time of day", s)
+}
+
+// Before reports whether t is earlier in the day than u.
+func (t Clock) Before(u Clock) bool { return t.sinceMidnight() < u.sinceMidnight() }
+
+// After reports whether t is later in the day than u.
+func (t Clock) After(u Clock) bool { return t.sinceMidnight() > u.sinceMidnight() }
+
+// On returns the time.Time at t on the date of d, in d's location.
+func (t Clock) On(d time.Time) time.Time {
+	year, month, day := d.Date()
+	return time.Date(year, month, day, t.Hour, t.Minute, t.Second, 0, d.Location())
+}
+
+func (t Clock) sinceMidnight() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute + time.Duration(t.Second)*time.Second
+}
+
+func (t Clock) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+func (t *Clock) Set(s string) error {
+	v, err := ParseClock(s)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// setTOML accepts strings as well as TOML local times, which format
+// themselves as "HH:MM:SS".
+func (t *Clock) setTOML(value interface{}) error {
+	return t.Set(fmt.Sprintf("%v", value))
+}
+
+func (t *Clock) Get() interface{} { return *t }
+
+func (t *Clock) snapshot() func() error {
+	v := *t
+	return func() error {
+		*t = v
+		return nil
+	}
+}
+
+// TimeOfDayVar defines a time of day config with a given name and default
+// value for a ConfigSet. The argument p points to a Clock variable in which to
+// store the value of the config. It panics if the default isn't a valid time
+// of day.
+func (c *ConfigSet) TimeOfDayVar(p *Clock, name string, value string) {
+	if err := p.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(p, name, "")
+}
+
+// TimeOfDay defines a time of day config variable with a given name and
+// default value, such as "02:15", for a ConfigSet.
+func (c *ConfigSet) TimeOfDay(name string, value string) *Clock {
+	p := new(Clock)
+	c.TimeOfDayVar(p, name, value)
+	return p
+}
+
+// TimeOfDayVar defines a time of day config with a given name and default
+// value. The argument p points to a Clock variable in which to store the value
+// of the config.
+func TimeOfDayVar(p *Clock, name string, value string) {
+	global().TimeOfDayVar(p, name, value)
+}
+
+// TimeOfDay defines a time of day config variable with a given name and
+// default value.
+func TimeOfDay(name string, value string) *Clock {
+	return global().TimeOfDay(name, value)
+}
+
+// -- RateLimit
+
+// RateLimit is a number of events allowed per interval, written in config
+// files as "100/s", "5000/m", or "10/5m".
+type RateLimit struct {
+	Count int64
+	Per   time.Duration
+}
+
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseRateLimit parses a rate in "count/interval" form. The interval is "s",
+// "m", "h", or any duration accepted by time.ParseDuration.
+func ParseRateLimit(s string) (RateLimit, error) {
+	slash := strings.Index(s, "/")
+	if slash < 0 {
+		return RateLimit{}, fmt.Errorf("%q is not of the form count/interval", s)
+	}
+
+	count, err := strconv.ParseInt(strings.TrimSpace(s[:slash]), 10, 64)
+	if err != nil || count < 0 {
+		return RateLimit{}, fmt.Errorf("%q does not have a valid count", s)
+	}
+
+	unit := strings.TrimSpace(s[slash+1:])
+	per, ok := rateUnits[unit]
+	if !ok {
+		per, err = time.ParseDuration(unit)
+		if err != nil || per <= 0 {
+			return RateLimit{}, fmt.Errorf("%q does not have a valid interval", s)
+		}
+	}
+
+	return RateLimit{count, per}, nil
+}
+
+// PerSecond returns the rate in events per second.
+func (r RateLimit) PerSecond() float64 {
+	if r.Per == 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Per.Seconds()
+}
+
+// Interval returns the average time between events, or zero if the count is
+// zero.
+func (r RateLimit) Interval() time.Duration {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Count)
+}
+
+func (r RateLimit) String() string {
+	for unit, per := range rateUnits {
+		if r.Per == per {
+			return fmt.Sprintf("%d/%s", r.Count, unit)
+		}
+	}
+	return fmt.Sprintf("%d/%s", r.Count, r.Per)
+}
+
+func (r *RateLimit) Set(s string) error {
+	v, err := ParseRateLimit(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (r *RateLimit) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return r.Set(s)
+	}
+	return errParse
+}
+
+func (r *RateLimit) Get() interface{} { return *r }
+
+func (r *RateLimit) snapshot() func() error {
+	v := *r
+	return func() error {
+		*r = v
+		return nil
+	}
+}
+
+// RateVar defines a rate config with a given name and default value for a
+// ConfigSet. The argument p points to a RateLimit variable in which to store
+// the value of the config. It panics if the default isn't a valid rate.
+func (c *ConfigSet) RateVar(p *RateLimit, name string, value string) {
+	if err := p.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(p, name, "")
+}
+
+// Rate defines a rate config variable with a given name and default value,
+// such as "100/s", for a ConfigSet.
+func (c *ConfigSet) Rate(name string, value string) *RateLimit {
+	p := new(RateLimit)
+	c.RateVar(p, name, value)
+	return p
+}
+
+// RateVar defines a rate config with a given name and default value. The
+// argument p points to a RateLimit variable in which to store the value of the
+// config.
+func RateVar(p *RateLimit, name string, value string) {
+	global().RateVar(p, name, value)
+}
+
+// Rate defines a rate config variable with a given name and default value.
+func Rate(name string, value string) *RateLimit {
+	return global().Rate(name, value)
+}
+
+// -- time.Time Value
+
+// localLayout is the layout of datetimes without an offset, which are
+// interpreted in the local time zone.
+const localLayout = "2006-01-02T15:04:05.999999999"
+
+// canonicalDatetime rewrites the other forms of datetimes that TOML allows,
+// with a space instead of the "T" or in lower case, such as
+// "1979-05-27 07:32:00z", in the form time.RFC3339 expects.
+func canonicalDatetime(s string) string {
+	s = strings.ToUpper(s)
+	if len(s) > 10 && s[10] == ' ' {
+		s = s[:10] + "T" + s[11:]
+	}
+	return s
+}
+
+type timeValue struct {
+	p             *time.Time
+	requireOffset bool
+}
+
+// Set parses an RFC 3339 datetime, keeping its offset rather than converting
+// it to UTC or local time. Unless an offset is required, datetimes without one
+// are accepted and interpreted in the local time zone. The date and time may
+// be separated by a space, as TOML allows.
+func (t *timeValue) Set(s string) error {
+	canonical := canonicalDatetime(s)
+	if v, err := time.Parse(time.RFC3339Nano, canonical); err == nil {
+		*t.p = v
+		return nil
+	}
+	v, err := time.ParseInLocation(localLayout, canonical, time.Local)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid datetime", s)
+	}
+	if t.requireOffset {
+		return fmt.Errorf("%q has no time zone offset", s)
+	}
+	*t.p = v
+	return nil
+}
+
+func (t *timeValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*t.p = v
+	case toml.LocalDateTime:
+		if t.requireOffset {
+			return fmt.Errorf("%s has no time zone offset", v)
+		}
+		*t.p = v.In(time.Local)
+	case string:
+		return t.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (t *timeValue) Get() interface{} { return *t.p }
+
+func (t *timeValue) snapshot() func() error {
+	v := *t.p
+	return func() error {
+		*t.p = v
+		return nil
+	}
+}
+
+func (t *timeValue) String() string {
+	if t.p == nil {
+		return ""
+	}
+	return t.p.Format(time.RFC3339Nano)
+}
+
+// TimeVar defines a time.Time config with a given name and default value for
+// a ConfigSet. The argument p points to a time.Time variable in which to store
+// the value of the config. Datetimes with an offset, such as
+// 1979-05-27T07:32:00-08:00, keep that offset; those without one are
+// interpreted in the local time zone.
+func (c *ConfigSet) TimeVar(p *time.Time, name string, value time.Time) {
+	*p = value
+	c.Var(&timeValue{p: p}, name, "")
+}
+
+// Time defines a time.Time config variable with a given name and default value
+// for a ConfigSet.
+func (c *ConfigSet) Time(name string, value time.Time) *time.Time {
+	p := new(time.Time)
+	c.TimeVar(p, name, value)
+	return p
+}
+
+// ZonedTimeVar is like TimeVar, but the datetime in the config file must have
+// an explicit offset. Use it for settings such as schedules, where guessing
+// the time zone would be a mistake.
+func (c *ConfigSet) ZonedTimeVar(p *time.Time, name string, value time.Time) {
+	*p = value
+	c.Var(&timeValue{p: p, requireOffset: true}, name, "")
+}
+
+// ZonedTime is like Time, but the datetime in the config file must have an
+// explicit offset.
+func (c *ConfigSet) ZonedTime(name string, value time.Time) *time.Time {
+	p := new(time.Time)
+	c.ZonedTimeVar(p, name, value)
+	return p
+}
+
+// TimeVar defines a time.Time config with a given name and default value. The
+// argument p points to a time.Time variable in which to store the value of the
+// config.
+func TimeVar(p *time.Time, name string, value time.Time) {
+	global().TimeVar(p, name, value)
+}
+
+// Time defines a time.Time config variable with a given name and default
+// value.
+func Time(name string, value time.Time) *time.Time {
+	return global().Time(name, value)
+}
+
+// ZonedTimeVar defines a time.Time config with a given name and default value
+// that must be given with an explicit offset. The argument p points to a
+// time.Time variable in which to store the value of the config.
+func ZonedTimeVar(p *time.Time, name string, value time.Time) {
+	global().ZonedTimeVar(p, name, value)
+}
+
+// ZonedTime defines a time.Time config variable with a given name and default
+// value that must be given with an explicit offset.
+func ZonedTime(name string, value time.Time) *time.Time {
+	return global().ZonedTime(name, value)
+}
+
+// -- Date Value
+
+const dateLayout = "2006-01-02"
+
+// lineEndDate matches a local date at the end of a line, such as
+// "cutoff = 2024-01-15".
+var lineEndDate = regexp.MustCompile(`(?m)(=[ \t]*\d{4}-\d{2}-\d{2})(\r?)$`)
+
+// loadTOML decodes a TOML document. go-toml rejects a local date at the end of
+// a line, expecting a time to follow, so a document it rejects for that reason
+// is decoded again with a space after each such date.
+func loadTOML(data []byte) (*toml.Tree, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil && strings.Contains(err.Error(), "incorrect date/time separation character") {
+		if padded, err := toml.LoadBytes(padLineEndDates(data)); err == nil {
+			return padded, nil
+		}
+	}
+	return tree, err
+}
+
+// padLineEndDates adds a space after each local date that ends a line,
+// leaving the lines of multi-line strings as they are.
+func padLineEndDates(data []byte) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	var s valueScanner
+	for i, line := range lines {
+		inString := s.quote != ""
+		s.scan(line)
+		if !inString && s.quote == "" {
+			lines[i] = lineEndDate.ReplaceAllString(line, "$1 $2")
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+type dateValue struct{ p *time.Time }
+
+// Set parses a date such as "2024-01-15" as midnight in the local time zone.
+// An empty string sets the zero time.
+func (d dateValue) Set(s string) error {
+	if s == "" {
+		*d.p = time.Time{}
+		return nil
+	}
+	v, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid date", s)
+	}
+	*d.p = v
+	return nil
+}
+
+// setTOML accepts TOML local dates and strings. Datetimes are rejected rather
+// than truncated, since their time of day is probably a mistake.
+func (d dateValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case toml.LocalDate:
+		*d.p = v.In(time.Local)
+	case string:
+		return d.Set(v)
+	case time.Time, toml.LocalDateTime:
+		return fmt.Errorf("%v is a datetime, not a date", v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (d dateValue) Get() interface{} { return *d.p }
+
+func (d dateValue) snapshot() func() error {
+	v := *d.p
+	return func() error {
+		*d.p = v
+		return nil
+	}
+}
+
+func (d dateValue) String() string {
+	if d.p == nil || d.p.IsZero() {
+		return ""
+	}
+	return d.p.Format(dateLayout)
+}
+
+// DateVar defines a date config with a given name and default value for a
+// ConfigSet. The argument p points to a time.Time variable in which to store
+// the value of the config as midnight in the local time zone. It panics if
+// the default isn't a valid date.
+func (c *ConfigSet) DateVar(p *time.Time, name string, value string) {
+	if err := (dateValue{p}).Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(dateValue{p}, name, "")
+}
+
+// Date defines a date config variable with a given name and default value,
+// such as "2024-01-15", for a ConfigSet. The TOML value must be a local date,
+// such as 2024-01-15, or a string in the same form. An empty default leaves
+// the time zero.
+func (c *ConfigSet) Date(name string, value string) *time.Time {
+	p := new(time.Time)
+	c.DateVar(p, name, value)
+	return p
+}
+
+// DateVar defines a date config with a given name and default value. The
+// argument p points to a time.Time variable in which to store the value of the
+// config.
+func DateVar(p *time.Time, name string, value string) {
+	global().DateVar(p, name, value)
+}
+
+// Date defines a date config variable with a given name and default value.
+func Date(name string, value string) *time.Time {
+	return global().Date(name, value)
+}
+
+// -- time.Location Value
+
+type locationValue struct{ p **time.Location }
+
+func (l locationValue) Set(s string) error {
+	v, err := time.LoadLocation(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a known time zone", s)
+	}
+	*l.p = v
+	return nil
+}
+
+func (l locationValue) setTOML(value interface{}) error {
+	if s, ok := value.(string); ok {
+		return l.Set(s)
+	}
+	return errParse
+}
+
+func (l locationValue) Get() interface{} { return *l.p }
+
+func (l locationValue) snapshot() func() error {
+	v := *l.p
+	return func() error {
+		*l.p = v
+		return nil
+	}
+}
+
+func (l locationValue) String() string {
+	if l.p == nil || *l.p == nil {
+		return ""
+	}
+	return (*l.p).String()
+}
+
+// LocationVar defines a time zone config with a given name and default value
+// for a ConfigSet. The argument p points to a *time.Location variable in which
+// to store the value of the config. Values are IANA time zone names, such as
+// "America/New_York", or "UTC" or "Local", and are loaded with
+// time.LoadLocation when the config is set, so an unknown zone fails the load.
+// Programs that may run without a time zone database can import time/tzdata.
+// It panics if the default isn't a known time zone.
+func (c *ConfigSet) LocationVar(p **time.Location, name string, value string) {
+	if err := (locationValue{p}).Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(locationValue{p}, name, "")
+}
+
+// Location defines a time zone config variable with a given name and default
+// value, such as "UTC", for a ConfigSet.
+func (c *ConfigSet) Location(name string, value string) **time.Location {
+	p := new(*time.Location)
+	c.LocationVar(p, name, value)
+	return p
+}
+
+// LocationVar defines a time zone config with a given name and default value.
+// The argument p points to a *time.Location variable in which to store the
+// value of the config.
+func LocationVar(p **time.Location, name string, value string) {
+	global().LocationVar(p, name, value)
+}
+
+// Location defines a time zone config variable with a given name and default
+// value.
+func Location(name string, value string) **time.Location {
+	return global().Location(name, value)
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	c := NewConfigSet("time", ContinueOnError)
+	start := c.TimeOfDay("quiet.start", "22:00")
+	end := c.TimeOfDay("quiet.end", "06:00")
+
+	err := c.parseBytes("time", []byte("[quiet]\nstart = \"23:30\"\nend = 07:15:30\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *start != (Clock{23, 30, 0}) {
+		t.Error("quiet.start should be 23:30:00, is", start)
+	}
+	if *end != (Clock{7, 15, 30}) {
+		t.Error("quiet.end should be 07:15:30, is", end)
+	}
+	if !end.Before(*start) || end.After(*start) {
+		t.Error("quiet.end should be before quiet.start")
+	}
+
+	day := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	if got := start.On(day); !got.Equal(time.Date(2024, 1, 15, 23, 30, 0, 0, time.UTC)) {
+		t.Error("Unexpected time:", got)
+	}
+
+	err = c.parseBytes("time", []byte("[quiet]\nstart = \"25:00\"\n"))
+	if err == nil || err.Error() != `The value for quiet.start is invalid: "25:00" is not a valid time of day` {
+		t.Error("Expected an invalid time of day error, got", err)
+	}
+}
+
+func TestRate(t *testing.T) {
+	c := NewConfigSet("time", ContinueOnError)
+	api := c.Rate("limits.api", "100/s")
+	batch := c.Rate("limits.batch", "1/h")
+
+	err := c.parseBytes("time", []byte("[limits]\napi = \"5000/m\"\nbatch = \"10/5m\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *api != (RateLimit{5000, time.Minute}) || api.String() != "5000/m" {
+		t.Error("limits.api should be 5000/m, is", api)
+	}
+	if *batch != (RateLimit{10, 5 * time.Minute}) || batch.Interval() != 30*time.Second {
+		t.Error("limits.batch should be 10/5m, is", batch)
+	}
+
+	for _, bad := range []string{"100", "x/s", "-1/s", "10/fortnight", "10/0s"} {
+		if _, err := ParseRateLimit(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	c := NewConfigSet("time", ContinueOnError)
+	launch := c.Time("launch", time.Time{})
+	local := c.Time("local", time.Time{})
+	cutover := c.ZonedTime("cutover", time.Time{})
+
+	err := c.parseBytes("time", []byte("launch = 1979-05-27T07:32:00-08:00\nlocal = 2024-01-15T09:00:00\ncutover = \"2024-03-10T02:00:00+05:30\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, offset := launch.Zone(); offset != -8*60*60 || launch.Hour() != 7 {
+		t.Error("launch should keep its -08:00 offset, is", launch)
+	}
+	if !local.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)) {
+		t.Error("local should be in the local time zone, is", local)
+	}
+	if _, offset := cutover.Zone(); offset != 5*60*60+30*60 || cutover.Hour() != 2 {
+		t.Error("cutover should keep its +05:30 offset, is", cutover)
+	}
+
+	err = c.parseBytes("time", []byte("launch = \"1979-05-27 07:32:00z\"\nlocal = \"2024-01-15 09:00:00.5\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !launch.Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)) {
+		t.Error("launch should be in UTC, is", launch)
+	}
+	if !local.Equal(time.Date(2024, 1, 15, 9, 0, 0, 5e8, time.Local)) {
+		t.Error("local should be in the local time zone, is", local)
+	}
+	if err := c.Set("launch", "1979-05-27"); err == nil || err.Error() != `"1979-05-27" is not a valid datetime` {
+		t.Error("Expected an invalid datetime error, got", err)
+	}
+
+	err = c.parseBytes("time", []byte("cutover = 2024-03-10T02:00:00\n"))
+	if err == nil || err.Error() != "The value for cutover is invalid: 2024-03-10T02:00:00 has no time zone offset" {
+		t.Error("Expected a missing offset error, got", err)
+	}
+	err = c.parseBytes("time", []byte("cutover = \"2024-03-10 02:00:00\"\n"))
+	if err == nil || err.Error() != `The value for cutover is invalid: "2024-03-10 02:00:00" has no time zone offset` {
+		t.Error("Expected a missing offset error, got", err)
+	}
+}
+
+func TestDate(t *testing.T) {
+	c := NewConfigSet("time", ContinueOnError)
+	cutoff := c.Date("billing.cutoff", "2024-01-01")
+	launch := c.Date("launch", "")
+
+	if !cutoff.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)) || !launch.IsZero() {
+		t.Fatal("Unexpected defaults:", cutoff, launch)
+	}
+
+	err := c.parseBytes("time", []byte("launch = \"2024-06-30\"\n[billing]\ncutoff = 2024-01-15\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cutoff.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)) {
+		t.Error("cutoff should be midnight on 2024-01-15, is", cutoff)
+	}
+	if s := c.Lookup("launch").Value.String(); s != "2024-06-30" {
+		t.Error("Unexpected string:", s)
+	}
+
+	c.String("notes", "")
+	err = c.parseBytes("time", []byte("notes = \"\"\"\nstart = 2024-01-15\n\"\"\"\nlaunch = 2024-06-30\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Lookup("notes").Value.String(); s != "start = 2024-01-15\n" {
+		t.Errorf("A multi-line string should be kept as it is, is %q", s)
+	}
+
+	err = c.parseBytes("time", []byte("launch = 2024-06-30T12:00:00\n"))
+	if err == nil || err.Error() != "The value for launch is invalid: 2024-06-30T12:00:00 is a datetime, not a date" {
+		t.Error("Expected a datetime error, got", err)
+	}
+	err = c.parseBytes("time", []byte("launch = \"June 30\"\n"))
+	if err == nil || err.Error() != `The value for launch is invalid: "June 30" is not a valid date` {
+		t.Error("Expected an invalid date error, got", err)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	c := NewConfigSet("time", ContinueOnError)
+	tz := c.Location("timezone", "UTC")
+
+	if *tz != time.UTC {
+		t.Fatal("timezone should default to UTC, is", *tz)
+	}
+	if err := c.parseBytes("time", []byte(`timezone = "America/New_York"`)); err != nil {
+		t.Fatal(err)
+	}
+	if (*tz).String() != "America/New_York" {
+		t.Error("timezone should be America/New_York, is", *tz)
+	}
+	if _, offset := time.Date(2024, 1, 15, 0, 0, 0, 0, *tz).Zone(); offset != -5*60*60 {
+		t.Error("Unexpected offset:", offset)
+	}
+
+	err := c.parseBytes("time", []byte(`timezone = "America/Springfield"`))
+	if err == nil || err.Error() != `The value for timezone is invalid: "America/Springfield" is not a known time zone` {
+		t.Error("Expected an unknown time zone error, got", err)
+	}
+}
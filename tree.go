@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Tree returns the decoded TOML document most recently loaded into the
+// ConfigSet, or nil if none has been loaded. When several documents are loaded
+// at once, as by ParseFiles or ParseLayers, it's the last of them. The tree
+// gives access to key positions and comments, and is shared with the
+// ConfigSet, so it must not be modified.
+func (c *ConfigSet) Tree() *toml.Tree {
+	if len(c.trees) == 0 {
+		return nil
+	}
+	return c.trees[len(c.trees)-1]
+}
+
+// -- wildcard Value
+
+type wildcardValue struct {
+	c      *ConfigSet
+	prefix string
+	define func(name string, sub *ConfigSet) error
+	names  []string
+
+	// reset, if set, is called before the tables are loaded.
+	reset func()
+
+	// appending makes setTOML add tables to the ones already loaded instead
+	// of replacing them, for documents loaded a section at a time.
+	appending bool
+}
+
+func (w *wildcardValue) Set(s string) error { return errParse }
+
+// setTOML loads each table in a table of tables, or each element of an array
+// of tables, into a new ConfigSet.
+func (w *wildcardValue) setTOML(value interface{}) error {
+	var names []string
+	tables := make(map[string]*toml.Tree)
+	switch v := value.(type) {
+	case *toml.Tree:
+		for _, key := range v.Keys() {
+			table, ok := v.Get(key).(*toml.Tree)
+			if !ok {
+				return fmt.Errorf("%s is not a table", key)
+			}
+			names = append(names, key)
+			tables[key] = table
+		}
+		sort.Strings(names)
+	case []*toml.Tree:
+		offset := 0
+		if w.appending {
+			offset = len(w.names)
+		}
+		for i, table := range v {
+			name := fmt.Sprint(offset + i)
+			names = append(names, name)
+			tables[name] = table
+		}
+	default:
+		return errParse
+	}
+
+	if w.reset != nil && !w.appending {
+		w.reset()
+	}
+	for _, name := range names {
+		path := append(strings.Split(w.prefix, "."), name)
+		sub := NewConfigSet(strings.Join(path, "."), ContinueOnError)
+		sub.prefix = sub.Name() + "."
+		sub.logger = w.c.logger
+		sub.errorTemplates = w.c.errorTemplates
+		sub.loading = w.c.loading
+		sub.lineOffset = w.c.lineOffset
+		if err := w.define(name, sub); err != nil {
+			return loadError{err}
+		}
+		if err := sub.loadTomlTree(tables[name], path); err != nil {
+			return loadError{err}
+		}
+	}
+	if w.appending {
+		names = append(w.names, names...)
+	}
+	w.names = names
+	return nil
+}
+
+func (w *wildcardValue) isTable() {}
+
+// snapshot restores the names of the tables loaded. The settings within them
+// aren't restored.
+func (w *wildcardValue) snapshot() func() error {
+	names := w.names
+	return func() error {
+		w.names = names
+		return nil
+	}
+}
+
+func (w *wildcardValue) String() string {
+	if w == nil {
+		return ""
+	}
+	return strings.Join(w.names, ",")
+}
+
+// Wildcard defines settings repeated under every table matching pattern,
+// which must end in ".*", such as "upstreams.*". Each matching table may be a
+// named table, such as [upstreams.primary], or an element of an array of
+// tables, such as [[upstreams]], which is named by its index. For each one,
+// define is called with the table's name and a new ConfigSet on which to
+// define that table's settings, which are then loaded from the table:
+//
+//	hosts := make(map[string]*string)
+//	c.Wildcard("upstreams.*", func(name string, sub *config.ConfigSet) {
+//		hosts[name] = sub.String("host", "localhost")
+//	})
+func (c *ConfigSet) Wildcard(pattern string, define func(name string, sub *ConfigSet)) {
+	if !strings.HasSuffix(pattern, ".*") {
+		panic("config: Wildcard pattern must end in \".*\": " + pattern)
+	}
+	prefix := c.prefix + strings.TrimSuffix(pattern, ".*")
+	w := &wildcardValue{c: c, prefix: prefix}
+	w.define = func(name string, sub *ConfigSet) error {
+		define(name, sub)
+		return nil
+	}
+	c.Var(w, strings.TrimSuffix(pattern, ".*"), "")
+}
+
+// Provided reports whether the named key was present in any TOML document
+// loaded by the most recent Parse call. Unlike comparing a config against its
+// default, this distinguishes a key that was explicitly set to its default
+// value from one that was left out.
+func (c *ConfigSet) Provided(name string) bool {
+	keys := strings.Split(name, ".")
+	for _, tree := range c.trees {
+		if tree.HasPath(keys) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvidedKeys returns the sorted names of every value in the TOML documents
+// loaded by the most recent Parse call. Tables loaded by a single config, such
+// as a KeyValueList, are reported by the table's name.
+func (c *ConfigSet) ProvidedKeys() []string {
+	seen := make(map[string]bool)
+	for _, tree := range c.trees {
+		c.collectKeys(tree, nil, seen)
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *ConfigSet) collectKeys(tree *toml.Tree, path []string, seen map[string]bool) {
+	for _, key := range tree.Keys() {
+		fullPath := append(path[:len(path):len(path)], key)
+		if subtree, isTree := tree.Get(key).(*toml.Tree); isTree && !c.isTable(fullPath) {
+			c.collectKeys(subtree, fullPath, seen)
+		} else {
+			seen[strings.Join(fullPath, ".")] = true
+		}
+	}
+}
+
+// -- raw table Value
+
+// rawTableValue accepts any TOML table without validating its contents. The
+// contents are read back with ConfigSet.Table.
+type rawTableValue struct{}
+
+func (rawTableValue) Set(s string) error { return errParse }
+
+func (rawTableValue) setTOML(value interface{}) error {
+	if _, ok := value.(*toml.Tree); !ok {
+		return errParse
+	}
+	return nil
+}
+
+func (rawTableValue) isTable() {}
+
+// snapshot restores nothing, since the table's contents are kept with the
+// ConfigSet's trees.
+func (rawTableValue) snapshot() func() error {
+	return func() error { return nil }
+}
+
+func (rawTableValue) String() string { return "" }
+
+// RawTable declares a table whose contents are accepted as-is instead of each
+// key needing its own config variable, for sections whose schema isn't known
+// in advance, such as plugin settings. Use Table to read the contents after
+// Parse.
+func (c *ConfigSet) RawTable(name string) {
+	c.Var(rawTableValue{}, name, "")
+}
+
+// Table returns the contents of the named table from the TOML documents
+// loaded by the most recent Parse call. If several documents define the
+// table, their contents are merged, with later documents taking precedence.
+// It returns a nil map if no document defines the table, and an error if the
+// name refers to a value that isn't a table.
+func (c *ConfigSet) Table(name string) (map[string]interface{}, error) {
+	keys := strings.Split(name, ".")
+	var table map[string]interface{}
+	for _, tree := range c.trees {
+		value := tree.GetPath(keys)
+		if value == nil {
+			continue
+		}
+		subtree, ok := value.(*toml.Tree)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a table", name)
+		}
+		if table == nil {
+			table = make(map[string]interface{})
+		}
+		mergeMaps(table, subtree.ToMap())
+	}
+	return table, nil
+}
+
+// mergeMaps deeply merges src into dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+		} else {
+			dst[key] = value
+		}
+	}
+}
+
+// Provided reports whether the named key was present in any TOML document
+// loaded into the global ConfigSet.
+func Provided(name string) bool {
+	return global().Provided(name)
+}
+
+// ProvidedKeys returns the sorted names of every value in the TOML documents
+// loaded into the global ConfigSet.
+func ProvidedKeys() []string {
+	return global().ProvidedKeys()
+}
+
+// Tree returns the decoded TOML document most recently loaded into the global
+// ConfigSet. See ConfigSet.Tree.
+func Tree() *toml.Tree {
+	return global().Tree()
+}
+
+// RawTable declares a table in the global ConfigSet whose contents are
+// accepted as-is. See ConfigSet.RawTable.
+func RawTable(name string) {
+	global().RawTable(name)
+}
+
+// Table returns the contents of the named table from the TOML documents
+// loaded into the global ConfigSet. See ConfigSet.Table.
+func Table(name string) (map[string]interface{}, error) {
+	return global().Table(name)
+}
+
+// Wildcard defines settings repeated under every table matching pattern in the
+// global ConfigSet. See ConfigSet.Wildcard.
+func Wildcard(pattern string, define func(name string, sub *ConfigSet)) {
+	global().Wildcard(pattern, define)
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProvided(t *testing.T) {
+	c := NewConfigSet("tree", ContinueOnError)
+	c.Int("port", 8080)
+	c.String("host", "localhost")
+	c.KeyValueList("headers")
+	c.Bool("tls.enabled", false)
+
+	err := c.ParseLayers(Layers{
+		Baseline: []byte("port = 8080\n[headers]\nA = \"1\"\n"),
+		User:     SIMPLE_CONFIG_PATH + ".missing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.Provided("port") {
+		t.Error("port should be provided even though it matches its default")
+	}
+	if c.Provided("host") || c.Provided("tls.enabled") {
+		t.Error("host and tls.enabled should not be provided")
+	}
+	if expected := []string{"headers", "port"}; !reflect.DeepEqual(c.ProvidedKeys(), expected) {
+		t.Errorf("ProvidedKeys should be %v, is %v", expected, c.ProvidedKeys())
+	}
+}
+
+func TestTable(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.conf")
+	override := filepath.Join(dir, "override.conf")
+	if err := os.WriteFile(base, []byte("port = 1\n[plugins.auth]\nenabled = false\nmode = \"basic\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(override, []byte("[plugins.auth]\nenabled = true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfigSet("tree", ContinueOnError)
+	c.RawTable("plugins")
+	c.Int("port", 0)
+
+	if err := c.ParseFiles(base, override); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := c.Table("plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"auth": map[string]interface{}{"enabled": true, "mode": "basic"},
+	}
+	if !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("plugins should be %v, is %v", expected, plugins)
+	}
+
+	if table, err := c.Table("missing"); table != nil || err != nil {
+		t.Errorf("missing should be absent, is %v (%v)", table, err)
+	}
+	if _, err := c.Table("port"); err == nil || err.Error() != "port is not a table" {
+		t.Error("Expected a not-a-table error, got", err)
+	}
+}
+
+func TestTree(t *testing.T) {
+	c := NewConfigSet("tree", ContinueOnError)
+	c.String("section.name", "")
+	c.String("places.california.name", "")
+
+	if c.Tree() != nil {
+		t.Fatal("Tree should be nil before loading")
+	}
+
+	c.Bool("my_bool", false)
+	c.Int("my_int", 0)
+	c.Int64("my_bigint", 0)
+	c.Uint("my_uint", 0)
+	c.Uint64("my_biguint", 0)
+	c.String("my_string", "")
+	c.Float64("my_bigfloat", 0)
+	if err := c.Parse(GOOD_CONFIG_PATH); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := c.Tree()
+	if tree == nil {
+		t.Fatal("Tree should not be nil after loading")
+	}
+	if pos := tree.GetPosition("section.name"); pos.Line != 12 {
+		t.Error("section.name should be on line 12, is on", pos.Line)
+	}
+}
+
+func TestWildcard(t *testing.T) {
+	c := NewConfigSet("tree", ContinueOnError)
+	hosts := make(map[string]*string)
+	c.Wildcard("upstreams.*", func(name string, sub *ConfigSet) {
+		hosts[name] = sub.String("host", "localhost")
+		sub.Int("port", 80)
+	})
+	weights := make(map[string]*int)
+	c.Wildcard("tenants.*", func(name string, sub *ConfigSet) {
+		weights[name] = sub.Int("weight", 1)
+	})
+
+	err := c.parseBytes("tree", []byte(`
+[[upstreams]]
+host = "a.internal"
+
+[[upstreams]]
+port = 8080
+
+[tenants.acme]
+weight = 5
+
+[tenants.globex]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hosts) != 2 || *hosts["0"] != "a.internal" || *hosts["1"] != "localhost" {
+		t.Errorf("Unexpected upstream hosts: %v", hosts)
+	}
+	if len(weights) != 2 || *weights["acme"] != 5 || *weights["globex"] != 1 {
+		t.Errorf("Unexpected tenant weights: %v", weights)
+	}
+
+	err = c.parseBytes("tree", []byte("[tenants.acme]\nweight = \"heavy\"\n"))
+	if err == nil || err.Error() != "The value for tenants.acme.weight is invalid" {
+		t.Error("Expected an invalid value error, got", err)
+	}
+	err = c.parseBytes("tree", []byte("[tenants.acme]\ncolor = \"red\"\n"))
+	if err == nil || err.Error() != "tenants.acme.color is not a valid config setting" {
+		t.Error("Expected an unknown setting error, got", err)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errTxDone is returned by Commit if the transaction has already been
+// committed or discarded.
+var errTxDone = errors.New("config: the transaction has already been committed or discarded")
+
+// Tx is a batch of changes to a ConfigSet's config variables that are
+// applied together by Commit. It's created by Begin.
+type Tx struct {
+	c       *ConfigSet
+	changes [][2]string
+	done    bool
+}
+
+// Begin starts a transaction, so that related configs can be changed
+// together, such as by an admin API or a pushed update:
+//
+//	tx := c.Begin()
+//	tx.Set("db.host", "db2.example.com")
+//	tx.Set("db.port", "5433")
+//	err := tx.Commit()
+func (c *ConfigSet) Begin() *Tx {
+	return &Tx{c: c}
+}
+
+// Set adds a change to the transaction. The value is checked by Commit.
+func (tx *Tx) Set(name, value string) {
+	tx.changes = append(tx.changes, [2]string{tx.c.prefix + name, value})
+}
+
+// Discard abandons the transaction without changing any configs.
+func (tx *Tx) Discard() {
+	tx.done = true
+}
+
+// Commit applies every change in the transaction, in the order they were
+// made. If any config isn't defined or can't hold its new value, or a change
+// can't be saved by the ConfigSet's Persister, no configs are changed and the
+// error is returned; changes the Persister had already saved are undone by
+// saving the previous values again. Otherwise the OnRotate callbacks are called once for the
+// whole batch, Previous returns the settings from before the transaction, and
+// Rollback undoes it. It returns a *FrozenError if the ConfigSet has been
+// frozen.
+func (tx *Tx) Commit() error {
+	c := tx.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tx.done {
+		return errTxDone
+	}
+	tx.done = true
+	if c.frozen {
+		if len(tx.changes) == 0 {
+			return nil
+		}
+		return &FrozenError{tx.changes[0][0], caller()}
+	}
+
+	s := c.takeSnapshot()
+	previous := c.Current()
+	if err := tx.apply(); err != nil {
+		if restoreErr := c.restore(s); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+	names := make([]string, len(tx.changes))
+	for i, change := range tx.changes {
+		names[i] = change[0]
+	}
+	c.recordSet(names...)
+	c.derive()
+	c.invalidateCache()
+	c.previous, c.rollback = previous, s
+	c.checkRotations(true)
+	return nil
+}
+
+func (tx *Tx) apply() error {
+	c := tx.c
+	// restorers save the value each changed config had before the
+	// transaction, in the order they were first changed.
+	var changed []restorer
+	seen := make(map[string]bool)
+	for _, change := range tx.changes {
+		name, value := change[0], change[1]
+		f := c.Lookup(name)
+		if f == nil {
+			err := errors.New(name + " is not a valid config setting")
+			return c.formatError(UnknownKey, ErrorData{Name: name}, err)
+		}
+		if !seen[name] {
+			seen[name] = true
+			changed = append(changed, restorer{name, saveValue(f.Value)})
+		}
+		if err := f.Value.Set(value); err != nil {
+			return c.valueError(name, err, "")
+		}
+	}
+	if c.persister == nil {
+		return nil
+	}
+	for i, r := range changed {
+		if err := c.persistValue(c.Lookup(r.name)); err != nil {
+			if undoErr := tx.unpersist(changed[:i]); undoErr != nil {
+				return fmt.Errorf("%s, and the changes already saved can't be undone: %s", err, undoErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// unpersist undoes changes that have been saved by the Persister, by
+// restoring each config's previous value and saving it again.
+func (tx *Tx) unpersist(saved []restorer) error {
+	c := tx.c
+	var errs []error
+	for i := len(saved) - 1; i >= 0; i-- {
+		r := saved[i]
+		if err := r.restore(); err != nil {
+			errs = append(errs, fmt.Errorf("config: can't restore %s: %s", r.name, err))
+			continue
+		}
+		if err := c.persistValue(c.Lookup(r.name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Begin starts a transaction on the global config.
+func Begin() *Tx {
+	return global().Begin()
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestTx(t *testing.T) {
+	c := NewConfigSet("tx", ContinueOnError)
+	host := c.String("db.host", "db1")
+	port := c.Int("db.port", 5432)
+	if err := c.ParseEnvOnly(); err != nil {
+		t.Fatal(err)
+	}
+	var events int
+	c.OnRotate("db.host", func(string) { events++ })
+	c.OnRotate("db.port", func(string) { events++ })
+
+	tx := c.Begin()
+	tx.Set("db.host", "db2")
+	tx.Set("db.port", "x")
+	err := tx.Commit()
+	if err == nil || err.Error() != "The value for db.port is invalid" {
+		t.Error("Unexpected error:", err)
+	}
+	if *host != "db1" || *port != 5432 || events != 0 {
+		t.Error("No configs should change after a failed commit:", *host, *port, events)
+	}
+	if err := tx.Commit(); err != errTxDone {
+		t.Error("Expected errTxDone, got", err)
+	}
+
+	tx = c.Begin()
+	tx.Set("db.host", "db2")
+	tx.Set("db.user", "app")
+	if err := tx.Commit(); err == nil || err.Error() != "db.user is not a valid config setting" {
+		t.Error("Unexpected error:", err)
+	}
+
+	tx = c.Begin()
+	tx.Set("db.host", "db2")
+	tx.Set("db.port", "5433")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if *host != "db2" || *port != 5433 || events != 2 {
+		t.Error("Unexpected result:", *host, *port, events)
+	}
+	if changed := c.Previous().Changed(c.Current()); len(changed) != 2 {
+		t.Error("Unexpected changes:", changed)
+	}
+	if err := c.Rollback(); err != nil || *host != "db1" || *port != 5432 {
+		t.Error("Rollback should undo the transaction:", err, *host, *port)
+	}
+
+	tx = c.Begin()
+	tx.Set("db.host", "db3")
+	tx.Discard()
+	if err := tx.Commit(); err != errTxDone || *host != "db1" {
+		t.Error("A discarded transaction shouldn't commit:", err, *host)
+	}
+}
+
+// flakyPersister records the values it saves, failing on the call numbered
+// fail.
+type flakyPersister struct {
+	calls  int
+	fail   int
+	stored map[string]string
+}
+
+func (p *flakyPersister) Persist(name string, value flag.Value) error {
+	p.calls++
+	if p.calls == p.fail {
+		return errors.New("disk full")
+	}
+	p.stored[name] = value.String()
+	return nil
+}
+
+func TestTxPersistFailure(t *testing.T) {
+	c := NewConfigSet("tx", ContinueOnError)
+	host := c.String("db.host", "db1")
+	port := c.Int("db.port", 5432)
+	p := &flakyPersister{fail: 2, stored: make(map[string]string)}
+	c.SetPersister(p)
+
+	tx := c.Begin()
+	tx.Set("db.host", "db2")
+	tx.Set("db.port", "5433")
+	err := tx.Commit()
+	if err == nil || err.Error() != "can't persist db.port: disk full" {
+		t.Fatal("Expected a persist error, got", err)
+	}
+	if *host != "db1" || *port != 5432 {
+		t.Error("Settings should be restored, are", *host, *port)
+	}
+	if p.stored["db.host"] != "db1" {
+		t.Error("The saved change to db.host should be undone, is", p.stored["db.host"])
+	}
+
+	tx = c.Begin()
+	tx.Set("db.host", "db3")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Origin("db.host") != "set" || c.Origin("db.port") != "default" {
+		t.Error("Unexpected origins:", c.Origin("db.host"), c.Origin("db.port"))
+	}
+	if err := c.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Origin("db.host") != "default" {
+		t.Error("Rollback should restore the origin, is", c.Origin("db.host"))
+	}
+	if err := c.Set("db.port", "6000"); err != nil || c.Origin("db.port") != "set" {
+		t.Error("Set should record its origin, is", c.Origin("db.port"), err)
+	}
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Units maps unit names to their size in some base unit, such as
+// Units{"KiB": 1024, "MiB": 1 << 20} for bytes or Units{"rpm": 1, "rps": 60}
+// for rotational speed.
+type Units map[string]float64
+
+var unitRegistry = struct {
+	sync.RWMutex
+	kinds map[string]Units
+}{kinds: map[string]Units{
+	"bytes": {
+		"B": 1, "kB": 1e3, "KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "PB": 1e15,
+		"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40, "PiB": 1 << 50,
+	},
+	"seconds": {
+		"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3, "s": 1, "m": 60, "h": 3600, "d": 86400,
+	},
+}}
+
+// RegisterUnits adds a kind of quantity that Quantity settings can accept,
+// replacing any kind already registered with the same name. The kinds "bytes"
+// and "seconds" are registered by default.
+func RegisterUnits(kind string, units Units) {
+	unitRegistry.Lock()
+	defer unitRegistry.Unlock()
+	unitRegistry.kinds[kind] = units
+}
+
+func lookupUnits(kind string) (Units, bool) {
+	unitRegistry.RLock()
+	defer unitRegistry.RUnlock()
+	units, ok := unitRegistry.kinds[kind]
+	return units, ok
+}
+
+// ParseQuantity parses a quantity such as "5GiB", "1500rpm", or "3h30m" using
+// the units registered for kind, returning its size in the base unit.
+// Quantities made of several terms are summed. A number without a unit is
+// taken to be in the base unit.
+func ParseQuantity(kind string, s string) (float64, error) {
+	units, ok := lookupUnits(kind)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a registered kind of unit", kind)
+	}
+	return units.parse(s)
+}
+
+func (u Units) parse(s string) (float64, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return 0, fmt.Errorf("%q is not a valid quantity", s)
+	}
+
+	var total float64
+	for rest != "" {
+		i := 0
+		for i < len(rest) && strings.ContainsRune("+-.0123456789eE_", rune(rest[i])) {
+			// An "e" is part of the number only if it's followed by a digit,
+			// so units such as "EiB" still work.
+			if (rest[i] == 'e' || rest[i] == 'E') && !(i+1 < len(rest) && unicode.IsDigit(rune(rest[i+1]))) {
+				break
+			}
+			i++
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid quantity", s)
+		}
+		rest = strings.TrimLeft(rest[i:], " ")
+
+		j := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsDigit(r) || r == ' ' })
+		if j < 0 {
+			j = len(rest)
+		}
+		unit := rest[:j]
+		rest = strings.TrimLeft(rest[j:], " ")
+
+		if unit == "" {
+			total += n
+			continue
+		}
+		size, ok := u[unit]
+		if !ok {
+			return 0, fmt.Errorf("%q has an unknown unit %q", s, unit)
+		}
+		total += n * size
+	}
+	return total, nil
+}
+
+// -- quantity Value
+
+type quantityValue struct {
+	p     *float64
+	units Units
+}
+
+func (q *quantityValue) Set(s string) error {
+	v, err := q.units.parse(s)
+	if err != nil {
+		return err
+	}
+	*q.p = v
+	return nil
+}
+
+func (q *quantityValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		*q.p = float64(v)
+	case float64:
+		*q.p = v
+	case string:
+		return q.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (q *quantityValue) Get() interface{} { return *q.p }
+
+func (q *quantityValue) snapshot() func() error {
+	v := *q.p
+	return func() error {
+		*q.p = v
+		return nil
+	}
+}
+
+func (q *quantityValue) String() string {
+	if q.p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*q.p, 'g', -1, 64)
+}
+
+func (c *ConfigSet) newQuantityValue(p *float64, name string, kind string, value string) *quantityValue {
+	units, ok := lookupUnits(kind)
+	if !ok {
+		panic(fmt.Sprintf("config: %s uses unregistered unit kind %q", name, kind))
+	}
+	q := &quantityValue{p, units}
+	if err := q.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	return q
+}
+
+// QuantityVar defines a quantity config with a given name, kind of unit, and
+// default value for a ConfigSet. The argument p points to a float64 variable
+// in which to store the value of the config, converted to the base unit of
+// kind. It panics if kind hasn't been registered with RegisterUnits or if the
+// default isn't valid.
+func (c *ConfigSet) QuantityVar(p *float64, name string, kind string, value string) {
+	c.Var(c.newQuantityValue(p, name, kind, value), name, "")
+}
+
+// Quantity defines a quantity config variable with a given name, kind of
+// unit, and default value, such as "512MiB", for a ConfigSet.
+func (c *ConfigSet) Quantity(name string, kind string, value string) *float64 {
+	p := new(float64)
+	c.QuantityVar(p, name, kind, value)
+	return p
+}
+
+// QuantityVar defines a quantity config with a given name, kind of unit, and
+// default value. The argument p points to a float64 variable in which to store
+// the value of the config, converted to the base unit of kind.
+func QuantityVar(p *float64, name string, kind string, value string) {
+	global().QuantityVar(p, name, kind, value)
+}
+
+// Quantity defines a quantity config variable with a given name, kind of
+// unit, and default value.
+func Quantity(name string, kind string, value string) *float64 {
+	return global().Quantity(name, kind, value)
+}
+
+// -- byte size Value
+
+type byteSizeValue struct {
+	p     *int64
+	units Units
+}
+
+// checkByteSize converts a number of bytes to an int64.
+func checkByteSize(v float64) (int64, error) {
+	switch {
+	case v < 0:
+		return 0, fmt.Errorf("%g bytes is negative", v)
+	case v >= math.MaxInt64:
+		return 0, errRange
+	case v != math.Trunc(v):
+		return 0, fmt.Errorf("%g bytes is not a whole number of bytes", v)
+	}
+	return int64(v), nil
+}
+
+func (b *byteSizeValue) Set(s string) error {
+	v, err := b.units.parse(s)
+	if err != nil {
+		return err
+	}
+	n, err := checkByteSize(v)
+	if err != nil {
+		return err
+	}
+	*b.p = n
+	return nil
+}
+
+func (b *byteSizeValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("%d bytes is negative", v)
+		}
+		*b.p = v
+	case string:
+		return b.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (b *byteSizeValue) Get() interface{} { return *b.p }
+
+func (b *byteSizeValue) snapshot() func() error {
+	v := *b.p
+	return func() error {
+		*b.p = v
+		return nil
+	}
+}
+
+func (b *byteSizeValue) String() string {
+	if b.p == nil {
+		return ""
+	}
+	return strconv.FormatInt(*b.p, 10)
+}
+
+// ByteSizeVar defines a byte size config with a given name and default value
+// for a ConfigSet. The argument p points to an int64 variable in which to
+// store the value of the config as a number of bytes. Values are integers or
+// strings with the units of the "bytes" kind registered for Quantity, such as
+// "512MB" or "2GiB". It panics if the default isn't valid.
+func (c *ConfigSet) ByteSizeVar(p *int64, name string, value string) {
+	units, _ := lookupUnits("bytes")
+	b := &byteSizeValue{p, units}
+	if err := b.Set(value); err != nil {
+		panic(fmt.Sprintf("config: invalid default for %s: %s", name, err))
+	}
+	c.Var(b, name, "")
+}
+
+// ByteSize defines a byte size config variable with a given name and default
+// value, such as "64MiB", for a ConfigSet.
+func (c *ConfigSet) ByteSize(name string, value string) *int64 {
+	p := new(int64)
+	c.ByteSizeVar(p, name, value)
+	return p
+}
+
+// ByteSizeVar defines a byte size config with a given name and default value.
+// The argument p points to an int64 variable in which to store the value of
+// the config as a number of bytes.
+func ByteSizeVar(p *int64, name string, value string) {
+	global().ByteSizeVar(p, name, value)
+}
+
+// ByteSize defines a byte size config variable with a given name and default
+// value.
+func ByteSize(name string, value string) *int64 {
+	return global().ByteSize(name, value)
+}
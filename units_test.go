@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestQuantity(t *testing.T) {
+	RegisterUnits("speed", Units{"rpm": 1, "rps": 60})
+
+	c := NewConfigSet("units", ContinueOnError)
+	cache := c.Quantity("cache", "bytes", "64MiB")
+	timeout := c.Quantity("timeout", "seconds", "30s")
+	fan := c.Quantity("fan", "speed", "0")
+
+	if *cache != 64<<20 {
+		t.Error("cache should default to 64MiB, is", *cache)
+	}
+
+	err := c.parseBytes("units", []byte("cache = \"5GiB\"\ntimeout = \"3h30m\"\nfan = \"1500rpm\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cache != 5<<30 {
+		t.Error("cache should be 5GiB, is", *cache)
+	}
+	if *timeout != 3*3600+30*60 {
+		t.Error("timeout should be 3h30m, is", *timeout)
+	}
+	if *fan != 1500 {
+		t.Error("fan should be 1500rpm, is", *fan)
+	}
+
+	err = c.parseBytes("units", []byte("fan = 25\n"))
+	if err != nil || *fan != 25 {
+		t.Error("fan should accept a plain number, is", *fan, err)
+	}
+
+	err = c.parseBytes("units", []byte("cache = \"5XB\"\n"))
+	if err == nil || err.Error() != `The value for cache is invalid: "5XB" has an unknown unit "XB"` {
+		t.Error("Expected an unknown unit error, got", err)
+	}
+
+	for _, test := range []struct {
+		s        string
+		expected float64
+	}{
+		{"1.5 KiB", 1536},
+		{"2EiB", 0},
+		{"1e3B", 1000},
+	} {
+		v, err := ParseQuantity("bytes", test.s)
+		if test.expected == 0 {
+			if err == nil {
+				t.Errorf("Expected an error parsing %q", test.s)
+			}
+		} else if err != nil || v != test.expected {
+			t.Errorf("%q should be %g, is %g (%v)", test.s, test.expected, v, err)
+		}
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	c := NewConfigSet("units", ContinueOnError)
+	cache := c.ByteSize("cache_size", "64MiB")
+	upload := c.ByteSize("upload_limit", "0")
+
+	if *cache != 64<<20 || *upload != 0 {
+		t.Fatal("Unexpected defaults:", *cache, *upload)
+	}
+	err := c.parseBytes("units", []byte("cache_size = \"2GiB\"\nupload_limit = 1048576\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cache != 2<<30 || *upload != 1<<20 {
+		t.Errorf("Unexpected sizes: %d, %d", *cache, *upload)
+	}
+	if err := c.Set("upload_limit", "512MB"); err != nil || *upload != 512e6 {
+		t.Error("upload_limit should be 512MB, is", *upload, err)
+	}
+
+	for doc, expected := range map[string]string{
+		`cache_size = "0.5B"`:    "The value for cache_size is invalid: 0.5 bytes is not a whole number of bytes",
+		`cache_size = -1`:        "The value for cache_size is invalid: -1 bytes is negative",
+		`cache_size = "9000PiB"`: "The value for cache_size is invalid: value out of range",
+		`cache_size = "lots"`:    `The value for cache_size is invalid: "lots" is not a valid quantity`,
+		`cache_size = 1.5`:       "The value for cache_size is invalid",
+	} {
+		err := c.parseBytes("units", []byte(doc))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s, got %v", expected, doc, err)
+		}
+	}
+}
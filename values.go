@@ -0,0 +1,413 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// errParse is returned by Set and setTOML if a value can't be parsed.
+var errParse = errors.New("parse error")
+
+// errRange is returned by Set and setTOML if a value is out of range.
+var errRange = errors.New("value out of range")
+
+// numError converts a strconv error into errParse or errRange.
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return errRange
+	}
+	return errParse
+}
+
+// loadError is returned by setTOML for errors that already name the config
+// they're about, such as errors loading the settings in a Wildcard table.
+// They're returned from Parse unchanged.
+type loadError struct {
+	error
+}
+
+// elementError is returned by setTOML when an element of an array value is
+// invalid. Reason completes a sentence such as "ports[3] is not a valid
+// integer".
+type elementError struct {
+	index  int
+	reason string
+}
+
+func (e elementError) Error() string {
+	return fmt.Sprintf("element %d %s", e.index, e.reason)
+}
+
+// tomlValue is implemented by config values that can be assigned a decoded
+// TOML value directly, without formatting it as a string and parsing it again
+// with Set.
+type tomlValue interface {
+	setTOML(value interface{}) error
+}
+
+// configValue is a flag.Value that can also be assigned decoded TOML values.
+// All of the config values defined by this package implement it.
+type configValue interface {
+	flag.Value
+	tomlValue
+}
+
+// tableValue is implemented by config values that are assigned a whole TOML
+// table. Their setTOML method is passed the *toml.Tree.
+type tableValue interface {
+	tomlValue
+	isTable()
+}
+
+// -- bool Value
+
+type boolValue bool
+
+func newBoolValue(val bool, p *bool) *boolValue {
+	*p = val
+	return (*boolValue)(p)
+}
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errParse
+	}
+	*b = boolValue(v)
+	return nil
+}
+
+func (b *boolValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		*b = boolValue(v)
+	case int64:
+		// debug = 1 has always been accepted, as strconv.ParseBool does.
+		if v != 0 && v != 1 {
+			return errParse
+		}
+		*b = v == 1
+	case string:
+		return b.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (b *boolValue) Get() interface{} { return bool(*b) }
+
+func (b *boolValue) snapshot() func() error {
+	v := *b
+	return func() error {
+		*b = v
+		return nil
+	}
+}
+
+func (b *boolValue) String() string { return strconv.FormatBool(bool(*b)) }
+
+// -- int Value
+
+type intValue int
+
+func newIntValue(val int, p *int) *intValue {
+	*p = val
+	return (*intValue)(p)
+}
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return numError(err)
+	}
+	*i = intValue(v)
+	return nil
+}
+
+func (i *intValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		if int64(int(v)) != v {
+			return errRange
+		}
+		*i = intValue(v)
+	case string:
+		return i.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (i *intValue) Get() interface{} { return int(*i) }
+
+func (i *intValue) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+
+// -- int64 Value
+
+type int64Value int64
+
+func newInt64Value(val int64, p *int64) *int64Value {
+	*p = val
+	return (*int64Value)(p)
+}
+
+func (i *int64Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return numError(err)
+	}
+	*i = int64Value(v)
+	return nil
+}
+
+func (i *int64Value) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		*i = int64Value(v)
+	case string:
+		return i.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (i *int64Value) Get() interface{} { return int64(*i) }
+
+func (i *int64Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *int64Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- uint Value
+
+type uintValue uint
+
+func newUintValue(val uint, p *uint) *uintValue {
+	*p = val
+	return (*uintValue)(p)
+}
+
+func (i *uintValue) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		return numError(err)
+	}
+	*i = uintValue(v)
+	return nil
+}
+
+func (i *uintValue) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 || uint64(v) > uint64(^uint(0)) {
+			return errRange
+		}
+		*i = uintValue(v)
+	case uint64:
+		if v > uint64(^uint(0)) {
+			return errRange
+		}
+		*i = uintValue(v)
+	case string:
+		return i.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (i *uintValue) Get() interface{} { return uint(*i) }
+
+func (i *uintValue) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *uintValue) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint64 Value
+
+type uint64Value uint64
+
+func newUint64Value(val uint64, p *uint64) *uint64Value {
+	*p = val
+	return (*uint64Value)(p)
+}
+
+func (i *uint64Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return numError(err)
+	}
+	*i = uint64Value(v)
+	return nil
+}
+
+func (i *uint64Value) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return errRange
+		}
+		*i = uint64Value(v)
+	case uint64:
+		*i = uint64Value(v)
+	case string:
+		return i.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (i *uint64Value) Get() interface{} { return uint64(*i) }
+
+func (i *uint64Value) snapshot() func() error {
+	v := *i
+	return func() error {
+		*i = v
+		return nil
+	}
+}
+
+func (i *uint64Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- string Value
+
+type stringValue string
+
+func newStringValue(val string, p *string) *stringValue {
+	*p = val
+	return (*stringValue)(p)
+}
+
+func (s *stringValue) Set(val string) error {
+	*s = stringValue(val)
+	return nil
+}
+
+func (s *stringValue) setTOML(value interface{}) error {
+	if v, ok := value.(string); ok {
+		*s = stringValue(v)
+	} else {
+		*s = stringValue(fmt.Sprintf("%v", value))
+	}
+	return nil
+}
+
+func (s *stringValue) Get() interface{} { return string(*s) }
+
+func (s *stringValue) snapshot() func() error {
+	v := *s
+	return func() error {
+		*s = v
+		return nil
+	}
+}
+
+func (s *stringValue) String() string { return string(*s) }
+
+// -- float64 Value
+
+type float64Value float64
+
+func newFloat64Value(val float64, p *float64) *float64Value {
+	*p = val
+	return (*float64Value)(p)
+}
+
+func (f *float64Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return numError(err)
+	}
+	*f = float64Value(v)
+	return nil
+}
+
+func (f *float64Value) setTOML(value interface{}) error {
+	switch v := value.(type) {
+	case float64:
+		*f = float64Value(v)
+	case int64:
+		*f = float64Value(v)
+	case string:
+		return f.Set(v)
+	default:
+		return errParse
+	}
+	return nil
+}
+
+func (f *float64Value) Get() interface{} { return float64(*f) }
+
+func (f *float64Value) snapshot() func() error {
+	v := *f
+	return func() error {
+		*f = v
+		return nil
+	}
+}
+
+func (f *float64Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 64)
+}
+
+// -- time.Duration Value
+
+type durationValue time.Duration
+
+func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
+	*p = val
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return errParse
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) setTOML(value interface{}) error {
+	if v, ok := value.(string); ok {
+		return d.Set(v)
+	}
+	return errParse
+}
+
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+
+func (d *durationValue) snapshot() func() error {
+	v := *d
+	return func() error {
+		*d = v
+		return nil
+	}
+}
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
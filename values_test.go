@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypedValues(t *testing.T) {
+	c := NewConfigSet("values", ContinueOnError)
+	b := c.Bool("b", false)
+	i := c.Int("i", 0)
+	u := c.Uint64("u", 0)
+	f := c.Float64("f", 0)
+	s := c.String("s", "")
+	d := c.Duration("d", 0)
+
+	values := map[string]interface{}{
+		"b": true,
+		"i": int64(-12),
+		"u": "0x10",
+		"f": int64(3),
+		"s": int64(42),
+		"d": "1m30s",
+	}
+	for name, value := range values {
+		if err := c.applyValue(name, value, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *b != true || *i != -12 || *u != 16 || *f != 3 || *s != "42" || *d != 90*time.Second {
+		t.Errorf("Unexpected values: %v %v %v %v %q %v", *b, *i, *u, *f, *s, *d)
+	}
+}
+
+func TestTypedValueErrors(t *testing.T) {
+	c := NewConfigSet("values", ContinueOnError)
+	c.Uint("u", 0)
+	c.Duration("d", 0)
+
+	tests := map[string]struct {
+		name  string
+		value interface{}
+	}{
+		"The value for u is invalid: value out of range": {"u", int64(-1)},
+		"The value for d is invalid":                     {"d", int64(5)},
+		"nope is not a valid config setting":             {"nope", true},
+	}
+	for expected, test := range tests {
+		err := c.applyValue(test.name, test.value, "")
+		if err == nil || err.Error() != expected {
+			t.Errorf("Error message should have been: %#v, but was: %v", expected, err)
+		}
+	}
+}
+
+func TestBoolIntegers(t *testing.T) {
+	c := NewConfigSet("values", ContinueOnError)
+	debug := c.Bool("debug", false)
+
+	if err := c.parseBytes("values", []byte("debug = 1")); err != nil || !*debug {
+		t.Errorf("debug = 1 should set true, got %v (%v)", *debug, err)
+	}
+	if err := c.parseBytes("values", []byte("debug = 0")); err != nil || *debug {
+		t.Errorf("debug = 0 should set false, got %v (%v)", *debug, err)
+	}
+	err := c.parseBytes("values", []byte("debug = 2"))
+	if expected := "The value for debug is invalid"; err == nil || err.Error() != expected {
+		t.Errorf("Error message should have been: %#v, but was: %v", expected, err)
+	}
+}